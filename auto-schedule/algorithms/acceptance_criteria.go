@@ -1,7 +1,13 @@
 package algorithms
 
 import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
 	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
 )
 
 type AppType int
@@ -19,6 +25,16 @@ func Acceptable(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Applica
 		return false
 	}
 
+	// check the replicas of multi-replica applications
+	if !replicaAcc(apps, soln) {
+		return false
+	}
+
+	// check the placement constraints
+	if !placementAcc(clouds, apps, soln) {
+		return false
+	}
+
 	// Maybe there will be other aspects in the future
 
 	// all checks passed
@@ -29,36 +45,195 @@ func Acceptable(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Applica
 func depAcc(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) bool {
 
 	for appName, app := range apps {
+		appSoln := soln.AppsSolution[appName]
+		// The dependencies of a rejected application do not need to be checked.
+		if !appSoln.Accepted {
+			continue
+		}
 		for _, dep := range app.Dependencies {
-			depAppName := dep.AppName
+			// If an application is accepted, all its dependent applications should be accepted or already running.
+			depLocs, available := depLocations(clouds, soln, dep.AppName)
+			if !available {
+				return false
+			}
 
-			// If an application is accepted, all its dependent applications should be accepted.
-			if soln.AppsSolution[appName].Accepted && !soln.AppsSolution[depAppName].Accepted {
+			// We presume that every application needs to send network requests to all its dependent applications, so the network RTT should not be too large, and the RTT and bandwidth should meet the requirements of the dependency.
+			// If the network between the 2 clouds does not meet the requirements, this solution is not acceptable.
+			if _, met := accessDep(clouds, appSoln, depLocs, dep); !met {
 				return false
 			}
+		}
+	}
 
-			// We presume that every application needs to send network requests to all its dependent applications, so the network RTT should not be too large.
-			// This check is only needed when this pair of applications are accepted.
-			if soln.AppsSolution[appName].Accepted && soln.AppsSolution[depAppName].Accepted {
-				srcVmName := soln.AppsSolution[appName].K8sNodeName
-				dstVmName := soln.AppsSolution[depAppName].K8sNodeName
-				// If 2 applications are deployed on the same VM, we think that the RTT between them is 0, so this check will not be needed in that condition.
-				if srcVmName != dstVmName {
-					srcCloudName := soln.AppsSolution[appName].TargetCloudName
-					dstCloudName := soln.AppsSolution[depAppName].TargetCloudName
-
-					// If the RTT is too large, this solution is not acceptable.
-					if clouds[srcCloudName].NetState[dstCloudName].Rtt > maxAccRttMs {
-						return false
-					}
+	// all clouds passed
+	return true
+}
 
-				}
+// Get where the dependency of an application is. If the dependency is scheduled together, its place is in the solution, and the output bool is whether it is accepted. Otherwise, it is a running application with fixed places (see asmodel.Cloud.RunningApps), and the output bool is whether it is running.
+func depLocations(clouds map[string]asmodel.Cloud, soln asmodel.Solution, depAppName string) ([]asmodel.AppLocation, bool) {
+	if depSoln, exist := soln.AppsSolution[depAppName]; exist {
+		return []asmodel.AppLocation{{CloudName: depSoln.TargetCloudName, K8sNodeName: depSoln.K8sNodeName}}, depSoln.Accepted
+	}
+	depLocs := asmodel.RunningAppLocations(clouds, depAppName)
+	return depLocs, len(depLocs) != 0
+}
+
+// Get the RTT from an application to its dependency, and whether the network meets the requirements of the dependency.
+// A running dependency may have pods on several nodes, and the application accesses the nearest one meeting the requirements. If none meets them, the RTT is that of the nearest one.
+func accessDep(clouds map[string]asmodel.Cloud, appSoln asmodel.SingleAppSolution, depLocs []asmodel.AppLocation, dep models.Dependency) (float64, bool) {
+	var minRtt, minMetRtt float64 = math.MaxFloat64, math.MaxFloat64
+	for _, depLoc := range depLocs {
+		// If 2 applications are deployed on the same VM, we think that the RTT between them is 0 and the bandwidth is unlimited, so the requirements are always met.
+		if appSoln.K8sNodeName == depLoc.K8sNodeName {
+			return 0, true
+		}
+		netState := clouds[appSoln.TargetCloudName].NetState[depLoc.CloudName]
+		minRtt = math.Min(minRtt, netState.Rtt)
+		if len(depNetViolation(netState, dep)) == 0 {
+			minMetRtt = math.Min(minMetRtt, netState.Rtt)
+		}
+	}
+	if minMetRtt != math.MaxFloat64 {
+		return minMetRtt, true
+	}
+	if minRtt != math.MaxFloat64 {
+		return minRtt, false
+	}
+	// no places of the dependency
+	return 0, false
+}
+
+// Get the RTT from an application to its dependency in a solution, used in the fitness functions.
+func depRtt(clouds map[string]asmodel.Cloud, soln asmodel.Solution, appName string, dep models.Dependency) float64 {
+	depLocs, _ := depLocations(clouds, soln, dep.AppName)
+	rtt, _ := accessDep(clouds, soln.AppsSolution[appName], depLocs, dep)
+	return rtt
+}
 
+// Check whether a solution is acceptable in terms of the replicas expanded by asmodel.ExpandReplicas.
+// The replicas of an application should be all accepted or all rejected. If they are accepted, they should be on different Kubernetes nodes (the pod anti-affinity of the Deployment requires this) and across at least MinClouds clouds.
+func replicaAcc(apps map[string]asmodel.Application, soln asmodel.Solution) bool {
+	for _, replicaNames := range asmodel.ReplicaGroups(apps) {
+		var acceptedCount int
+		for _, replicaName := range replicaNames {
+			if soln.AppsSolution[replicaName].Accepted {
+				acceptedCount++
 			}
+		}
+		if acceptedCount == 0 {
+			continue
+		}
+		if acceptedCount != len(replicaNames) {
+			return false
+		}
 
+		usedNodes := make(map[string]struct{})
+		usedClouds := make(map[string]struct{})
+		for _, replicaName := range replicaNames {
+			replicaSoln := soln.AppsSolution[replicaName]
+			// If the nodes are not decided yet, we cannot check them.
+			if len(replicaSoln.K8sNodeName) != 0 {
+				if _, exist := usedNodes[replicaSoln.K8sNodeName]; exist {
+					return false
+				}
+				usedNodes[replicaSoln.K8sNodeName] = struct{}{}
+			}
+			usedClouds[replicaSoln.TargetCloudName] = struct{}{}
+		}
+		if len(usedClouds) < apps[replicaNames[0]].MinClouds {
+			return false
 		}
 	}
 
-	// all clouds passed
 	return true
 }
+
+// Check whether the network state between 2 clouds meets the requirements of a dependency. If not, return the description of the violated requirement, otherwise return an empty string.
+func depNetViolation(netState models.NetworkState, dep models.Dependency) string {
+	if netState.Rtt > maxAccRttMs {
+		return fmt.Sprintf("RTT %g ms to dependency [%s] is larger than the maximum acceptable RTT %g ms", netState.Rtt, dep.AppName, maxAccRttMs)
+	}
+	if dep.MaxRttMs > 0 && netState.Rtt > dep.MaxRttMs {
+		return fmt.Sprintf("RTT %g ms to dependency [%s] violates maxRttMs %g", netState.Rtt, dep.AppName, dep.MaxRttMs)
+	}
+	// If the bandwidth is unknown, we cannot check it, so the requirement is considered as met.
+	if dep.MinBandwidthMbps > 0 && netState.BandwidthKnown() && netState.Bandwidth < dep.MinBandwidthMbps {
+		return fmt.Sprintf("bandwidth %g Mbps to dependency [%s] violates minBandwidthMbps %g", netState.Bandwidth, dep.AppName, dep.MinBandwidthMbps)
+	}
+	return ""
+}
+
+// ExplainRejections tries to find out why the applications are rejected in a solution. The key of the output map is the name of a rejected application, and the value is the reason.
+// An algorithm does not record why it rejects an application, so we check the dependency requirements against the solution. If no dependency requirement can explain the rejection, the reason is that the resources are not enough or that accepting it makes the solution worse.
+func ExplainRejections(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) map[string]string {
+	reasons := make(map[string]string)
+	appClouds := cloudsOfApps(apps, soln)
+	for appName, app := range apps {
+		if soln.AppsSolution[appName].Accepted {
+			continue
+		}
+		reasons[appName] = explainOneRejection(clouds, app, soln, appClouds)
+	}
+	return reasons
+}
+
+// appClouds is the output of cloudsOfApps.
+func explainOneRejection(clouds map[string]asmodel.Cloud, app asmodel.Application, soln asmodel.Solution, appClouds map[string]map[string]struct{}) string {
+	for _, dep := range app.Dependencies {
+		if _, available := depLocations(clouds, soln, dep.AppName); !available {
+			if _, exist := soln.AppsSolution[dep.AppName]; !exist {
+				return fmt.Sprintf("its dependency [%s] is not running", dep.AppName)
+			}
+			depAppName, _, _ := asmodel.SplitReplicaName(dep.AppName)
+			return fmt.Sprintf("its dependency [%s] is rejected", depAppName)
+		}
+	}
+
+	// only the clouds allowed by the placement constraints are available
+	allowedClouds := candidateClouds(clouds, app)
+	if len(clouds) != 0 && len(allowedClouds) == 0 {
+		return "no cloud meets its placement constraints (allowed clouds, forbidden clouds, and cloud types)"
+	}
+
+	if len(app.ReplicaOf) != 0 && app.MinClouds > len(allowedClouds) {
+		return fmt.Sprintf("its replicas need at least %d clouds, but only %d clouds are available", app.MinClouds, len(allowedClouds))
+	}
+
+	// Check whether there is any cloud where this application can meet all dependency requirements and co-location constraints. If no, the requirements must be the reason.
+	var violations []string
+	for cloudName, cloud := range allowedClouds {
+		var violation string
+		for _, dep := range app.Dependencies {
+			displayDep := dep
+			displayDep.AppName, _, _ = asmodel.SplitReplicaName(dep.AppName)
+			// the requirements are met if any place of the dependency meets them. A running dependency may have several places.
+			depLocs, _ := depLocations(clouds, soln, dep.AppName)
+			violation = ""
+			for _, depLoc := range depLocs {
+				// on the same cloud, it may be deployed on the same VM with the dependency
+				if depLoc.CloudName == cloudName {
+					violation = ""
+					break
+				}
+				if violation = depNetViolation(cloud.NetState[depLoc.CloudName], displayDep); len(violation) == 0 {
+					break
+				}
+			}
+			if len(violation) != 0 {
+				break
+			}
+		}
+		if len(violation) == 0 {
+			violation = coLocationViolation(app, cloudName, appClouds)
+		}
+		if len(violation) == 0 {
+			return "resources are not enough, or accepting it makes the solution worse"
+		}
+		violations = append(violations, fmt.Sprintf("on cloud [%s], %s", cloudName, violation))
+	}
+	if len(violations) == 0 {
+		return "no cloud is available"
+	}
+	sort.Strings(violations)
+	return fmt.Sprintf("requirements cannot be met: %s", strings.Join(violations, "; "))
+}
@@ -464,3 +464,439 @@ func TestInnerDepAcc(t *testing.T) {
 	}
 
 }
+
+// 3 clouds with RTT and bandwidth among them, for testing the network requirements of dependencies.
+func cloudsWithBandwidthForTest() map[string]asmodel.Cloud {
+	return map[string]asmodel.Cloud{
+		"cloud1": asmodel.Cloud{
+			Name: "cloud1",
+			NetState: map[string]models.NetworkState{
+				"cloud1": {Rtt: 0.5, Bandwidth: 1000},
+				"cloud2": {Rtt: 10, Bandwidth: 200},
+				"cloud3": {Rtt: 80, Bandwidth: 50},
+			},
+		},
+		"cloud2": asmodel.Cloud{
+			Name: "cloud2",
+			NetState: map[string]models.NetworkState{
+				"cloud1": {Rtt: 10, Bandwidth: 200},
+				"cloud2": {Rtt: 0.5, Bandwidth: 1000},
+				"cloud3": {Rtt: 60, Bandwidth: 80},
+			},
+		},
+		"cloud3": asmodel.Cloud{
+			Name: "cloud3",
+			NetState: map[string]models.NetworkState{
+				"cloud1": {Rtt: 80, Bandwidth: 50},
+				"cloud2": {Rtt: 60, Bandwidth: 80},
+				"cloud3": {Rtt: 0.5, Bandwidth: 1000},
+			},
+		},
+	}
+}
+
+func appsWithNetReqForTest(maxRttMs, minBandwidthMbps float64) map[string]asmodel.Application {
+	return map[string]asmodel.Application{
+		"app1": asmodel.Application{
+			Name:     "app1",
+			Priority: 1,
+			Dependencies: []models.Dependency{
+				{AppName: "app2", MaxRttMs: maxRttMs, MinBandwidthMbps: minBandwidthMbps},
+			},
+		},
+		"app2": asmodel.Application{
+			Name:     "app2",
+			Priority: 10,
+		},
+	}
+}
+
+func TestInnerDepAccNetRequirements(t *testing.T) {
+	testCases := []struct {
+		name           string
+		apps           map[string]asmodel.Application
+		app1Cloud      string
+		app1Node       string
+		expectedResult bool
+	}{
+		{
+			name:           "case no requirements",
+			apps:           appsWithNetReqForTest(0, 0),
+			app1Cloud:      "cloud1",
+			app1Node:       "node1",
+			expectedResult: true,
+		},
+		{
+			name:           "case RTT requirement met",
+			apps:           appsWithNetReqForTest(70, 0),
+			app1Cloud:      "cloud2",
+			app1Node:       "node2",
+			expectedResult: true,
+		},
+		{
+			name:           "case RTT requirement violated",
+			apps:           appsWithNetReqForTest(70, 0),
+			app1Cloud:      "cloud1",
+			app1Node:       "node1",
+			expectedResult: false,
+		},
+		{
+			name:           "case bandwidth requirement met",
+			apps:           appsWithNetReqForTest(0, 80),
+			app1Cloud:      "cloud2",
+			app1Node:       "node2",
+			expectedResult: true,
+		},
+		{
+			name:           "case bandwidth requirement violated",
+			apps:           appsWithNetReqForTest(0, 100),
+			app1Cloud:      "cloud2",
+			app1Node:       "node2",
+			expectedResult: false,
+		},
+		{
+			name:           "case same cloud different VMs",
+			apps:           appsWithNetReqForTest(1, 500),
+			app1Cloud:      "cloud3",
+			app1Node:       "node3-2",
+			expectedResult: true,
+		},
+		{
+			name:           "case same VM",
+			apps:           appsWithNetReqForTest(0.1, 5000),
+			app1Cloud:      "cloud3",
+			app1Node:       "node3",
+			expectedResult: true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		soln := asmodel.Solution{
+			AppsSolution: map[string]asmodel.SingleAppSolution{
+				"app1": {Accepted: true, TargetCloudName: testCase.app1Cloud, K8sNodeName: testCase.app1Node},
+				"app2": {Accepted: true, TargetCloudName: "cloud3", K8sNodeName: "node3"},
+			},
+		}
+		actualResult := depAcc(cloudsWithBandwidthForTest(), testCase.apps, soln)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+// The bandwidth is unknown if iperf3 fails to measure it, and then minBandwidthMbps is not checked, but maxRttMs still is.
+func TestInnerDepNetViolationUnknownBandwidth(t *testing.T) {
+	netState := models.NetworkState{Rtt: 10, Bandwidth: models.UnknownBandwidthMbps}
+	assert.Equal(t, "", depNetViolation(netState, models.Dependency{AppName: "app2", MinBandwidthMbps: 100}))
+	assert.NotEqual(t, "", depNetViolation(netState, models.Dependency{AppName: "app2", MaxRttMs: 5, MinBandwidthMbps: 100}))
+	assert.NotEqual(t, "", depNetViolation(models.NetworkState{Rtt: 10, Bandwidth: models.UnreachableBandwidthMbps}, models.Dependency{AppName: "app2", MinBandwidthMbps: 100}))
+}
+
+// app1 depends on the running application "db", which is not scheduled together.
+func TestInnerDepAccRunningDep(t *testing.T) {
+	testCases := []struct {
+		name           string
+		dbLocations    []asmodel.AppLocation
+		maxRttMs       float64
+		app1Soln       asmodel.SingleAppSolution
+		expectedResult bool
+		expectedRtt    float64
+	}{
+		{
+			name:           "case nearest pod meets requirements",
+			dbLocations:    []asmodel.AppLocation{{CloudName: "cloud3", K8sNodeName: "node3"}, {CloudName: "cloud2", K8sNodeName: "node2-db"}},
+			maxRttMs:       70,
+			app1Soln:       asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+			expectedResult: true,
+			expectedRtt:    10,
+		},
+		{
+			name:           "case RTT requirement violated",
+			dbLocations:    []asmodel.AppLocation{{CloudName: "cloud3", K8sNodeName: "node3"}},
+			maxRttMs:       70,
+			app1Soln:       asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+			expectedResult: false,
+			expectedRtt:    80,
+		},
+		{
+			name:           "case same VM",
+			dbLocations:    []asmodel.AppLocation{{CloudName: "cloud3", K8sNodeName: "node3"}},
+			maxRttMs:       0.1,
+			app1Soln:       asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud3", K8sNodeName: "node3"},
+			expectedResult: true,
+			expectedRtt:    0,
+		},
+		{
+			name:           "case dependency not running",
+			dbLocations:    nil,
+			maxRttMs:       0,
+			app1Soln:       asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+			expectedResult: false,
+			expectedRtt:    0,
+		},
+		{
+			name:           "case rejected and dependency not running",
+			dbLocations:    nil,
+			maxRttMs:       0,
+			app1Soln:       asmodel.RejSoln,
+			expectedResult: true,
+			expectedRtt:    0,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		clouds := cloudsWithBandwidthForTest()
+		asmodel.SetRunningApps(clouds, map[string][]asmodel.AppLocation{"db": testCase.dbLocations})
+		dep := models.Dependency{AppName: "db", MaxRttMs: testCase.maxRttMs}
+		apps := map[string]asmodel.Application{
+			"app1": {Name: "app1", Priority: 5, Dependencies: []models.Dependency{dep}},
+		}
+		soln := asmodel.Solution{
+			AppsSolution: map[string]asmodel.SingleAppSolution{
+				"app1": testCase.app1Soln,
+			},
+		}
+		actualResult := depAcc(clouds, apps, soln)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+		if testCase.app1Soln.Accepted {
+			actualRtt := depRtt(clouds, soln, "app1", dep)
+			assert.Equal(t, testCase.expectedRtt, actualRtt, fmt.Sprintf("%s: RTT is not expected", testCase.name))
+		}
+	}
+}
+
+func TestExplainRejections(t *testing.T) {
+	testCases := []struct {
+		name           string
+		clouds         map[string]asmodel.Cloud
+		apps           map[string]asmodel.Application
+		soln           asmodel.Solution
+		expectedResult map[string]string
+	}{
+		{
+			name:   "case all accepted",
+			clouds: cloudsWithBandwidthForTest(),
+			apps:   appsWithNetReqForTest(70, 0),
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node2"},
+					"app2": {Accepted: true, TargetCloudName: "cloud3", K8sNodeName: "node3"},
+				},
+			},
+			expectedResult: map[string]string{},
+		},
+		{
+			name:   "case dependency rejected",
+			clouds: cloudsWithBandwidthForTest(),
+			apps:   appsWithNetReqForTest(0, 0),
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+					"app2": {Accepted: false},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1": "its dependency [app2] is rejected",
+				"app2": "resources are not enough, or accepting it makes the solution worse",
+			},
+		},
+		{
+			name:   "case requirements can be met in the same cloud",
+			clouds: cloudsWithBandwidthForTest(),
+			apps:   appsWithNetReqForTest(1, 500),
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+					"app2": {Accepted: true, TargetCloudName: "cloud3", K8sNodeName: "node3"},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1": "resources are not enough, or accepting it makes the solution worse",
+			},
+		},
+		{
+			name:   "case replicas need more clouds",
+			clouds: cloudsWithBandwidthForTest(),
+			apps: asmodel.ExpandReplicas(map[string]asmodel.Application{
+				"app1": {Name: "app1", Priority: 1, Replicas: 4, MinClouds: 4, Dependencies: []models.Dependency{{AppName: "app2"}}},
+				"app2": {Name: "app2", Priority: 10},
+			}),
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1#0": {Accepted: false},
+					"app1#1": {Accepted: false},
+					"app1#2": {Accepted: false},
+					"app1#3": {Accepted: false},
+					"app2":   {Accepted: true, TargetCloudName: "cloud3", K8sNodeName: "node3"},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1#0": "its replicas need at least 4 clouds, but only 3 clouds are available",
+				"app1#1": "its replicas need at least 4 clouds, but only 3 clouds are available",
+				"app1#2": "its replicas need at least 4 clouds, but only 3 clouds are available",
+				"app1#3": "its replicas need at least 4 clouds, but only 3 clouds are available",
+			},
+		},
+		{
+			name:   "case placement constraints cannot be met",
+			clouds: cloudsWithBandwidthForTest(),
+			apps: map[string]asmodel.Application{
+				"app1": {Name: "app1", Priority: 1, Placement: models.Placement{AllowedClouds: []string{"cloud4"}}},
+			},
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1": "no cloud meets its placement constraints (allowed clouds, forbidden clouds, and cloud types)",
+			},
+		},
+		{
+			name:   "case co-location cannot be met",
+			clouds: cloudsWithBandwidthForTest(),
+			apps: map[string]asmodel.Application{
+				"app1": {Name: "app1", Priority: 1, Placement: models.Placement{ForbiddenClouds: []string{"cloud3"}, CoLocateWith: []string{"app2"}}},
+				"app2": {Name: "app2", Priority: 10},
+			},
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+					"app2": {Accepted: true, TargetCloudName: "cloud3", K8sNodeName: "node3"},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1": "requirements cannot be met: on cloud [cloud1], it should be on the same cloud as [app2]; on cloud [cloud2], it should be on the same cloud as [app2]",
+			},
+		},
+		{
+			name: "case requirements cannot be met",
+			// without cloud3, app1 cannot be put on the same cloud with app2
+			clouds: func() map[string]asmodel.Cloud {
+				clouds := cloudsWithBandwidthForTest()
+				delete(clouds, "cloud3")
+				return clouds
+			}(),
+			apps: appsWithNetReqForTest(70, 100),
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+					"app2": {Accepted: true, TargetCloudName: "cloud3", K8sNodeName: "node3"},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1": "requirements cannot be met: on cloud [cloud1], RTT 80 ms to dependency [app2] violates maxRttMs 70; on cloud [cloud2], bandwidth 80 Mbps to dependency [app2] violates minBandwidthMbps 100",
+			},
+		},
+		{
+			name:   "case running dependency not running",
+			clouds: cloudsWithBandwidthForTest(),
+			apps: map[string]asmodel.Application{
+				"app1": {Name: "app1", Priority: 1, Dependencies: []models.Dependency{{AppName: "db"}}},
+			},
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1": "its dependency [db] is not running",
+			},
+		},
+		{
+			name: "case running dependency requirements cannot be met",
+			clouds: func() map[string]asmodel.Cloud {
+				clouds := cloudsWithBandwidthForTest()
+				asmodel.SetRunningApps(clouds, map[string][]asmodel.AppLocation{"db": {{CloudName: "cloud1", K8sNodeName: "node1"}}})
+				return clouds
+			}(),
+			apps: map[string]asmodel.Application{
+				"app1": {Name: "app1", Priority: 1, Placement: models.Placement{ForbiddenClouds: []string{"cloud1"}}, Dependencies: []models.Dependency{{AppName: "db", MaxRttMs: 5}}},
+			},
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+				},
+			},
+			expectedResult: map[string]string{
+				"app1": "requirements cannot be met: on cloud [cloud2], RTT 10 ms to dependency [db] violates maxRttMs 5; on cloud [cloud3], RTT 80 ms to dependency [db] violates maxRttMs 5",
+			},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := ExplainRejections(testCase.clouds, testCase.apps, testCase.soln)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerReplicaAcc(t *testing.T) {
+	apps := asmodel.ExpandReplicas(map[string]asmodel.Application{
+		"frontend": {Name: "frontend", Priority: 5, Replicas: 3, MinClouds: 2},
+		"backend":  {Name: "backend", Priority: 8},
+	})
+
+	testCases := []struct {
+		name           string
+		replicaSolns   []asmodel.SingleAppSolution
+		expectedResult bool
+	}{
+		{
+			name: "case all rejected",
+			replicaSolns: []asmodel.SingleAppSolution{
+				{Accepted: false},
+				{Accepted: false},
+				{Accepted: false},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "case partly accepted",
+			replicaSolns: []asmodel.SingleAppSolution{
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+				{Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node2"},
+				{Accepted: false},
+			},
+			expectedResult: false,
+		},
+		{
+			name: "case spread across 2 clouds",
+			replicaSolns: []asmodel.SingleAppSolution{
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node2"},
+				{Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3"},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "case same node",
+			replicaSolns: []asmodel.SingleAppSolution{
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+				{Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3"},
+			},
+			expectedResult: false,
+		},
+		{
+			name: "case only 1 cloud",
+			replicaSolns: []asmodel.SingleAppSolution{
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"},
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node2"},
+				{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node3"},
+			},
+			expectedResult: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		soln := asmodel.GenEmptySoln()
+		soln.AppsSolution["backend"] = asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1"}
+		for j, replicaSoln := range testCase.replicaSolns {
+			soln.AppsSolution[asmodel.ReplicaName("frontend", j)] = replicaSoln
+		}
+		actualResult := replicaAcc(apps, soln)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
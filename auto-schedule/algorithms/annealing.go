@@ -0,0 +1,158 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/astaxie/beego"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+/**
+NOTE:
+Mcssga evaluates a whole population in every iteration, which is expensive for medium groups of applications. Annealing is a simulated annealing algorithm, which evaluates only one solution in every iteration:
+1. It starts from the solution of Greedy.
+2. In every iteration, a neighbor of the current solution is made in the same way as the mutation of Mcssga: the gene of a random application is changed to be rejected or on another cloud, and the replicas follow their first one. The neighbor is refined and checked by RefineSoln, and an unacceptable one is skipped.
+3. A better neighbor is always taken as the current solution, and a worse one is taken with the probability exp((neighbor fitness - current fitness) / temperature), so that the search can leave local optima. The temperature decreases in every iteration.
+The fitness function is that of Mcssga, and the output is the best solution in all iterations.
+*/
+
+// AnnealingParams are the parameters of simulated annealing.
+type AnnealingParams struct {
+	IterationCount int `json:"iterationCount"`
+	// The initial temperature in the unit of the average fitness value of a rejected application, e.g., with 1, a neighbor which is worse by rejecting an average application is taken with probability 1/e at first.
+	InitialTemperature float64 `json:"initialTemperature"`
+	CoolingRate        float64 `json:"coolingRate"` // the temperature is multiplied by it in every iteration, in (0, 1)
+	// If in the past StopNoUpdateIteration iterations, the best solution so far has not been updated, we stop the algorithm.
+	StopNoUpdateIteration int `json:"stopNoUpdateIteration"`
+}
+
+// DefaultAnnealingParams is used for the parameters not set.
+var DefaultAnnealingParams AnnealingParams = AnnealingParams{
+	IterationCount:        5000,
+	InitialTemperature:    1,
+	CoolingRate:           0.999,
+	StopNoUpdateIteration: 1000,
+}
+
+// set the parameters not more than 0 to the default values.
+func (p AnnealingParams) withDefaults() AnnealingParams {
+	if p.IterationCount <= 0 {
+		p.IterationCount = DefaultAnnealingParams.IterationCount
+	}
+	if p.InitialTemperature <= 0 {
+		p.InitialTemperature = DefaultAnnealingParams.InitialTemperature
+	}
+	if p.CoolingRate <= 0 || p.CoolingRate >= 1 {
+		p.CoolingRate = DefaultAnnealingParams.CoolingRate
+	}
+	if p.StopNoUpdateIteration <= 0 {
+		p.StopNoUpdateIteration = DefaultAnnealingParams.StopNoUpdateIteration
+	}
+	return p
+}
+
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        AnnealingName,
+		Description: fmt.Sprintf("Simulated annealing algorithm starting from the solution of Greedy, with the fitness function of Mcssga. Cheaper than Mcssga for medium groups of applications. The parameters are %s.", models.JsonString(DefaultAnnealingParams)),
+		Params: func() []ParamSchema {
+			return []ParamSchema{{Name: "expAppCompuTimeOneCpu", Type: "float", Description: "the expected computation time (ms) of applications by one CPU core", Default: DefaultExpAppCompuTimeOneCpu}}
+		},
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			a := NewAnnealing(DefaultAnnealingParams, params.ExpAppCompuTimeOneCpu)
+			a.MaxHourlyCost = params.MaxHourlyCost
+			a.Seed = params.Seed
+			return a
+		},
+	})
+}
+
+// Simulated annealing algorithm
+type Annealing struct {
+	Params                AnnealingParams
+	ExpAppCompuTimeOneCpu float64 // the expected computation time of every application by one CPU core, used for the applications without their own values.  unit millisecond (ms)
+	MaxHourlyCost         float64 // the budget per hour of the VMs to create, see SolutionCost. Not more than 0 means no limit.
+
+	Seed   int64      // the seed of the random generator, with which the same input always gives the same solution
+	rander *rand.Rand // the random generator of this scheduling, created from Seed
+
+	// record the best fitness value until every iteration, to show the evolution trend
+	BestFitnessEachIter []float64
+}
+
+func NewAnnealing(params AnnealingParams, exTimeOneCpu float64) *Annealing {
+	return &Annealing{
+		Params:                params.withDefaults(),
+		ExpAppCompuTimeOneCpu: exTimeOneCpu,
+		Seed:                  NewSeed(),
+	}
+}
+
+func (a *Annealing) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
+	beego.Info("Using scheduling algorithm:", AnnealingName)
+	a.rander = NewRander(a.Seed)
+
+	greedy := NewGreedy()
+	greedy.MaxHourlyCost = a.MaxHourlyCost
+	current, err := greedy.Schedule(clouds, apps, appsOrder)
+	if err != nil {
+		return asmodel.Solution{}, fmt.Errorf("get the initial solution by %s, error: %w", GreedyName, err)
+	}
+	if len(apps) == 0 {
+		return current, nil
+	}
+
+	// Mcssga provides the fitness function and the mutation of genes.
+	ops := NewMcssga(0, 0, 0, 0, 0, a.ExpAppCompuTimeOneCpu)
+	ops.MaxHourlyCost = a.MaxHourlyCost
+	ops.SetMaxReaRtt(clouds)
+	ops.SetAvgDepNum(apps)
+
+	// The temperature is relative to the average fitness value of a rejected application, so that the same parameters work for different applications.
+	appNames := sortedAppNames(apps)
+	var rejFitnessSum float64
+	for _, appName := range appNames {
+		app := apps[appName]
+		rejFitnessSum += (ops.expCompuTimeOneCpu(app) + ops.MaxReachableRtt*ops.AvgDepNum) / 2 * float64(app.Priority)
+	}
+	temperature := a.Params.InitialTemperature * rejFitnessSum / float64(len(apps))
+
+	curFitness := ops.Fitness(clouds, apps, current)
+	best, bestFitness := current, curFitness
+	a.BestFitnessEachIter = nil
+	var noUpdateIteration int
+	for iteration := 1; iteration <= a.Params.IterationCount; iteration++ {
+		neighbor := asmodel.SolutionCopy(current)
+		mutatedAppName := appNames[randomInt(a.rander, 0, len(appNames)-1)]
+		neighbor.AppsSolution[mutatedAppName] = ops.geneMutate(candidateClouds(clouds, apps[mutatedAppName]), neighbor.AppsSolution[mutatedAppName], a.rander)
+		alignReplicaGenes(clouds, apps, neighbor, a.rander)
+
+		if refinedSoln, acceptable := RefineSoln(clouds, apps, appsOrder, neighbor); acceptable {
+			fitness := ops.Fitness(clouds, apps, refinedSoln)
+			if fitness >= curFitness || randomFloat64(a.rander, 0, 1) < math.Exp((fitness-curFitness)/temperature) {
+				current, curFitness = refinedSoln, fitness
+			}
+		}
+		temperature *= a.Params.CoolingRate
+
+		if curFitness > bestFitness+floatDelta {
+			best, bestFitness = current, curFitness
+			noUpdateIteration = 0
+		} else {
+			noUpdateIteration++
+		}
+		a.BestFitnessEachIter = append(a.BestFitnessEachIter, bestFitness)
+
+		// If we did not find better solutions in the past some iterations, we stop the algorithm and return the result.
+		if noUpdateIteration > a.Params.StopNoUpdateIteration {
+			break
+		}
+	}
+
+	beego.Info(fmt.Sprintf("%s finished after %d iterations, the best fitness value is %g.", AnnealingName, len(a.BestFitnessEachIter), bestFitness))
+	return best, nil
+}
@@ -0,0 +1,44 @@
+package algorithms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/models"
+)
+
+func TestAnnealingSchedule(t *testing.T) {
+	clouds, apps, appsOrder := exactCloudsAppsForTest()
+	params := AnnealingParams{IterationCount: 300, StopNoUpdateIteration: 100}
+
+	annealing := NewAnnealing(params, DefaultExpAppCompuTimeOneCpu)
+	annealing.Seed = 20231017
+	solution, err := annealing.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	t.Log(models.JsonString(solution))
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution should be acceptable")
+	assert.Equal(t, DefaultAnnealingParams.InitialTemperature, annealing.Params.InitialTemperature, "the parameters not set should be the default")
+
+	fitnessCalc := NewMcssga(0, 0, 0, 0, 0, DefaultExpAppCompuTimeOneCpu)
+	fitnessCalc.SetMaxReaRtt(clouds)
+	fitnessCalc.SetAvgDepNum(apps)
+	fitness := fitnessCalc.Fitness(clouds, apps, solution)
+
+	// Annealing starts from the solution of Greedy, and cannot be better than Exact.
+	greedySoln, err := NewGreedy().Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, fitness, fitnessCalc.Fitness(clouds, apps, greedySoln))
+	exact := NewExact(DefaultExpAppCompuTimeOneCpu, time.Minute)
+	_, err = exact.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, fitness, exact.BestFitness+floatDelta)
+
+	// the same seed gives the same solution
+	annealingAgain := NewAnnealing(params, DefaultExpAppCompuTimeOneCpu)
+	annealingAgain.Seed = 20231017
+	solutionAgain, err := annealingAgain.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	assert.Equal(t, solution, solutionAgain)
+}
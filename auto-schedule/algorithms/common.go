@@ -1,6 +1,7 @@
 package algorithms
 
 import (
+	"context"
 	"sync"
 
 	asmodel "emcontroller/auto-schedule/model"
@@ -21,12 +22,16 @@ const (
 
 	maxAccRttMs float64 = 20000 // unit: millisecond (ms). Maximum acceptable Round-Trip Time (RTT) between to applications with a dependency. The value should be smaller than models.UnreachableRttMs.
 
-	McssgaName   string = "Mcssga"
-	CompRandName string = "CompRand"
-	BERandName   string = "BERand"
-	AmagaName    string = "Amaga"
-	AmpgaName    string = "Ampga"
-	DiktyogaName string = "Diktyoga"
+	McssgaName    string = "Mcssga"
+	CompRandName  string = "CompRand"
+	BERandName    string = "BERand"
+	AmagaName     string = "Amaga"
+	AmpgaName     string = "Ampga"
+	DiktyogaName  string = "Diktyoga"
+	Nsga2Name     string = "Nsga2"
+	ExactName     string = "Exact"
+	GreedyName    string = "Greedy"
+	AnnealingName string = "Annealing"
 )
 
 var (
@@ -39,6 +44,11 @@ type SchedulingAlgorithm interface {
 	Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error)
 }
 
+// The anytime algorithms (e.g., the genetic algorithms) check this in every iteration. If ctx is done, i.e., its deadline is reached or it is cancelled, they stop and return the best solution found so far. A nil ctx is never done.
+func searchDone(ctx context.Context) bool {
+	return ctx != nil && ctx.Err() != nil
+}
+
 // After scheduling applications to clouds, we get a coarse solution. Then, we use this function to refine the solution, do 3 things:
 // 1. schedule applications to VMs inside clouds;
 // 2. allocate CPUs to applications inside VMs;
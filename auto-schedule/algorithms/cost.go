@@ -0,0 +1,38 @@
+package algorithms
+
+import (
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+// SolutionCost is the price per hour of the VMs that a solution creates. The existing VMs are already paid, so putting applications on them costs nothing more. The VMs on the clouds without pricing cost 0.
+func SolutionCost(clouds map[string]asmodel.Cloud, soln asmodel.Solution) float64 {
+	var cost float64
+	for _, vm := range soln.VmsToCreate {
+		if pricing := clouds[vm.Cloud].Pricing; pricing != nil {
+			cost += pricing.VmHourly(vm)
+		}
+	}
+	return cost
+}
+
+// AppsCost is the price per hour of the resources requested by the accepted applications, on the clouds where they are placed, no matter whether on existing or new VMs. This is what the applications cost.
+func AppsCost(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) float64 {
+	var cost float64
+	for appName, appSoln := range soln.AppsSolution {
+		if !appSoln.Accepted {
+			continue
+		}
+		pricing := clouds[appSoln.TargetCloudName].Pricing
+		if pricing == nil {
+			continue
+		}
+		res := apps[appName].Resources
+		cost += pricing.ResourcesHourly(appSoln.AllocatedCpuCore, res.Memory, res.Storage)
+	}
+	return cost
+}
+
+// WithinBudget checks whether the VMs that a solution creates cost no more than the budget per hour. A budget not more than 0 means no limit.
+func WithinBudget(clouds map[string]asmodel.Cloud, soln asmodel.Solution, maxHourlyCost float64) bool {
+	return maxHourlyCost <= 0 || SolutionCost(clouds, soln) <= maxHourlyCost+floatDelta
+}
@@ -0,0 +1,93 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func costCloudsForTest() map[string]asmodel.Cloud {
+	return map[string]asmodel.Cloud{
+		"priced": {Name: "priced", Pricing: &models.CloudPricing{VCpuHour: 0.1, RamGibHour: 0.01, StorageGibHour: 0.001, VmHour: 0.5}},
+		"free":   {Name: "free"},
+	}
+}
+
+func TestSolutionCost(t *testing.T) {
+	clouds := costCloudsForTest()
+	testCases := []struct {
+		name           string
+		soln           asmodel.Solution
+		expectedResult float64
+	}{
+		{
+			name:           "case no new VMs",
+			soln:           asmodel.GenEmptySoln(),
+			expectedResult: 0,
+		},
+		{
+			name: "case new VMs on both clouds",
+			soln: asmodel.Solution{VmsToCreate: []models.IaasVm{
+				{Cloud: "priced", VCpu: 4, Ram: 4096, Storage: 100},
+				{Cloud: "free", VCpu: 8, Ram: 8192, Storage: 100},
+			}},
+			expectedResult: 4*0.1 + 4*0.01 + 100*0.001 + 0.5,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := SolutionCost(clouds, testCase.soln)
+		assert.InDelta(t, testCase.expectedResult, actualResult, 1e-9, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+
+	expensive := asmodel.Solution{VmsToCreate: []models.IaasVm{{Cloud: "priced", VCpu: 4, Ram: 4096, Storage: 100}}}
+	assert.True(t, WithinBudget(clouds, expensive, 0), "0 means no limit")
+	assert.True(t, WithinBudget(clouds, expensive, 2))
+	assert.False(t, WithinBudget(clouds, expensive, 1))
+}
+
+func TestAppsCost(t *testing.T) {
+	clouds := costCloudsForTest()
+	apps := map[string]asmodel.Application{
+		"app1": {Name: "app1", Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 2, Memory: 2048, Storage: 10}}},
+		"app2": {Name: "app2", Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 2, Memory: 2048, Storage: 10}}},
+		"app3": {Name: "app3", Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 2, Memory: 2048, Storage: 10}}},
+	}
+	soln := asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+		"app1": {Accepted: true, TargetCloudName: "priced", AllocatedCpuCore: 3},
+		"app2": {Accepted: true, TargetCloudName: "free", AllocatedCpuCore: 2},
+		"app3": {Accepted: false},
+	}}
+	assert.InDelta(t, 3*0.1+2*0.01+10*0.001, AppsCost(clouds, apps, soln), 1e-9)
+}
+
+func TestMcssgaFitnessBudget(t *testing.T) {
+	clouds := costCloudsForTest()
+	apps := map[string]asmodel.Application{
+		"app1": {Name: "app1", Priority: 5, Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 2, Memory: 2048, Storage: 10}}},
+	}
+	rejected := asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{"app1": asmodel.RejSoln}}
+	onNewVm := asmodel.Solution{
+		AppsSolution: map[string]asmodel.SingleAppSolution{"app1": {Accepted: true, TargetCloudName: "priced", K8sNodeName: "auto-sched-priced-0", AllocatedCpuCore: 2}},
+		VmsToCreate:  []models.IaasVm{{Name: "auto-sched-priced-0", Cloud: "priced", VCpu: 4, Ram: 4096, Storage: 100}},
+	}
+	moreExpensive := asmodel.SolutionCopy(onNewVm)
+	moreExpensive.VmsToCreate[0].VCpu = 8
+
+	m := NewMcssga(20, 50, 0.7, 0.2, 20, 100)
+	m.SetMaxReaRtt(clouds)
+	m.SetAvgDepNum(apps)
+	assert.Greater(t, m.Fitness(clouds, apps, onNewVm), m.Fitness(clouds, apps, rejected), "without budget, accepting is better")
+
+	m.MaxHourlyCost = 0.5
+	assert.Less(t, m.Fitness(clouds, apps, onNewVm), m.Fitness(clouds, apps, rejected), "over the budget is worse than rejecting")
+	assert.Less(t, m.Fitness(clouds, apps, moreExpensive), m.Fitness(clouds, apps, onNewVm), "the more it costs, the worse it is")
+
+	m.MaxHourlyCost = 2
+	assert.Greater(t, m.Fitness(clouds, apps, onNewVm), m.Fitness(clouds, apps, rejected), "within the budget, accepting is better")
+}
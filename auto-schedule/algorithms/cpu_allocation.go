@@ -337,8 +337,8 @@ func calcCpuOneApp(vm asmodel.K8sNode, apps map[string]asmodel.Application, this
 // calculate the sum of the weights of some applications for CPU allocation
 func calcAppsSumWeight(apps map[string]asmodel.Application) float64 {
 	var sumWeight float64
-	for _, app := range apps {
-		sumWeight += calcAppWeight(app)
+	for _, appName := range sortedAppNames(apps) { // the sum of floats depends on the order, so we use a fixed order
+		sumWeight += calcAppWeight(apps[appName])
 	}
 	return sumWeight
 }
@@ -4,7 +4,11 @@ The functions group applications using DFS (Depth First Search) according to the
 
 package algorithms
 
-import asmodel "emcontroller/auto-schedule/model"
+import (
+	"sort"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
 
 // application with double directions of dependencies recorded
 type biDirDepApp struct {
@@ -61,12 +65,13 @@ func genBiDir(apps map[string]asmodel.Application) map[string]biDirDepApp {
 }
 
 // group applications according to their dependency using DFS
+// We traverse the applications in order, so that the groups are always the same, and so are the dedicated VMs created for them.
 func groupByDep(apps map[string]asmodel.Application) [][]string {
 	var groups [][]string
 
 	biApps := genBiDir(apps)
-	for appName, biApp := range biApps {
-		if !biApp.visited {
+	for _, appName := range sortedAppNames(apps) {
+		if !biApps[appName].visited {
 			var group []string
 			dfsDep(&biApps, appName, &group)
 			groups = append(groups, group)
@@ -87,14 +92,24 @@ func dfsDep(biApps *map[string]biDirDepApp, appName string, group *[]string) {
 	*group = append(*group, appName)
 
 	// add all applications which have dependency with this one into the same group.
-	for fatherAppName, _ := range (*biApps)[appName].fatherDep {
+	for _, fatherAppName := range sortedNameSet((*biApps)[appName].fatherDep) {
 		if !(*biApps)[fatherAppName].visited {
 			dfsDep(biApps, fatherAppName, group)
 		}
 	}
-	for childAppName, _ := range (*biApps)[appName].childDep {
+	for _, childAppName := range sortedNameSet((*biApps)[appName].childDep) {
 		if !(*biApps)[childAppName].visited {
 			dfsDep(biApps, childAppName, group)
 		}
 	}
 }
+
+// get the names in a set in order
+func sortedNameSet(nameSet map[string]struct{}) []string {
+	names := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -427,6 +427,10 @@ func TestDfsGroup(t *testing.T) {
 		}
 
 		depGroups := groupByDep(testCase.apps)
+		// the groups should be the same every time, so that the dedicated VMs created for them are also the same.
+		for j := 0; j < 10; j++ {
+			assert.Equal(t, depGroups, groupByDep(testCase.apps), fmt.Sprintf("%s: groups in a different order", testCase.name))
+		}
 		t.Log("depGroups:")
 		for _, depGroup := range depGroups {
 			t.Log(depGroup)
@@ -0,0 +1,264 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/astaxie/beego"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+/**
+NOTE:
+All other algorithms are genetic or random, so we cannot know how far their solutions are from the optimal one. Exact is a branch-and-bound algorithm finding the solution with the highest fitness value of Mcssga, as the reference to evaluate the other algorithms. It is only practical for small groups of applications.
+1. The applications are decided one by one, and the dependencies of an application are decided before it, so an application is only accepted when its dependencies are already accepted. The replicas of an application are decided together as one unit, and as they are the same, we only try every multiset of clouds for them rather than every permutation.
+2. The undecided applications are seen as rejected, so every node of the search tree is a complete solution, refined and checked by RefineSoln. Accepting more applications only needs more resources and costs more, so if a node is not acceptable or over the budget, we do not search its children.
+3. The upper bound of the fitness values below a node is the sum of the best possible fitness values of every application: a rejected one has a fixed value; an accepted one can at most get its requested CPU cores, and its RTTs to the decided dependencies on other clouds are known; an undecided one can at most get its requested CPU cores with all RTTs 0. If the upper bound is not better than the best solution found so far, we do not search this node.
+The VM allocation of RefineSoln is a heuristic, so the solution is optimal among the ones that RefineSoln can refine. The algorithms for comparison refine solutions by CmpRefineSoln, which may allocate more CPU cores than applications request, so their fitness values can be higher than that of Exact.
+*/
+
+const ExactMaxAppCount int = 12 // Exact is only practical for so many applications (counting every replica), otherwise it usually reaches the time limit.
+
+// DefaultExactTimeLimit is used when AlgoParams.TimeLimit is not set.
+var DefaultExactTimeLimit time.Duration = 60 * time.Second
+
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        ExactName,
+		Description: fmt.Sprintf("Exact branch-and-bound algorithm, finding the solution with the highest fitness value of Mcssga. Only for about %d applications, and when the time limit (%s by default) is reached, it returns the best solution found so far. For comparison.", ExactMaxAppCount, DefaultExactTimeLimit),
+		Params: func() []ParamSchema {
+			return []ParamSchema{{Name: "expAppCompuTimeOneCpu", Type: "float", Description: "the expected computation time (ms) of applications by one CPU core", Default: DefaultExpAppCompuTimeOneCpu}}
+		},
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			e := NewExact(params.ExpAppCompuTimeOneCpu, params.TimeLimit)
+			e.Ctx = params.Ctx
+			e.MaxHourlyCost = params.MaxHourlyCost
+			return e
+		},
+	})
+}
+
+// Exact branch-and-bound algorithm
+type Exact struct {
+	ExpAppCompuTimeOneCpu float64         // the expected computation time of every application by one CPU core, used for the applications without their own values.  unit millisecond (ms)
+	MaxHourlyCost         float64         // the budget per hour of the VMs to create, see SolutionCost. Not more than 0 means no limit.
+	TimeLimit             time.Duration   // when the search takes so long, we stop it and return the best solution found so far
+	Ctx                   context.Context // when it is done before the time limit, e.g., the client disconnects, we also stop the search. nil means only the time limit.
+
+	// the result of the last Schedule
+	Optimal       bool    // whether the search finished within the time limit, which means that the output solution is optimal
+	ExploredNodes int     // how many solutions are refined and checked
+	BestFitness   float64 // the fitness value of the output solution
+
+	fitnessCalc *Mcssga // calculates the fitness values, and its MaxReachableRtt and AvgDepNum are set by the scheduled clouds and applications
+	units       []exactUnit
+	unitIdxOf   map[string]int     // the index of the unit of every application
+	rejFitness  map[string]float64 // the fitness value of every application if rejected
+	cpuFitness  map[string]float64 // the best possible computation part of the fitness value of every application, weighted by priority
+	deadline    time.Time
+	best        asmodel.Solution
+}
+
+// the applications decided together in Exact, i.e., one application, or all replicas of an application.
+type exactUnit struct {
+	appNames []string
+	options  [][]string // every option is the target clouds of the applications, in the same order as appNames
+}
+
+func NewExact(exTimeOneCpu float64, timeLimit time.Duration) *Exact {
+	if timeLimit <= 0 {
+		timeLimit = DefaultExactTimeLimit
+	}
+	return &Exact{
+		ExpAppCompuTimeOneCpu: exTimeOneCpu,
+		TimeLimit:             timeLimit,
+	}
+}
+
+func (e *Exact) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
+	beego.Info("Using scheduling algorithm:", ExactName)
+	if len(apps) > ExactMaxAppCount {
+		beego.Warning(fmt.Sprintf("%s is only practical for at most %d applications, but there are %d, so it may only return the best solution found in %s.", ExactName, ExactMaxAppCount, len(apps), e.TimeLimit))
+	}
+
+	e.fitnessCalc = NewMcssga(0, 0, 0, 0, 0, e.ExpAppCompuTimeOneCpu)
+	e.fitnessCalc.MaxHourlyCost = e.MaxHourlyCost
+	e.fitnessCalc.SetMaxReaRtt(clouds)
+	e.fitnessCalc.SetAvgDepNum(apps)
+	e.prepare(clouds, apps)
+
+	// the root of the search tree rejects all applications
+	genes := asmodel.GenEmptySoln()
+	for appName := range apps {
+		genes.AppsSolution[appName] = asmodel.SasCopy(asmodel.RejSoln)
+	}
+	refinedSoln, acceptable := RefineSoln(clouds, apps, appsOrder, genes)
+	if !acceptable {
+		return asmodel.Solution{}, fmt.Errorf("the solution rejecting all applications is not acceptable")
+	}
+	e.best = refinedSoln
+	e.BestFitness = e.fitnessCalc.Fitness(clouds, apps, refinedSoln)
+	e.ExploredNodes = 1
+
+	startTime := time.Now()
+	e.deadline = startTime.Add(e.TimeLimit)
+	e.Optimal = e.search(clouds, apps, appsOrder, genes, 0)
+	if e.Optimal {
+		beego.Info(fmt.Sprintf("%s found the optimal solution with fitness value %g in %s, explored nodes: %d.", ExactName, e.BestFitness, time.Since(startTime), e.ExploredNodes))
+	} else {
+		beego.Info(fmt.Sprintf("%s reached the time limit %s or was cancelled, the best fitness value found is %g, explored nodes: %d.", ExactName, e.TimeLimit, e.BestFitness, e.ExploredNodes))
+	}
+
+	return e.best, nil
+}
+
+// prepare the units in the order to decide and the values used by the upper bounds.
+func (e *Exact) prepare(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application) {
+	e.units, e.unitIdxOf = exactUnits(clouds, apps)
+	e.rejFitness = make(map[string]float64)
+	e.cpuFitness = make(map[string]float64)
+	for appName, app := range apps {
+		expCompuTime := e.fitnessCalc.expCompuTimeOneCpu(app)
+		e.rejFitness[appName] = -(expCompuTime + e.fitnessCalc.MaxReachableRtt*e.fitnessCalc.AvgDepNum) / 2 * float64(app.Priority)
+		// an application is never allocated more CPU cores than it requests
+		maxCpu := math.Max(app.Resources.CpuCore, cpuCoreStep)
+		e.cpuFitness[appName] = (expCompuTime - expCompuTime/maxCpu) * float64(app.Priority)
+	}
+}
+
+// Search the children of a node, in which the first "depth" units are decided. genes are the decisions without refinement. The output is false if the time limit is reached.
+func (e *Exact) search(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, genes asmodel.Solution, depth int) bool {
+	if depth == len(e.units) {
+		return true
+	}
+	unit := e.units[depth]
+
+	type child struct {
+		genes   asmodel.Solution
+		fitness float64
+	}
+	var children []child
+	for _, option := range unit.options {
+		if time.Now().After(e.deadline) || searchDone(e.Ctx) {
+			return false
+		}
+		childGenes := asmodel.SolutionCopy(genes)
+		for i, appName := range unit.appNames {
+			childGenes.AppsSolution[appName] = asmodel.SingleAppSolution{
+				Accepted:        true,
+				TargetCloudName: option[i],
+			}
+		}
+		e.ExploredNodes++
+		refinedSoln, acceptable := RefineSoln(clouds, apps, appsOrder, childGenes)
+		if !acceptable || !WithinBudget(clouds, refinedSoln, e.MaxHourlyCost) {
+			continue
+		}
+		fitness := e.fitnessCalc.Fitness(clouds, apps, refinedSoln)
+		if fitness > e.BestFitness+floatDelta {
+			e.best = refinedSoln
+			e.BestFitness = fitness
+		}
+		children = append(children, child{genes: childGenes, fitness: fitness})
+	}
+
+	// We search the better children first, so that we can find good solutions early and prune more nodes. Rejecting this unit is the same solution as this node, so it is tried last.
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].fitness > children[j].fitness
+	})
+	children = append(children, child{genes: genes})
+
+	for _, c := range children {
+		if e.upperBound(clouds, apps, c.genes, depth+1) < e.BestFitness+floatDelta {
+			continue
+		}
+		if !e.search(clouds, apps, appsOrder, c.genes, depth+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// The highest fitness value that the solutions below a node can have. In the node, the first "decided" units are decided in the genes.
+func (e *Exact) upperBound(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, genes asmodel.Solution, decided int) float64 {
+	baseNetPart := e.fitnessCalc.MaxReachableRtt * e.fitnessCalc.AvgDepNum
+
+	var bound float64
+	for _, appName := range sortedAppNames(apps) {
+		app := apps[appName]
+		if e.unitIdxOf[appName] >= decided {
+			bound += e.cpuFitness[appName] + baseNetPart*float64(app.Priority)
+			continue
+		}
+		gene := genes.AppsSolution[appName]
+		if !gene.Accepted {
+			bound += e.rejFitness[appName]
+			continue
+		}
+
+		// The dependencies are decided before this application. Its RTT to a dependency on the same cloud may be 0, if they are on the same VM.
+		netPart := baseNetPart
+		for _, dep := range app.Dependencies {
+			depGene, exist := genes.AppsSolution[dep.AppName]
+			if !exist || !depGene.Accepted || depGene.TargetCloudName == gene.TargetCloudName {
+				continue
+			}
+			netPart -= clouds[gene.TargetCloudName].NetState[depGene.TargetCloudName].Rtt
+		}
+		if netPart < 0 {
+			netPart = 0
+		}
+		bound += e.cpuFitness[appName] + netPart*float64(app.Priority)
+	}
+	return bound
+}
+
+// Group the applications into the units of Exact, in the order that every unit is after the units of its dependencies. Also output the index of the unit of every application.
+func exactUnits(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application) ([]exactUnit, map[string]int) {
+	// The applications with higher priorities are decided earlier, to find good solutions early.
+	unitsAppNames, unitIdxOf := dependencyFirstUnits(apps, func(a, b asmodel.Application) bool {
+		return a.Priority > b.Priority
+	})
+	units := make([]exactUnit, len(unitsAppNames))
+	for i, appNames := range unitsAppNames {
+		units[i] = exactUnit{appNames: appNames, options: exactUnitOptions(clouds, apps, appNames)}
+	}
+	return units, unitIdxOf
+}
+
+// Get all options to accept a unit of applications. Every replica needs a cloud, and the replicas should be across at least MinClouds clouds.
+func exactUnitOptions(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appNames []string) [][]string {
+	firstApp := apps[appNames[0]] // the replicas have the same constraints
+	cloudNames := sortedCloudNames(candidateClouds(clouds, firstApp))
+	leastClouds := 1
+	if len(firstApp.ReplicaOf) != 0 && firstApp.MinClouds > leastClouds {
+		leastClouds = firstApp.MinClouds
+	}
+
+	var options [][]string
+	option := make([]string, len(appNames))
+	// choose clouds with non-decreasing indexes, so every multiset of clouds is chosen once.
+	var choose func(appIdx, minCloudIdx, usedClouds int)
+	choose = func(appIdx, minCloudIdx, usedClouds int) {
+		if appIdx == len(appNames) {
+			if usedClouds >= leastClouds {
+				options = append(options, append([]string{}, option...))
+			}
+			return
+		}
+		for cloudIdx := minCloudIdx; cloudIdx < len(cloudNames); cloudIdx++ {
+			option[appIdx] = cloudNames[cloudIdx]
+			newUsed := usedClouds
+			if appIdx == 0 || cloudIdx != minCloudIdx {
+				newUsed++
+			}
+			choose(appIdx+1, cloudIdx, newUsed)
+		}
+	}
+	choose(0, 0, 0)
+	return options
+}
@@ -0,0 +1,132 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// a small group of applications, which cannot be all accepted on the clouds of replicaCloudsAppsForTest
+func exactCloudsAppsForTest() (map[string]asmodel.Cloud, map[string]asmodel.Application, []string) {
+	clouds, _, _ := replicaCloudsAppsForTest()
+	genRes := func(cpu float64) asmodel.AppResources {
+		return asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: cpu, Memory: 512, Storage: 5}}
+	}
+	apps := asmodel.ExpandReplicas(map[string]asmodel.Application{
+		"frontend": {Name: "frontend", Priority: 5, Resources: genRes(2), Replicas: 2, MinClouds: 2, Dependencies: []models.Dependency{{AppName: "backend"}}},
+		"backend":  {Name: "backend", Priority: 8, Resources: genRes(3), Dependencies: []models.Dependency{{AppName: "db"}}},
+		"db":       {Name: "db", Priority: 3, Resources: genRes(2)},
+		"worker":   {Name: "worker", Priority: 2, Resources: genRes(4)},
+		"batch":    {Name: "batch", Priority: 1, Resources: genRes(4)},
+	})
+	appsOrder := GenerateAppsOrder(apps)
+	sort.Strings(appsOrder)
+	return clouds, apps, appsOrder
+}
+
+func TestInnerExactUnitOptions(t *testing.T) {
+	clouds, apps, _ := replicaCloudsAppsForTest()
+
+	// 3 replicas across at least 2 of 2 clouds
+	options := exactUnitOptions(clouds, apps, []string{"frontend#0", "frontend#1", "frontend#2"})
+	assert.Equal(t, [][]string{{"cloud1", "cloud1", "cloud2"}, {"cloud1", "cloud2", "cloud2"}}, options)
+
+	options = exactUnitOptions(clouds, apps, []string{"backend"})
+	assert.Equal(t, [][]string{{"cloud1"}, {"cloud2"}}, options)
+
+	// the placement constraints are considered
+	backend := apps["backend"]
+	backend.Placement.ForbiddenClouds = []string{"cloud1"}
+	apps["backend"] = backend
+	options = exactUnitOptions(clouds, apps, []string{"backend"})
+	assert.Equal(t, [][]string{{"cloud2"}}, options)
+}
+
+func TestInnerExactUnits(t *testing.T) {
+	clouds, apps, _ := exactCloudsAppsForTest()
+	units, unitIdxOf := exactUnits(clouds, apps)
+
+	var unitApps [][]string
+	for _, unit := range units {
+		unitApps = append(unitApps, unit.appNames)
+	}
+	// the dependencies first, and then higher priorities first
+	assert.Equal(t, [][]string{{"db"}, {"backend"}, {"frontend#0", "frontend#1"}, {"worker"}, {"batch"}}, unitApps)
+	assert.Equal(t, 2, unitIdxOf["frontend#1"])
+}
+
+func TestExactSchedule(t *testing.T) {
+	clouds, apps, appsOrder := exactCloudsAppsForTest()
+
+	exact := NewExact(DefaultExpAppCompuTimeOneCpu, time.Minute)
+	solution, err := exact.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	t.Log(models.JsonString(solution))
+	assert.True(t, exact.Optimal, "the search should finish in the time limit")
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution should be acceptable")
+
+	fitnessCalc := NewMcssga(0, 0, 0, 0, 0, DefaultExpAppCompuTimeOneCpu)
+	fitnessCalc.SetMaxReaRtt(clouds)
+	fitnessCalc.SetAvgDepNum(apps)
+	assert.Equal(t, fitnessCalc.Fitness(clouds, apps, solution), exact.BestFitness)
+
+	// enumerate all solutions to find the optimal one
+	bestFitness := fitnessCalc.Fitness(clouds, apps, RandomAcceptMostSolution(clouds, apps, appsOrder, NewRander(0)))
+	appNames := sortedAppNames(apps)
+	choices := append([]string{""}, sortedCloudNames(clouds)...) // "" means rejected
+	var enumerate func(idx int, genes asmodel.Solution)
+	enumerate = func(idx int, genes asmodel.Solution) {
+		if idx == len(appNames) {
+			if refinedSoln, acceptable := RefineSoln(clouds, apps, appsOrder, genes); acceptable {
+				if fitness := fitnessCalc.Fitness(clouds, apps, refinedSoln); fitness > bestFitness {
+					bestFitness = fitness
+				}
+			}
+			return
+		}
+		for _, choice := range choices {
+			genes.AppsSolution[appNames[idx]] = asmodel.SingleAppSolution{Accepted: len(choice) != 0, TargetCloudName: choice}
+			enumerate(idx+1, genes)
+		}
+	}
+	enumerate(0, asmodel.GenEmptySoln())
+	assert.InDelta(t, bestFitness, exact.BestFitness, floatDelta, "the solution should be optimal")
+	assert.Less(t, exact.ExploredNodes, 243, "some nodes should be pruned") // 243 = 3^5 solutions
+
+	// Mcssga should not be better than the optimal solution
+	mcssga := NewMcssga(20, 50, 0.7, 0.02, 20, DefaultExpAppCompuTimeOneCpu)
+	mcssga.Seed = 20231017
+	mcssgaSoln, err := mcssga.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, fitnessCalc.Fitness(clouds, apps, mcssgaSoln), exact.BestFitness+floatDelta)
+}
+
+func TestExactTimeLimit(t *testing.T) {
+	clouds, apps, appsOrder := exactCloudsAppsForTest()
+
+	exact := NewExact(DefaultExpAppCompuTimeOneCpu, time.Nanosecond)
+	solution, err := exact.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	assert.False(t, exact.Optimal, "the search should be stopped by the time limit")
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), fmt.Sprintf("the best solution found should be acceptable: %s", models.JsonString(solution)))
+}
+
+func TestExactCancelled(t *testing.T) {
+	clouds, apps, appsOrder := exactCloudsAppsForTest()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	exact := NewExact(DefaultExpAppCompuTimeOneCpu, time.Minute)
+	exact.Ctx = ctx
+	solution, err := exact.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	assert.False(t, exact.Optimal, "the search should be stopped by the cancelled context")
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), fmt.Sprintf("the best solution found should be acceptable: %s", models.JsonString(solution)))
+}
@@ -1,14 +1,14 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
 	"net/http"
 	"strconv"
-	"sync"
 
-	"github.com/KeepTheBeats/routing-algorithms/random"
 	"github.com/astaxie/beego"
 	"github.com/wcharczuk/go-chart"
 
@@ -20,6 +20,20 @@ An algorithm for comparison, with name "Accept More Application Genetic Algorith
 In the experiment, we will compare MCSSGA with this algorithm.
 */
 
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        AmagaName,
+		Description: "Accept More Application Genetic Algorithm, maximizing the number of accepted applications. For comparison.",
+		Params:      gaParamsSchema,
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			a := NewAmaga(params.Ga.ChromosomesCount, params.Ga.IterationCount, params.Ga.CrossoverProbability, params.Ga.MutationProbability, params.Ga.StopNoUpdateIteration)
+			a.Seed = params.Seed
+			a.Ctx = params.Ctx
+			return a
+		},
+	})
+}
+
 // Accept More Application Genetic Algorithm (AMAGA)
 type Amaga struct {
 	ChromosomesCount     int // One chromosome is a solution
@@ -31,6 +45,11 @@ type Amaga struct {
 	StopNoUpdateIteration int
 	CurNoUpdateIteration  int // record how many iterations the best solution has not updated currently.
 
+	Ctx context.Context // when it is done, e.g., its deadline is reached or the client disconnects, the algorithm stops and returns the best solution so far. nil means no deadline.
+
+	Seed   int64      // the seed of the random generator, with which the same input always gives the same solution
+	rander *rand.Rand // the random generator of this scheduling, created from Seed
+
 	// these 2 member variables record the best solution in all iteration as well as its fitness value
 	BestFitnessRecords []float64
 	BestSolnRecords    []asmodel.Solution
@@ -47,6 +66,7 @@ func NewAmaga(chromosomesCount int, iterationCount int, crossoverProbability flo
 		MutationProbability:   mutationProbability,
 		StopNoUpdateIteration: stopNoUpdateIteration,
 		CurNoUpdateIteration:  0,
+		Seed:                  NewSeed(),
 		BestFitnessRecords:    nil,
 		BestSolnRecords:       nil,
 		BestFitnessEachIter:   nil,
@@ -55,6 +75,7 @@ func NewAmaga(chromosomesCount int, iterationCount int, crossoverProbability flo
 
 func (a *Amaga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
 	beego.Info("Using scheduling algorithm:", AmagaName)
+	a.rander = NewRander(a.Seed)
 
 	// randomly generate the init population
 	var initPopulation []asmodel.Solution = a.initialize(clouds, apps, appsOrder)
@@ -64,6 +85,11 @@ func (a *Amaga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmode
 
 	// No. 1 iteration to No. m.IterationCount iteration
 	for iteration := 1; iteration <= a.IterationCount; iteration++ {
+		// If the deadline is reached or the scheduling is cancelled, we stop the algorithm and return the best solution so far.
+		if searchDone(a.Ctx) {
+			beego.Info(fmt.Sprintf("%s stopped before iteration %d, because: %s.", AmagaName, iteration, a.Ctx.Err()))
+			break
+		}
 
 		currentPopulation = a.crossoverOperator(clouds, apps, appsOrder, currentPopulation)
 
@@ -85,13 +111,9 @@ func (a *Amaga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmode
 
 // use "best effort random" method to generate some solutions as the init population
 func (a *Amaga) initialize(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) []asmodel.Solution {
-	var initPopulation []asmodel.Solution
-	for i := 0; i < a.ChromosomesCount; i++ {
-		var oneSolution asmodel.Solution = CmpRandomAcceptMostSolution(clouds, apps, appsOrder)
-		initPopulation = append(initPopulation, oneSolution)
-	}
-
-	return initPopulation
+	return genInitPopulation(a.Ctx, a.ChromosomesCount, a.rander, func(rander *rand.Rand) asmodel.Solution {
+		return CmpRandomAcceptMostSolution(clouds, apps, appsOrder, rander)
+	})
 }
 
 // the selection operator of Genetic Algorithm
@@ -100,21 +122,10 @@ func (a *Amaga) selectionOperator(clouds map[string]asmodel.Cloud, apps map[stri
 	// calculate the fitness of every chromosome in the current (old) population
 	fitnesses := make([]float64, len(population))
 
-	var fitMu sync.Mutex  // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // calculate the fitness of every chromosome in parallel
-	for i := 0; i < len(population); i++ {
-		wg.Add(1)
-		go func(chromIdx int) {
-			defer wg.Done()
-
-			thisFitness := a.Fitness(clouds, apps, population[chromIdx])
-
-			fitMu.Lock()
-			fitnesses[chromIdx] = thisFitness
-			fitMu.Unlock()
-		}(i)
-	}
-	wg.Wait()
+	// calculate the fitness of every chromosome in parallel
+	parallelFor(len(population), func(chromIdx int) {
+		fitnesses[chromIdx] = a.Fitness(clouds, apps, population[chromIdx])
+	})
 
 	beego.Info("fitness values this iteration:", fitnesses)
 
@@ -131,7 +142,7 @@ func (a *Amaga) selectionOperator(clouds map[string]asmodel.Cloud, apps map[stri
 		var selChrIdx int // selected chromosome index in the input population
 
 		// binary tournament selection
-		picked := random.RandomPickN(pickHelper, 2)
+		picked := randomPickN(a.rander, len(pickHelper), 2)
 		if fitnesses[picked[0]] > fitnesses[picked[1]] { // the larger, the better
 			selChrIdx = picked[0]
 		} else {
@@ -206,7 +217,7 @@ func (a *Amaga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[stri
 	// randomly choose the chromosomes that need crossover. We save their indexes.
 	var idxNeedCrossover []int
 	for i := 0; i < len(population); i++ {
-		if random.RandomFloat64(0, 1) < a.CrossoverProbability {
+		if randomFloat64(a.rander, 0, 1) < a.CrossoverProbability {
 			idxNeedCrossover = append(idxNeedCrossover, i)
 		}
 	}
@@ -218,8 +229,8 @@ func (a *Amaga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[stri
 	// randomly choose chromosome pairs to do crossover
 	var whetherCrossover []bool = make([]bool, len(population))
 
-	var crpoMu sync.Mutex // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // calculate the fitness of every chromosome in parallel
+	// the pairs of chromosomes to do crossover
+	var pairs [][2]asmodel.Solution
 
 	for len(idxNeedCrossover) > 1 { // we can only do crossover when we have at list 2 chromosomes
 
@@ -231,7 +242,7 @@ func (a *Amaga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[stri
 		*/
 
 		// choose first index
-		first := random.RandomInt(0, len(idxNeedCrossover)-1)
+		first := randomInt(a.rander, 0, len(idxNeedCrossover)-1)
 		firstIndex := idxNeedCrossover[first]
 		// mark
 		whetherCrossover[firstIndex] = true
@@ -239,7 +250,7 @@ func (a *Amaga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[stri
 		idxNeedCrossover = append(idxNeedCrossover[:first], idxNeedCrossover[first+1:]...)
 
 		// choose second index
-		second := random.RandomInt(0, len(idxNeedCrossover)-1)
+		second := randomInt(a.rander, 0, len(idxNeedCrossover)-1)
 		secondIndex := idxNeedCrossover[second]
 		// mark
 		whetherCrossover[secondIndex] = true
@@ -254,18 +265,18 @@ func (a *Amaga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[stri
 		firstChromosome := asmodel.SolutionCopy(population[firstIndex])
 		secondChromosome := asmodel.SolutionCopy(population[secondIndex])
 
-		// this AllPossTwoPointCrossover has big workload, and it can be concurrent so we do it concurrently.
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			newFirstChromosome, newSecondChromosome := CmpAllPossTwoPointCrossover(firstChromosome, secondChromosome, clouds, apps, appsOrder)
-			// append the two new chromosomes in crossoveredPopulation
-			crpoMu.Lock()
-			crossoveredPopulation = append(crossoveredPopulation, newFirstChromosome, newSecondChromosome)
-			crpoMu.Unlock()
-		}()
+		pairs = append(pairs, [2]asmodel.Solution{firstChromosome, secondChromosome})
+	}
+
+	// this CmpAllPossTwoPointCrossover has big workload, and it can be concurrent so we do it in parallel. The new chromosomes are put in the order of the pairs, so that the result does not depend on which pair finishes first.
+	crossoveredPairs := make([][2]asmodel.Solution, len(pairs))
+	randers := childRanders(a.rander, len(pairs))
+	parallelFor(len(pairs), func(pairIdx int) {
+		crossoveredPairs[pairIdx][0], crossoveredPairs[pairIdx][1] = CmpAllPossTwoPointCrossover(pairs[pairIdx][0], pairs[pairIdx][1], clouds, apps, appsOrder, randers[pairIdx])
+	})
+	for _, pair := range crossoveredPairs {
+		crossoveredPopulation = append(crossoveredPopulation, pair[0], pair[1])
 	}
-	wg.Wait()
 
 	//beego.Info("whetherCrossover:", whetherCrossover) // for debug
 
@@ -283,50 +294,44 @@ func (a *Amaga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[stri
 func (a *Amaga) mutationOperator(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, population []asmodel.Solution) []asmodel.Solution {
 	var mutatedPopulation []asmodel.Solution = make([]asmodel.Solution, len(population))
 
-	var popuMu sync.Mutex // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // mutate every chromosome in parallel
-
-	for i := 0; i < len(population); i++ { // a chromosome
-		wg.Add(1)
-
-		go func(chromIdx int) {
-			defer wg.Done()
-
-			for { // We repeat mutating a chromosome until the mutated new one is acceptable.
-				mutatedChromosome := asmodel.GenEmptySoln()
-
-				// gene-based mutation
-				for appName, oriGene := range population[chromIdx].AppsSolution {
-					// every gene has the probability "m.MutationProbability" to mutate
-					if random.RandomFloat64(0, 1) < a.MutationProbability {
-						mutatedChromosome.AppsSolution[appName] = a.geneMutate(clouds, oriGene) // mutate
-					} else {
-						mutatedChromosome.AppsSolution[appName] = asmodel.SasCopy(oriGene) // do not mutate
-					}
-				}
-
-				// refine the mutated chromosome and check whether it is acceptable
-				mutatedChromosome, acceptable := CmpRefineSoln(clouds, apps, appsOrder, mutatedChromosome)
-				if acceptable {
-					popuMu.Lock()
-					mutatedPopulation[chromIdx] = mutatedChromosome
-					popuMu.Unlock()
-					break
+	// mutate every chromosome in parallel
+	randers := childRanders(a.rander, len(population))
+	parallelFor(len(population), func(chromIdx int) {
+		rander := randers[chromIdx]
+		for { // We repeat mutating a chromosome until the mutated new one is acceptable.
+			mutatedChromosome := asmodel.GenEmptySoln()
+
+			// gene-based mutation
+			for _, appName := range appsOrder { // in a fixed order, so that the same seed gives the same mutation
+				oriGene := population[chromIdx].AppsSolution[appName]
+				// every gene has the probability "m.MutationProbability" to mutate
+				if randomFloat64(rander, 0, 1) < a.MutationProbability {
+					mutatedChromosome.AppsSolution[appName] = a.geneMutate(candidateClouds(clouds, apps[appName]), oriGene, rander) // mutate, only to the clouds allowed by the placement constraints
+				} else {
+					mutatedChromosome.AppsSolution[appName] = asmodel.SasCopy(oriGene) // do not mutate
 				}
 			}
+			// the replicas of an application should be accepted or rejected together
+			alignReplicaGenes(clouds, apps, mutatedChromosome, rander)
+
+			// refine the mutated chromosome and check whether it is acceptable
+			mutatedChromosome, acceptable := CmpRefineSoln(clouds, apps, appsOrder, mutatedChromosome, rander)
+			if acceptable {
+				mutatedPopulation[chromIdx] = mutatedChromosome
+				break
+			}
+		}
 
-			/**
-			In the above loop, we do not need to save the unacceptable solutions/chromosomes, because the mutated solutions/chromosomes are generated randomly and it is almost impossible to exclude the tried solutions from the following random attempts, so even if we save the unacceptable solutions/chromosomes, it can only save some time to run the function RefineSoln, but cannot reduce the number of random attempts, which I think is not worthy enough.
-			*/
-		}(i)
-	}
-	wg.Wait()
+		/**
+		In the above loop, we do not need to save the unacceptable solutions/chromosomes, because the mutated solutions/chromosomes are generated randomly and it is almost impossible to exclude the tried solutions from the following random attempts, so even if we save the unacceptable solutions/chromosomes, it can only save some time to run the function RefineSoln, but cannot reduce the number of random attempts, which I think is not worthy enough.
+		*/
+	})
 
 	return mutatedPopulation
 }
 
 // The function to mutate a gene. After the mutation, a gene should become a different one, unless it is not accepted originally.
-func (a *Amaga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleAppSolution) asmodel.SingleAppSolution {
+func (a *Amaga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleAppSolution, rander *rand.Rand) asmodel.SingleAppSolution {
 	var mutated asmodel.SingleAppSolution = asmodel.SasCopy(asmodel.RejSoln)
 
 	cloudsToPick := asmodel.CloudMapCopy(clouds)
@@ -334,9 +339,9 @@ func (a *Amaga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleAp
 		delete(cloudsToPick, ori.TargetCloudName) // after mutation, the target cloud should be different
 	}
 
-	mutated.Accepted = random.RandomInt(0, 1) == 0 // 50% accept 50% not
-	if mutated.Accepted {                          // Only when accepted, this gene needs a target cloud.
-		mutated.TargetCloudName, _ = randomCloudMapPick(cloudsToPick)
+	mutated.Accepted = randomInt(rander, 0, 1) == 0 && len(cloudsToPick) > 0 // 50% accept 50% not. If no cloud can be picked, it can only be rejected.
+	if mutated.Accepted {                                                    // Only when accepted, this gene needs a target cloud.
+		mutated.TargetCloudName, _ = randomCloudMapPick(cloudsToPick, rander)
 	}
 
 	return mutated
@@ -1,6 +1,8 @@
 package algorithms
 
 import (
+	"math/rand"
+
 	"github.com/astaxie/beego"
 
 	asmodel "emcontroller/auto-schedule/model"
@@ -11,21 +13,38 @@ An algorithm for comparison, with name "best-effort random algorithm".
 In the experiment, we will compare MCSSGA with this algorithm.
 */
 
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        BERandName,
+		Description: "Best-effort random algorithm, randomly accepting as many applications as possible. For comparison.",
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			b := NewBERand()
+			b.Seed = params.Seed
+			return b
+		},
+	})
+}
+
 // completely random algorithm
 type BERand struct {
+	Seed int64 // the seed of the random generator
 }
 
 func NewBERand() *BERand {
-	return &BERand{}
+	return &BERand{Seed: NewSeed()}
 }
 
 func (m *BERand) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
 	beego.Info("Using scheduling algorithm:", BERandName)
-	return CmpRandomAcceptMostSolution(clouds, apps, appsOrder), nil
+	return CmpRandomAcceptMostSolution(clouds, apps, appsOrder, NewRander(m.Seed)), nil
 }
 
-// Generate a solution randomly, doing the best to accept more applications, the different with that in Mcssga is that this method uses CmpRefineSoln method to refine solutions.
-func CmpRandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) asmodel.Solution {
+// Generate a solution randomly, doing the best to accept more applications, the different with that in Mcssga is that this method uses CmpRefineSoln method to refine solutions. All randomness comes from rander.
+func CmpRandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, rander *rand.Rand) asmodel.Solution {
+	cmpRefine := func(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, soln asmodel.Solution) (asmodel.Solution, bool) {
+		return CmpRefineSoln(clouds, apps, appsOrder, soln, rander)
+	}
+
 	// initialize an all-reject solution with all applications rejected.
 	var solution asmodel.Solution = asmodel.GenEmptySoln()
 	for _, app := range apps {
@@ -39,14 +58,24 @@ func CmpRandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[strin
 	// traverse apps in random order
 	for len(untriedApps) > 0 {
 		// randomly choose an application, trying to deploy it to a cloud.
-		pickedAppName, _ := randomAppMapPick(untriedApps)
+		pickedAppName, pickedApp := randomAppMapPick(untriedApps, rander)
+
+		// The replicas of an application are accepted or rejected together, so we try to place all of them at once.
+		if len(pickedApp.ReplicaOf) != 0 {
+			replicaNames := asmodel.ReplicaGroups(apps)[pickedApp.ReplicaOf]
+			solution = randomAcceptReplicas(clouds, apps, appsOrder, solution, replicaNames, cmpRefine, rander)
+			for _, replicaName := range replicaNames {
+				delete(untriedApps, replicaName)
+			}
+			continue
+		}
 
-		// avoiding changing the original cloud map
-		untriedClouds := asmodel.CloudMapCopy(clouds)
+		// avoiding changing the original cloud map. We only try the clouds allowed by the placement constraints.
+		untriedClouds := asmodel.CloudMapCopy(candidateClouds(clouds, pickedApp))
 		// traverse clouds in random order
 		for len(untriedClouds) > 0 {
 			// randomly choose a cloud, trying to deploy the application to it.
-			pickedCloudName, _ := randomCloudMapPick(untriedClouds)
+			pickedCloudName, _ := randomCloudMapPick(untriedClouds, rander)
 			solution.AppsSolution[pickedAppName] = asmodel.SingleAppSolution{
 				Accepted:        true,
 				TargetCloudName: pickedCloudName,
@@ -54,7 +83,7 @@ func CmpRandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[strin
 
 			// If the randomly chosen cloud and the app constitute an acceptable solution,
 			// we map them in the solution, and "break" to look for a cloud for another application.
-			refinedSoln, acceptable := CmpRefineSoln(clouds, apps, appsOrder, solution)
+			refinedSoln, acceptable := CmpRefineSoln(clouds, apps, appsOrder, solution, rander)
 			if acceptable {
 				// if this solution passes the 3 checks (VM, CPU, acceptable), we set it back to the original solution.
 				solution = asmodel.SolutionCopy(refinedSoln)
@@ -3,24 +3,25 @@ package algorithms
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"sort"
 
 	"github.com/KeepTheBeats/routing-algorithms/mymath"
-	"github.com/KeepTheBeats/routing-algorithms/random"
 	"github.com/astaxie/beego"
 
 	asmodel "emcontroller/auto-schedule/model"
 	apiv1 "k8s.io/api/core/v1"
 )
 
-// the function to refine solutions in the algorithms for comparison.
-func CmpRefineSoln(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, soln asmodel.Solution) (asmodel.Solution, bool) {
+// the function to refine solutions in the algorithms for comparison. The CPUs are allocated randomly by rander.
+func CmpRefineSoln(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, soln asmodel.Solution, rander *rand.Rand) (asmodel.Solution, bool) {
 	// 1. give the solution node names
 	solnWithVm, vmAcceptable := cmpAllocateVms(clouds, apps, appsOrder, soln)
 	if !vmAcceptable {
 		return asmodel.Solution{}, false
 	}
 	// 2. Allocate CPU cores
-	solnWithCpu, cpuAcceptable := cmpAllocateCpus(clouds, apps, appsOrder, solnWithVm)
+	solnWithCpu, cpuAcceptable := cmpAllocateCpus(clouds, apps, appsOrder, solnWithVm, rander)
 	if !cpuAcceptable {
 		return asmodel.Solution{}, false
 	}
@@ -39,9 +40,9 @@ func cmpAllocateVms(clouds map[string]asmodel.Cloud, apps map[string]asmodel.App
 	// If there is original VmsToCreate in the input solution, we ignore them, as this function will generate a new VM allocation scheme from zero.
 	solnWithVm.VmsToCreate = nil
 
-	// We should allocate VMs cloud by cloud.
-	for _, cloud := range clouds {
-		solnWithVmsThisCloud, allocType := cmpAllocateVmsOneCloud(cloud, apps, appsOrder, soln)
+	// We should allocate VMs cloud by cloud, in a fixed order, so that the VMs to create are always in the same order.
+	for _, cloudName := range sortedCloudNames(clouds) {
+		solnWithVmsThisCloud, allocType := cmpAllocateVmsOneCloud(clouds[cloudName], apps, appsOrder, soln)
 		if allocType == UnAcceptable { // if any cloud cannot accept the scheduled applications, this whole solution is not acceptable.
 			return asmodel.Solution{}, false
 		}
@@ -112,7 +113,10 @@ func cmpResAccOneCloud(cloud asmodel.Cloud, apps map[string]asmodel.Application,
 	}
 
 	// On a cloud, when existing VMs do not have enough resources for the applications scheduled here, we try to create a VM with all rest resources.
-	vmToCreate := cloud.GetSharedVmToCreate(0, true)
+	vmToCreate, vmFound := cloud.GetSharedVmToCreate(0, true)
+	if !vmFound {
+		return solnWithVm, false // On the clouds using flavors, no flavor fits the rest resources of this cloud.
+	}
 	k8sNodeToCreate := asmodel.GenK8sNodeFromPods(vmToCreate, []apiv1.Pod{})
 
 	appNamesToThisVm, meetAllRest := vmResMeetAllRestApps(k8sNodeToCreate, apps, &curAppName, appsIter.nextAppName, true)
@@ -136,7 +140,7 @@ func cmpResAccOneCloud(cloud asmodel.Cloud, apps map[string]asmodel.Application,
 }
 
 // allocate cpus to complete this solution in the algorithms for comparison.
-func cmpAllocateCpus(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, solnWithVm asmodel.Solution) (asmodel.Solution, bool) {
+func cmpAllocateCpus(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, solnWithVm asmodel.Solution, rander *rand.Rand) (asmodel.Solution, bool) {
 
 	// This method ues an incremental way to set allocate CPU cores to applications, so before the incremental way, in the base solution, all values of AllocatedCpuCore must be 0.
 	for appName, _ := range apps {
@@ -148,9 +152,9 @@ func cmpAllocateCpus(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Ap
 	// avoiding changing the original solution
 	solnWithCpu := asmodel.SolutionCopy(solnWithVm)
 
-	// We should allocate CPUs cloud by cloud.
-	for _, cloud := range clouds {
-		solnWithCpuThisCloud, acceptable := cmpAllocateCpusOneCloud(cloud, apps, appsOrder, solnWithVm)
+	// We should allocate CPUs cloud by cloud, in a fixed order, because the CPUs are allocated randomly.
+	for _, cloudName := range sortedCloudNames(clouds) {
+		solnWithCpuThisCloud, acceptable := cmpAllocateCpusOneCloud(clouds[cloudName], apps, appsOrder, solnWithVm, rander)
 		if !acceptable { // if any cloud cannot accept the scheduled applications, this whole solution is not acceptable.
 			return asmodel.Solution{}, false
 		}
@@ -163,7 +167,7 @@ func cmpAllocateCpus(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Ap
 }
 
 // allocate cpus in one cloud in the algorithms for comparison.
-func cmpAllocateCpusOneCloud(cloud asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, solnWithVm asmodel.Solution) (asmodel.Solution, bool) {
+func cmpAllocateCpusOneCloud(cloud asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, solnWithVm asmodel.Solution, rander *rand.Rand) (asmodel.Solution, bool) {
 	// For every cloud, at first, we find out the applications scheduled on it.
 	appsThisCloud := findAppsOneCloud(cloud, apps, solnWithVm)
 
@@ -176,10 +180,15 @@ func cmpAllocateCpusOneCloud(cloud asmodel.Cloud, apps map[string]asmodel.Applic
 	// group applications by the VMs on which they are scheduled
 	vmAppGroups := groupAppsByVm(appsThisCloud, appsOrder, solnWithVm)
 
-	// allocate CPUs to applications on each VM
-	for vmName, appNames := range vmAppGroups {
-		vm := getVmByName(vmName, cloud, solnWithVm)                                         // handle this vm
-		solnWithCpuThisVm := cmpAllocateCpusOneVm(vm, apps, appsOrder, appNames, solnWithVm) // allocate CPUs to applications on this VM
+	// allocate CPUs to applications on each VM, in a fixed order, because the CPUs are allocated randomly.
+	vmNames := make([]string, 0, len(vmAppGroups))
+	for vmName := range vmAppGroups {
+		vmNames = append(vmNames, vmName)
+	}
+	sort.Strings(vmNames)
+	for _, vmName := range vmNames {
+		vm := getVmByName(vmName, cloud, solnWithVm)                                                            // handle this vm
+		solnWithCpuThisVm := cmpAllocateCpusOneVm(vm, apps, appsOrder, vmAppGroups[vmName], solnWithVm, rander) // allocate CPUs to applications on this VM
 
 		solnWithCpu.Absorb(solnWithCpuThisVm) // combine the solution of this VM into the solution of this cloud.
 	}
@@ -188,13 +197,13 @@ func cmpAllocateCpusOneCloud(cloud asmodel.Cloud, apps map[string]asmodel.Applic
 }
 
 // allocate CPUs to the applications on a VM in the algorithms for comparison.
-func cmpAllocateCpusOneVm(vm asmodel.K8sNode, apps map[string]asmodel.Application, appsOrder []string, appNamesThisVm []string, solnWithVm asmodel.Solution) asmodel.Solution {
+func cmpAllocateCpusOneVm(vm asmodel.K8sNode, apps map[string]asmodel.Application, appsOrder []string, appNamesThisVm []string, solnWithVm asmodel.Solution, rander *rand.Rand) asmodel.Solution {
 	appsThisVm := filterAppsByNames(appNamesThisVm, apps) // get the applications scheduled to this VM
-	return cmpVmCpuAllocation(vm, appsThisVm, appNamesThisVm, appsOrder, solnWithVm)
+	return cmpVmCpuAllocation(vm, appsThisVm, appNamesThisVm, appsOrder, solnWithVm, rander)
 }
 
 // allocate the CPUs of a VM to the applications scheduled to it, in a completely random way.
-func cmpVmCpuAllocation(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Application, appNamesThisVm []string, appsOrder []string, solnWithVm asmodel.Solution) asmodel.Solution {
+func cmpVmCpuAllocation(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Application, appNamesThisVm []string, appsOrder []string, solnWithVm asmodel.Solution, rander *rand.Rand) asmodel.Solution {
 	var solnWithCpuThisVm asmodel.Solution = asmodel.GenEmptySoln()
 	for appName, _ := range appsThisVm { // the result should only include the solutions for the applications to handle
 		solnWithCpuThisVm.AppsSolution[appName] = asmodel.SasCopy(solnWithVm.AppsSolution[appName])
@@ -217,12 +226,12 @@ func cmpVmCpuAllocation(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Applic
 	appNamesCopy := make([]string, len(appNamesThisVm)) // copy to avoid changing the original variable.
 	copy(appNamesCopy, appNamesThisVm)
 	for len(appNamesCopy) > 0 && vmCopy.ResidualResources.CpuCore > floatDelta { // when the CPU cores are used up, we stop this allocation
-		randIdx := random.RandomInt(0, len(appNamesCopy)-1)
+		randIdx := randomInt(rander, 0, len(appNamesCopy)-1)
 		randAppName := appNamesCopy[randIdx]
 		appNamesCopy = append(appNamesCopy[:randIdx], appNamesCopy[randIdx+1:]...)
 
 		// randomly choose CPU to allocate
-		randCpu := float64(random.RandomInt(0, int(mymath.UnitRound(vmCopy.ResidualResources.CpuCore, 1))))
+		randCpu := float64(randomInt(rander, 0, int(mymath.UnitRound(vmCopy.ResidualResources.CpuCore, 1))))
 
 		thisAppSoln := solnWithCpuThisVm.AppsSolution[randAppName]
 		thisAppSoln.AllocatedCpuCore += randCpu
@@ -247,7 +256,7 @@ func cmpVmCpuAllocation(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Applic
 
 // Deprecated: this method is not random enough.
 // allocate the CPUs of a VM to the applications scheduled to it, in a completely random way. The difference between this and oldCmpVmCpuAllocation3 is that this allocation method retain some resources for the possible future applications, which is more reasonable.
-func oldCmpVmCpuAllocation4(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Application, appNamesThisVm []string, appsOrder []string, solnWithVm asmodel.Solution) asmodel.Solution {
+func oldCmpVmCpuAllocation4(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Application, appNamesThisVm []string, appsOrder []string, solnWithVm asmodel.Solution, rander *rand.Rand) asmodel.Solution {
 	var solnWithCpuThisVm asmodel.Solution = asmodel.GenEmptySoln()
 	for appName, _ := range appsThisVm { // the result should only include the solutions for the applications to handle
 		solnWithCpuThisVm.AppsSolution[appName] = asmodel.SasCopy(solnWithVm.AppsSolution[appName])
@@ -270,9 +279,9 @@ func oldCmpVmCpuAllocation4(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Ap
 	// we only allocate at most len(appNamesThisVm) times, because we should not use up all CPUs, because we should retain some for the possible future applications.
 	for i := 0; i < len(appNamesThisVm) && vmCopy.ResidualResources.CpuCore > floatDelta; i++ {
 		// randomly pick an app to allocate CPU
-		randAppName := appNamesThisVm[random.RandomInt(0, len(appNamesThisVm)-1)]
+		randAppName := appNamesThisVm[randomInt(rander, 0, len(appNamesThisVm)-1)]
 		// randomly choose CPU to allocate
-		randCpu := float64(random.RandomInt(1, int(mymath.UnitRound(vmCopy.ResidualResources.CpuCore, 1))))
+		randCpu := float64(randomInt(rander, 1, int(mymath.UnitRound(vmCopy.ResidualResources.CpuCore, 1))))
 
 		thisAppSoln := solnWithCpuThisVm.AppsSolution[randAppName]
 		thisAppSoln.AllocatedCpuCore += randCpu
@@ -297,7 +306,7 @@ func oldCmpVmCpuAllocation4(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Ap
 
 // Deprecated: this method uses up all CPUs of a VM, but it should still retain some CPUs for the possible future applications.
 // allocate the CPUs of a VM to the applications scheduled to it, in a completely random way.
-func oldCmpVmCpuAllocation3(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Application, appNamesThisVm []string, appsOrder []string, solnWithVm asmodel.Solution) asmodel.Solution {
+func oldCmpVmCpuAllocation3(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Application, appNamesThisVm []string, appsOrder []string, solnWithVm asmodel.Solution, rander *rand.Rand) asmodel.Solution {
 	var solnWithCpuThisVm asmodel.Solution = asmodel.GenEmptySoln()
 	for appName, _ := range appsThisVm { // the result should only include the solutions for the applications to handle
 		solnWithCpuThisVm.AppsSolution[appName] = asmodel.SasCopy(solnWithVm.AppsSolution[appName])
@@ -319,9 +328,9 @@ func oldCmpVmCpuAllocation3(vm asmodel.K8sNode, appsThisVm map[string]asmodel.Ap
 	// Then randomly allocate CPU cores to applications until all CPU cores are allocated
 	for vmCopy.ResidualResources.CpuCore > floatDelta {
 		// randomly pick an app to allocate CPU
-		randAppName := appNamesThisVm[random.RandomInt(0, len(appNamesThisVm)-1)]
+		randAppName := appNamesThisVm[randomInt(rander, 0, len(appNamesThisVm)-1)]
 		// randomly choose CPU to allocate
-		randCpu := float64(random.RandomInt(1, int(mymath.UnitRound(vmCopy.ResidualResources.CpuCore, 1))))
+		randCpu := float64(randomInt(rander, 1, int(mymath.UnitRound(vmCopy.ResidualResources.CpuCore, 1))))
 
 		thisAppSoln := solnWithCpuThisVm.AppsSolution[randAppName]
 		thisAppSoln.AllocatedCpuCore += randCpu
@@ -434,7 +443,7 @@ func oldCmpVmCpuAllocation(vm asmodel.K8sNode, appsThisVm map[string]asmodel.App
 
 // for comparison
 // Randomly explore all possibilities of 2-point crossover, to try to get an acceptable solution. If this function cannot find an acceptable solution after trying all possibilities, it will return the original 2 chromosomes without doing crossover. The only difference with AllPossTwoPointCrossover is that this function use CmpRefineSoln instead of RefineSoln.
-func CmpAllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromosome asmodel.Solution, clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, asmodel.Solution) {
+func CmpAllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromosome asmodel.Solution, clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, rander *rand.Rand) (asmodel.Solution, asmodel.Solution) {
 	// in our unit tests, we will set both the input cloud and apps as nil
 	var testMode bool = clouds == nil && apps == nil
 
@@ -457,7 +466,7 @@ func CmpAllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromos
 	}
 	for len(possiblePointWidths) > 0 {
 		// randomly select a possible point width, and then remove it from the array, in order not to select it again.
-		widthIdx := random.RandomInt(0, len(possiblePointWidths)-1)
+		widthIdx := randomInt(rander, 0, len(possiblePointWidths)-1)
 		pointWidth := possiblePointWidths[widthIdx]
 		possiblePointWidths = append(possiblePointWidths[:widthIdx], possiblePointWidths[widthIdx+1:]...)
 		if testMode {
@@ -471,7 +480,7 @@ func CmpAllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromos
 		}
 		for len(possiblePoint1) > 0 {
 			// randomly select a possible point1, and then remove it from the array, in order not to select it again.
-			pointIdx := random.RandomInt(0, len(possiblePoint1)-1)
+			pointIdx := randomInt(rander, 0, len(possiblePoint1)-1)
 			point1 := possiblePoint1[pointIdx]
 			possiblePoint1 = append(possiblePoint1[:pointIdx], possiblePoint1[pointIdx+1:]...)
 
@@ -491,8 +500,8 @@ func CmpAllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromos
 				crossoveredChromosome1, crossoveredChromosome2 := twoPointCrossover(firstChromosome, secondChromosome, appsOrder, point1, point2)
 
 				// refine the 2 crossovered chromosomes and check whether they are acceptable. If both of them are acceptable, we return them as the result.
-				if crossoveredChromosome1, acceptable1 := CmpRefineSoln(clouds, apps, appsOrder, crossoveredChromosome1); acceptable1 {
-					if crossoveredChromosome2, acceptable2 := CmpRefineSoln(clouds, apps, appsOrder, crossoveredChromosome2); acceptable2 {
+				if crossoveredChromosome1, acceptable1 := CmpRefineSoln(clouds, apps, appsOrder, crossoveredChromosome1, rander); acceptable1 {
+					if crossoveredChromosome2, acceptable2 := CmpRefineSoln(clouds, apps, appsOrder, crossoveredChromosome2, rander); acceptable2 {
 						return crossoveredChromosome1, crossoveredChromosome2
 					}
 				}
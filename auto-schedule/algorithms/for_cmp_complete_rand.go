@@ -3,7 +3,6 @@ package algorithms
 import (
 	"fmt"
 
-	"github.com/KeepTheBeats/routing-algorithms/random"
 	"github.com/astaxie/beego"
 
 	asmodel "emcontroller/auto-schedule/model"
@@ -14,12 +13,25 @@ An algorithm for comparison, with name "completely random algorithm".
 In the experiment, we will compare MCSSGA with this algorithm.
 */
 
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        CompRandName,
+		Description: "Completely random algorithm. For comparison.",
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			c := NewCompRand()
+			c.Seed = params.Seed
+			return c
+		},
+	})
+}
+
 // completely random algorithm
 type CompRand struct {
+	Seed int64 // the seed of the random generator
 }
 
 func NewCompRand() *CompRand {
-	return &CompRand{}
+	return &CompRand{Seed: NewSeed()}
 }
 
 func (m *CompRand) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
@@ -28,21 +40,28 @@ func (m *CompRand) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asm
 	// copy clouds, avoiding changing the original ones.
 	cloudsCopy := asmodel.CloudMapCopy(clouds)
 
+	rander := NewRander(m.Seed)
+
 	var solution asmodel.Solution = asmodel.GenEmptySoln()
-	for appName, _ := range apps {
+	for _, appName := range sortedAppNames(apps) { // in a fixed order, so that the same seed gives the same solution
+		app := apps[appName]
 		var thisAppSoln asmodel.SingleAppSolution
 
-		thisAppSoln.Accepted = random.RandomInt(0, 1) == 0 // randomly set accepted
+		// only the clouds allowed by the placement constraints can be picked
+		allowedClouds := candidateClouds(cloudsCopy, app)
+		thisAppSoln.Accepted = randomInt(rander, 0, 1) == 0 && len(allowedClouds) > 0 // randomly set accepted
 
 		if thisAppSoln.Accepted { // randomly set cloud
-			pickedCloudName, _ := randomCloudMapPick(cloudsCopy)
+			pickedCloudName, _ := randomCloudMapPick(allowedClouds, rander)
 			thisAppSoln.TargetCloudName = pickedCloudName
 		}
 
 		solution.AppsSolution[appName] = thisAppSoln
 	}
+	// the replicas of an application should be accepted or rejected together
+	alignReplicaGenes(clouds, apps, solution, rander)
 
-	refinedSoln, acceptable := CmpRefineSoln(clouds, apps, appsOrder, solution)
+	refinedSoln, acceptable := CmpRefineSoln(clouds, apps, appsOrder, solution, rander)
 	if !acceptable {
 		return asmodel.Solution{}, fmt.Errorf("This time, \"completely random algorithm\" get an unusable solution.")
 	}
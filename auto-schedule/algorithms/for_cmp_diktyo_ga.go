@@ -1,14 +1,14 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
 	"net/http"
 	"strconv"
-	"sync"
 
-	"github.com/KeepTheBeats/routing-algorithms/random"
 	"github.com/astaxie/beego"
 	"github.com/wcharczuk/go-chart"
 
@@ -20,6 +20,20 @@ An algorithm for comparison, with name "Diktyo-GA". The paper "Diktyo: Network-A
 In the experiment, we will compare MCSSGA with this algorithm.
 */
 
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        DiktyogaName,
+		Description: "Genetic algorithm customized from the paper Diktyo, minimizing the network latencies among applications. For comparison.",
+		Params:      gaParamsSchema,
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			d := NewDiktyoga(params.Ga.ChromosomesCount, params.Ga.IterationCount, params.Ga.CrossoverProbability, params.Ga.MutationProbability, params.Ga.StopNoUpdateIteration)
+			d.Seed = params.Seed
+			d.Ctx = params.Ctx
+			return d
+		},
+	})
+}
+
 // Diktyo-GA
 type Diktyoga struct {
 	ChromosomesCount     int // One chromosome is a solution
@@ -34,6 +48,11 @@ type Diktyoga struct {
 	MaxReachableRtt float64 // The biggest RTT between any 2 (or 1) reachable clouds, used to calculate fitness values. unit millisecond (ms)
 	AvgDepNum       float64 // Average dependent application number of all applications
 
+	Ctx context.Context // when it is done, e.g., its deadline is reached or the client disconnects, the algorithm stops and returns the best solution so far. nil means no deadline.
+
+	Seed   int64      // the seed of the random generator, with which the same input always gives the same solution
+	rander *rand.Rand // the random generator of this scheduling, created from Seed
+
 	// these 2 member variables record the best solution in all iteration as well as its fitness value
 	BestFitnessRecords []float64
 	BestSolnRecords    []asmodel.Solution
@@ -51,6 +70,7 @@ func NewDiktyoga(chromosomesCount int, iterationCount int, crossoverProbability
 		StopNoUpdateIteration: stopNoUpdateIteration,
 		CurNoUpdateIteration:  0,
 		MaxReachableRtt:       0,
+		Seed:                  NewSeed(),
 		BestFitnessRecords:    nil,
 		BestSolnRecords:       nil,
 		BestFitnessEachIter:   nil,
@@ -82,6 +102,7 @@ func (d *Diktyoga) SetAvgDepNum(apps map[string]asmodel.Application) {
 
 func (d *Diktyoga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
 	beego.Info("Using scheduling algorithm:", DiktyogaName)
+	d.rander = NewRander(d.Seed)
 	d.SetMaxReaRtt(clouds)
 	beego.Info("MaxReachableRtt:", d.MaxReachableRtt)
 	d.SetAvgDepNum(apps)
@@ -95,6 +116,11 @@ func (d *Diktyoga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asm
 
 	// No. 1 iteration to No. m.IterationCount iteration
 	for iteration := 1; iteration <= d.IterationCount; iteration++ {
+		// If the deadline is reached or the scheduling is cancelled, we stop the algorithm and return the best solution so far.
+		if searchDone(d.Ctx) {
+			beego.Info(fmt.Sprintf("%s stopped before iteration %d, because: %s.", DiktyogaName, iteration, d.Ctx.Err()))
+			break
+		}
 
 		currentPopulation = d.crossoverOperator(clouds, apps, appsOrder, currentPopulation)
 
@@ -116,13 +142,9 @@ func (d *Diktyoga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asm
 
 // use "best effort random" method to generate some solutions as the init population
 func (d *Diktyoga) initialize(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) []asmodel.Solution {
-	var initPopulation []asmodel.Solution
-	for i := 0; i < d.ChromosomesCount; i++ {
-		var oneSolution asmodel.Solution = CmpRandomAcceptMostSolution(clouds, apps, appsOrder)
-		initPopulation = append(initPopulation, oneSolution)
-	}
-
-	return initPopulation
+	return genInitPopulation(d.Ctx, d.ChromosomesCount, d.rander, func(rander *rand.Rand) asmodel.Solution {
+		return CmpRandomAcceptMostSolution(clouds, apps, appsOrder, rander)
+	})
 }
 
 // the selection operator of Genetic Algorithm
@@ -131,21 +153,10 @@ func (d *Diktyoga) selectionOperator(clouds map[string]asmodel.Cloud, apps map[s
 	// calculate the fitness of every chromosome in the current (old) population
 	fitnesses := make([]float64, len(population))
 
-	var fitMu sync.Mutex  // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // calculate the fitness of every chromosome in parallel
-	for i := 0; i < len(population); i++ {
-		wg.Add(1)
-		go func(chromIdx int) {
-			defer wg.Done()
-
-			thisFitness := d.Fitness(clouds, apps, population[chromIdx])
-
-			fitMu.Lock()
-			fitnesses[chromIdx] = thisFitness
-			fitMu.Unlock()
-		}(i)
-	}
-	wg.Wait()
+	// calculate the fitness of every chromosome in parallel
+	parallelFor(len(population), func(chromIdx int) {
+		fitnesses[chromIdx] = d.Fitness(clouds, apps, population[chromIdx])
+	})
 
 	beego.Info("fitness values this iteration:", fitnesses)
 
@@ -162,7 +173,7 @@ func (d *Diktyoga) selectionOperator(clouds map[string]asmodel.Cloud, apps map[s
 		var selChrIdx int // selected chromosome index in the input population
 
 		// binary tournament selection
-		picked := random.RandomPickN(pickHelper, 2)
+		picked := randomPickN(d.rander, len(pickHelper), 2)
 		if fitnesses[picked[0]] > fitnesses[picked[1]] { // the larger, the better
 			selChrIdx = picked[0]
 		} else {
@@ -218,7 +229,7 @@ func (d *Diktyoga) selectionOperator(clouds map[string]asmodel.Cloud, apps map[s
 func (d *Diktyoga) Fitness(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, chromosome asmodel.Solution) float64 {
 	var fitnessValue float64
 
-	for appName, _ := range apps {
+	for _, appName := range sortedAppNames(apps) { // the sum of floats depends on the order, so we use a fixed order
 		fitnessValue += d.fitnessOneApp(clouds, apps, chromosome, appName)
 	}
 
@@ -226,33 +237,30 @@ func (d *Diktyoga) Fitness(clouds map[string]asmodel.Cloud, apps map[string]asmo
 }
 
 // calculate the fitness value contributed by an application. According to the paper Diktyo, this fitness function only considers acceptance rate and network latency.
+// Only if an application sets its own expected computation time, its computation part is also considered in the same way as Mcssga, because the user tells us that the computation of this application matters.
 func (d *Diktyoga) fitnessOneApp(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, chromosome asmodel.Solution, thisAppName string) float64 {
 	var thisAppFitness float64 // result
 
+	expCompuTime := apps[thisAppName].ExpCompuTimeOneCpu // 0 if this application does not set it
+
 	// if an application is rejected, it contributes a big negative fitness value, this is to encourage higher priority-weighted acceptance rate
 	if !chromosome.AppsSolution[thisAppName].Accepted {
-		thisAppFitness = -(d.MaxReachableRtt * d.AvgDepNum) / 2
+		thisAppFitness = -(expCompuTime + d.MaxReachableRtt*d.AvgDepNum) / 2
 	} else {
 
+		// calculate the computation part of this application, which is 0 if this application does not set its expected computation time
+		var thisAppPart float64 = 0
+		if expCompuTime > 0 {
+			thisAppPart = expCompuTime - expCompuTime/chromosome.AppsSolution[thisAppName].AllocatedCpuCore
+		}
+
 		// if this app is accepted, all its dependent apps are also accepted, which is guaranteed by our dependency acceptable check
 
 		netPart := d.MaxReachableRtt * d.AvgDepNum // the base network part of fitness.
 		for _, dep := range apps[thisAppName].Dependencies {
-			depAppName := dep.AppName
-
 			// calculate the network part of the fitness value of this dependency
-			thisCloudName := chromosome.AppsSolution[thisAppName].TargetCloudName
-			depCloudName := chromosome.AppsSolution[depAppName].TargetCloudName
-			thisNodeName := chromosome.AppsSolution[thisAppName].K8sNodeName
-			depNodeName := chromosome.AppsSolution[depAppName].K8sNodeName
-
-			var thisRtt float64 // RTT from this application to the dependent application
-
-			if thisNodeName == depNodeName { // We consider the RTT inside a same VM as 0.
-				thisRtt = 0
-			} else {
-				thisRtt = clouds[thisCloudName].NetState[depCloudName].Rtt
-			}
+			// RTT from this application to the dependent application, which may be a running one. We consider the RTT inside a same VM as 0.
+			thisRtt := depRtt(clouds, chromosome, thisAppName, dep)
 
 			netPart -= thisRtt
 			/**
@@ -266,7 +274,7 @@ func (d *Diktyoga) fitnessOneApp(clouds map[string]asmodel.Cloud, apps map[strin
 			netPart = 0
 		}
 
-		thisAppFitness = netPart
+		thisAppFitness = thisAppPart + netPart
 	}
 
 	return thisAppFitness
@@ -282,7 +290,7 @@ func (d *Diktyoga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[s
 	// randomly choose the chromosomes that need crossover. We save their indexes.
 	var idxNeedCrossover []int
 	for i := 0; i < len(population); i++ {
-		if random.RandomFloat64(0, 1) < d.CrossoverProbability {
+		if randomFloat64(d.rander, 0, 1) < d.CrossoverProbability {
 			idxNeedCrossover = append(idxNeedCrossover, i)
 		}
 	}
@@ -294,8 +302,8 @@ func (d *Diktyoga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[s
 	// randomly choose chromosome pairs to do crossover
 	var whetherCrossover []bool = make([]bool, len(population))
 
-	var crpoMu sync.Mutex // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // calculate the fitness of every chromosome in parallel
+	// the pairs of chromosomes to do crossover
+	var pairs [][2]asmodel.Solution
 
 	for len(idxNeedCrossover) > 1 { // we can only do crossover when we have at list 2 chromosomes
 
@@ -307,7 +315,7 @@ func (d *Diktyoga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[s
 		*/
 
 		// choose first index
-		first := random.RandomInt(0, len(idxNeedCrossover)-1)
+		first := randomInt(d.rander, 0, len(idxNeedCrossover)-1)
 		firstIndex := idxNeedCrossover[first]
 		// mark
 		whetherCrossover[firstIndex] = true
@@ -315,7 +323,7 @@ func (d *Diktyoga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[s
 		idxNeedCrossover = append(idxNeedCrossover[:first], idxNeedCrossover[first+1:]...)
 
 		// choose second index
-		second := random.RandomInt(0, len(idxNeedCrossover)-1)
+		second := randomInt(d.rander, 0, len(idxNeedCrossover)-1)
 		secondIndex := idxNeedCrossover[second]
 		// mark
 		whetherCrossover[secondIndex] = true
@@ -330,18 +338,18 @@ func (d *Diktyoga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[s
 		firstChromosome := asmodel.SolutionCopy(population[firstIndex])
 		secondChromosome := asmodel.SolutionCopy(population[secondIndex])
 
-		// this AllPossTwoPointCrossover has big workload, and it can be concurrent so we do it concurrently.
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			newFirstChromosome, newSecondChromosome := CmpAllPossTwoPointCrossover(firstChromosome, secondChromosome, clouds, apps, appsOrder)
-			// append the two new chromosomes in crossoveredPopulation
-			crpoMu.Lock()
-			crossoveredPopulation = append(crossoveredPopulation, newFirstChromosome, newSecondChromosome)
-			crpoMu.Unlock()
-		}()
+		pairs = append(pairs, [2]asmodel.Solution{firstChromosome, secondChromosome})
+	}
+
+	// this CmpAllPossTwoPointCrossover has big workload, and it can be concurrent so we do it in parallel. The new chromosomes are put in the order of the pairs, so that the result does not depend on which pair finishes first.
+	crossoveredPairs := make([][2]asmodel.Solution, len(pairs))
+	randers := childRanders(d.rander, len(pairs))
+	parallelFor(len(pairs), func(pairIdx int) {
+		crossoveredPairs[pairIdx][0], crossoveredPairs[pairIdx][1] = CmpAllPossTwoPointCrossover(pairs[pairIdx][0], pairs[pairIdx][1], clouds, apps, appsOrder, randers[pairIdx])
+	})
+	for _, pair := range crossoveredPairs {
+		crossoveredPopulation = append(crossoveredPopulation, pair[0], pair[1])
 	}
-	wg.Wait()
 
 	//beego.Info("whetherCrossover:", whetherCrossover) // for debug
 
@@ -359,50 +367,44 @@ func (d *Diktyoga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[s
 func (d *Diktyoga) mutationOperator(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, population []asmodel.Solution) []asmodel.Solution {
 	var mutatedPopulation []asmodel.Solution = make([]asmodel.Solution, len(population))
 
-	var popuMu sync.Mutex // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // mutate every chromosome in parallel
-
-	for i := 0; i < len(population); i++ { // a chromosome
-		wg.Add(1)
-
-		go func(chromIdx int) {
-			defer wg.Done()
-
-			for { // We repeat mutating a chromosome until the mutated new one is acceptable.
-				mutatedChromosome := asmodel.GenEmptySoln()
-
-				// gene-based mutation
-				for appName, oriGene := range population[chromIdx].AppsSolution {
-					// every gene has the probability "m.MutationProbability" to mutate
-					if random.RandomFloat64(0, 1) < d.MutationProbability {
-						mutatedChromosome.AppsSolution[appName] = d.geneMutate(clouds, oriGene) // mutate
-					} else {
-						mutatedChromosome.AppsSolution[appName] = asmodel.SasCopy(oriGene) // do not mutate
-					}
-				}
-
-				// refine the mutated chromosome and check whether it is acceptable
-				mutatedChromosome, acceptable := CmpRefineSoln(clouds, apps, appsOrder, mutatedChromosome)
-				if acceptable {
-					popuMu.Lock()
-					mutatedPopulation[chromIdx] = mutatedChromosome
-					popuMu.Unlock()
-					break
+	// mutate every chromosome in parallel
+	randers := childRanders(d.rander, len(population))
+	parallelFor(len(population), func(chromIdx int) {
+		rander := randers[chromIdx]
+		for { // We repeat mutating a chromosome until the mutated new one is acceptable.
+			mutatedChromosome := asmodel.GenEmptySoln()
+
+			// gene-based mutation
+			for _, appName := range appsOrder { // in a fixed order, so that the same seed gives the same mutation
+				oriGene := population[chromIdx].AppsSolution[appName]
+				// every gene has the probability "m.MutationProbability" to mutate
+				if randomFloat64(rander, 0, 1) < d.MutationProbability {
+					mutatedChromosome.AppsSolution[appName] = d.geneMutate(candidateClouds(clouds, apps[appName]), oriGene, rander) // mutate, only to the clouds allowed by the placement constraints
+				} else {
+					mutatedChromosome.AppsSolution[appName] = asmodel.SasCopy(oriGene) // do not mutate
 				}
 			}
+			// the replicas of an application should be accepted or rejected together
+			alignReplicaGenes(clouds, apps, mutatedChromosome, rander)
+
+			// refine the mutated chromosome and check whether it is acceptable
+			mutatedChromosome, acceptable := CmpRefineSoln(clouds, apps, appsOrder, mutatedChromosome, rander)
+			if acceptable {
+				mutatedPopulation[chromIdx] = mutatedChromosome
+				break
+			}
+		}
 
-			/**
-			In the above loop, we do not need to save the unacceptable solutions/chromosomes, because the mutated solutions/chromosomes are generated randomly and it is almost impossible to exclude the tried solutions from the following random attempts, so even if we save the unacceptable solutions/chromosomes, it can only save some time to run the function RefineSoln, but cannot reduce the number of random attempts, which I think is not worthy enough.
-			*/
-		}(i)
-	}
-	wg.Wait()
+		/**
+		In the above loop, we do not need to save the unacceptable solutions/chromosomes, because the mutated solutions/chromosomes are generated randomly and it is almost impossible to exclude the tried solutions from the following random attempts, so even if we save the unacceptable solutions/chromosomes, it can only save some time to run the function RefineSoln, but cannot reduce the number of random attempts, which I think is not worthy enough.
+		*/
+	})
 
 	return mutatedPopulation
 }
 
 // The function to mutate a gene. After the mutation, a gene should become a different one, unless it is not accepted originally.
-func (d *Diktyoga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleAppSolution) asmodel.SingleAppSolution {
+func (d *Diktyoga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleAppSolution, rander *rand.Rand) asmodel.SingleAppSolution {
 	var mutated asmodel.SingleAppSolution = asmodel.SasCopy(asmodel.RejSoln)
 
 	cloudsToPick := asmodel.CloudMapCopy(clouds)
@@ -410,9 +412,9 @@ func (d *Diktyoga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.Singl
 		delete(cloudsToPick, ori.TargetCloudName) // after mutation, the target cloud should be different
 	}
 
-	mutated.Accepted = random.RandomInt(0, 1) == 0 // 50% accept 50% not
-	if mutated.Accepted {                          // Only when accepted, this gene needs a target cloud.
-		mutated.TargetCloudName, _ = randomCloudMapPick(cloudsToPick)
+	mutated.Accepted = randomInt(rander, 0, 1) == 0 && len(cloudsToPick) > 0 // 50% accept 50% not. If no cloud can be picked, it can only be rejected.
+	if mutated.Accepted {                                                    // Only when accepted, this gene needs a target cloud.
+		mutated.TargetCloudName, _ = randomCloudMapPick(cloudsToPick, rander)
 	}
 
 	return mutated
@@ -0,0 +1,56 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+func TestInnerDiktyogaFitnessOneApp(t *testing.T) {
+	clouds := map[string]asmodel.Cloud{
+		"cloud1": {Name: "cloud1"},
+	}
+	testCases := []struct {
+		name           string
+		app            asmodel.Application
+		appSoln        asmodel.SingleAppSolution
+		expectedResult float64
+	}{
+		{
+			name:           "case accepted without its own time",
+			app:            asmodel.Application{Name: "app1", Priority: 1},
+			appSoln:        asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 2},
+			expectedResult: 0,
+		},
+		{
+			name:           "case accepted with its own time",
+			app:            asmodel.Application{Name: "app1", Priority: 1, ExpCompuTimeOneCpu: 2000},
+			appSoln:        asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 4},
+			expectedResult: 2000 - 2000.0/4,
+		},
+		{
+			name:           "case rejected without its own time",
+			app:            asmodel.Application{Name: "app1", Priority: 1},
+			appSoln:        asmodel.SingleAppSolution{Accepted: false},
+			expectedResult: 0,
+		},
+		{
+			name:           "case rejected with its own time",
+			app:            asmodel.Application{Name: "app1", Priority: 1, ExpCompuTimeOneCpu: 5},
+			appSoln:        asmodel.SingleAppSolution{Accepted: false},
+			expectedResult: -5.0 / 2,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		d := NewDiktyoga(100, 5000, 0.7, 0.2, 200)
+		apps := map[string]asmodel.Application{testCase.app.Name: testCase.app}
+		soln := asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{testCase.app.Name: testCase.appSoln}}
+		actualResult := d.fitnessOneApp(clouds, apps, soln, testCase.app.Name)
+		assert.InDelta(t, testCase.expectedResult, actualResult, testDelta, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
@@ -0,0 +1,131 @@
+package algorithms
+
+import (
+	"fmt"
+
+	"github.com/astaxie/beego"
+
+	"emcontroller/models"
+)
+
+// The parameters of the genetic algorithms (Mcssga, Ampga, Amaga, and Diktyoga).
+type GaParams struct {
+	ChromosomesCount      int     `json:"chromosomesCount"`
+	IterationCount        int     `json:"iterationCount"`
+	CrossoverProbability  float64 `json:"crossoverProbability"`
+	MutationProbability   float64 `json:"mutationProbability"`
+	StopNoUpdateIteration int     `json:"stopNoUpdateIteration"` // stop the iteration if the best solution is not updated for so many iterations
+}
+
+// the keys of the GA parameters in app.conf
+const (
+	GaChromosomesCountKey      string = "GaChromosomesCount"
+	GaIterationCountKey        string = "GaIterationCount"
+	GaCrossoverProbabilityKey  string = "GaCrossoverProbability"
+	GaMutationProbabilityKey   string = "GaMutationProbability"
+	GaStopNoUpdateIterationKey string = "GaStopNoUpdateIteration"
+	GaWorkersKey               string = "GaWorkers"
+)
+
+// DefaultGaParams is used when a request does not set the GA parameters. It can be changed by app.conf.
+var DefaultGaParams GaParams = GaParams{
+	ChromosomesCount:      200,
+	IterationCount:        5000,
+	CrossoverProbability:  0.7,
+	MutationProbability:   0.019,
+	StopNoUpdateIteration: 200,
+}
+
+// InitGaParams reads the default GA parameters and GaWorkers from app.conf. If a parameter is not set, we keep the built-in value. If the parameters are invalid, we use the built-in values.
+func InitGaParams() {
+	initGaWorkers()
+
+	params := DefaultGaParams
+	var errs []error
+	for key, target := range map[string]*int{
+		GaChromosomesCountKey:      &params.ChromosomesCount,
+		GaIterationCountKey:        &params.IterationCount,
+		GaStopNoUpdateIterationKey: &params.StopNoUpdateIteration,
+	} {
+		if len(beego.AppConfig.String(key)) == 0 {
+			continue
+		}
+		value, err := beego.AppConfig.Int(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse [%s] to int, error: [%w]", key, err))
+			continue
+		}
+		*target = value
+	}
+	for key, target := range map[string]*float64{
+		GaCrossoverProbabilityKey: &params.CrossoverProbability,
+		GaMutationProbabilityKey:  &params.MutationProbability,
+	} {
+		if len(beego.AppConfig.String(key)) == 0 {
+			continue
+		}
+		value, err := beego.AppConfig.Float(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse [%s] to float64, error: [%w]", key, err))
+			continue
+		}
+		*target = value
+	}
+	errs = append(errs, ValidateGaParams(params)...)
+
+	if len(errs) != 0 {
+		beego.Error(fmt.Sprintf("The GA parameters %s in app.conf are invalid, error: [%s]. We use the built-in values %s.", models.JsonString(params), models.HandleErrSlice(errs).Error(), models.JsonString(DefaultGaParams)))
+		return
+	}
+	DefaultGaParams = params
+	beego.Info(fmt.Sprintf("The default GA parameters are %s.", models.JsonString(DefaultGaParams)))
+}
+
+// initGaWorkers reads GaWorkers from app.conf. If it is not set or invalid, all CPUs are used.
+func initGaWorkers() {
+	if len(beego.AppConfig.String(GaWorkersKey)) == 0 {
+		return
+	}
+	workers, err := beego.AppConfig.Int(GaWorkersKey)
+	if err != nil {
+		beego.Error(fmt.Sprintf("parse [%s] to int, error: [%s]. The genetic algorithms use all CPUs.", GaWorkersKey, err.Error()))
+		return
+	}
+	GaWorkers = workers
+	beego.Info(fmt.Sprintf("The genetic algorithms use %d workers.", gaWorkerCount()))
+}
+
+// ValidateGaParams checks whether the GA parameters can be used by the algorithms.
+func ValidateGaParams(params GaParams) []error {
+	var allErrs []error
+
+	// crossover needs at least 2 chromosomes
+	if params.ChromosomesCount < 2 {
+		allErrs = append(allErrs, fmt.Errorf("chromosomesCount should be at least 2, but it is [%d]", params.ChromosomesCount))
+	}
+	if params.IterationCount < 1 {
+		allErrs = append(allErrs, fmt.Errorf("iterationCount should be at least 1, but it is [%d]", params.IterationCount))
+	}
+	if params.CrossoverProbability < 0 || params.CrossoverProbability > 1 {
+		allErrs = append(allErrs, fmt.Errorf("crossoverProbability should be in [0, 1], but it is [%g]", params.CrossoverProbability))
+	}
+	if params.MutationProbability < 0 || params.MutationProbability > 1 {
+		allErrs = append(allErrs, fmt.Errorf("mutationProbability should be in [0, 1], but it is [%g]", params.MutationProbability))
+	}
+	if params.StopNoUpdateIteration < 1 {
+		allErrs = append(allErrs, fmt.Errorf("stopNoUpdateIteration should be at least 1, but it is [%d]", params.StopNoUpdateIteration))
+	}
+
+	return allErrs
+}
+
+// the tunable parameters of genetic algorithms, whose default values are the current DefaultGaParams.
+func gaParamsSchema() []ParamSchema {
+	return []ParamSchema{
+		{Name: "chromosomesCount", Type: "int", Description: "the number of chromosomes in the population, at least 2", Default: DefaultGaParams.ChromosomesCount},
+		{Name: "iterationCount", Type: "int", Description: "the maximum number of iterations, at least 1", Default: DefaultGaParams.IterationCount},
+		{Name: "crossoverProbability", Type: "float", Description: "the probability that a chromosome does crossover, in [0, 1]", Default: DefaultGaParams.CrossoverProbability},
+		{Name: "mutationProbability", Type: "float", Description: "the probability that a gene mutates, in [0, 1]", Default: DefaultGaParams.MutationProbability},
+		{Name: "stopNoUpdateIteration", Type: "int", Description: "stop if the best solution is not updated for so many iterations, at least 1", Default: DefaultGaParams.StopNoUpdateIteration},
+	}
+}
@@ -0,0 +1,69 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/models"
+)
+
+func TestValidateGaParams(t *testing.T) {
+	testCases := []struct {
+		name           string
+		params         GaParams
+		expectedErrNum int
+	}{
+		{
+			name:           "default",
+			params:         DefaultGaParams,
+			expectedErrNum: 0,
+		},
+		{
+			name: "small",
+			params: GaParams{
+				ChromosomesCount:      2,
+				IterationCount:        1,
+				CrossoverProbability:  0,
+				MutationProbability:   1,
+				StopNoUpdateIteration: 1,
+			},
+			expectedErrNum: 0,
+		},
+		{
+			name: "counts too small",
+			params: GaParams{
+				ChromosomesCount:      1,
+				IterationCount:        0,
+				CrossoverProbability:  0.7,
+				MutationProbability:   0.019,
+				StopNoUpdateIteration: 0,
+			},
+			expectedErrNum: 3,
+		},
+		{
+			name: "probabilities out of range",
+			params: GaParams{
+				ChromosomesCount:      200,
+				IterationCount:        5000,
+				CrossoverProbability:  1.1,
+				MutationProbability:   -0.1,
+				StopNoUpdateIteration: 200,
+			},
+			expectedErrNum: 2,
+		},
+		{
+			name:           "empty",
+			params:         GaParams{},
+			expectedErrNum: 3,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		errs := ValidateGaParams(testCase.params)
+		t.Log("errors:", models.HandleErrSlice(errs))
+		assert.Equal(t, testCase.expectedErrNum, len(errs), fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
@@ -0,0 +1,127 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/astaxie/beego"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+/**
+NOTE:
+The genetic algorithms take seconds to minutes, which is too slow for interactive deployments, e.g., in development. Greedy is a deterministic first-fit-decreasing algorithm, which decides every application only once:
+1. The applications are sorted by "priority * requested CPU" in descending order, but the dependencies of an application are put before it, because an application can only be accepted when its dependencies are accepted.
+2. Every application is put on the first cloud that can accept it, and the clouds are tried in the ascending order of the sum of the RTTs to its dependencies. The replicas of an application are put on as few clouds as possible, at least MinClouds.
+3. Every try is refined and checked by RefineSoln, which puts the application on an existing Kubernetes node, or creates a VM by Cloud.GetSharedVmToCreate if the existing nodes do not have enough resources.
+*/
+
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        GreedyName,
+		Description: "Deterministic greedy first-fit-decreasing algorithm, putting the applications with larger \"priority * requested CPU\" on the clouds with smaller RTTs to their dependencies first. Fast, for interactive deployments.",
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			g := NewGreedy()
+			g.MaxHourlyCost = params.MaxHourlyCost
+			return g
+		},
+	})
+}
+
+// Greedy first-fit-decreasing algorithm
+type Greedy struct {
+	MaxHourlyCost float64 // the budget per hour of the VMs to create, see SolutionCost. Not more than 0 means no limit. A cloud is not fit for an application if the solution is over the budget.
+}
+
+func NewGreedy() *Greedy {
+	return &Greedy{}
+}
+
+func (g *Greedy) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
+	beego.Info("Using scheduling algorithm:", GreedyName)
+
+	// initialize an all-reject solution with all applications rejected.
+	solution := asmodel.GenEmptySoln()
+	for appName := range apps {
+		solution.AppsSolution[appName] = asmodel.SasCopy(asmodel.RejSoln)
+	}
+	solution, acceptable := RefineSoln(clouds, apps, appsOrder, solution)
+	if !acceptable {
+		return asmodel.Solution{}, fmt.Errorf("the solution rejecting all applications is not acceptable")
+	}
+
+	units, _ := dependencyFirstUnits(apps, func(a, b asmodel.Application) bool {
+		return float64(a.Priority)*a.Resources.CpuCore > float64(b.Priority)*b.Resources.CpuCore
+	})
+	for _, appNames := range units {
+		for _, option := range g.unitOptions(clouds, apps, solution, appNames) {
+			triedSoln := asmodel.SolutionCopy(solution)
+			for i, appName := range appNames {
+				triedSoln.AppsSolution[appName] = asmodel.SingleAppSolution{
+					Accepted:        true,
+					TargetCloudName: option[i],
+				}
+			}
+			refinedSoln, acceptable := RefineSoln(clouds, apps, appsOrder, triedSoln)
+			if acceptable && WithinBudget(clouds, refinedSoln, g.MaxHourlyCost) {
+				solution = refinedSoln
+				break
+			}
+		}
+		// If no option fits, the applications stay rejected.
+	}
+
+	return solution, nil
+}
+
+// Get the options to try for a unit of applications in order. Every option is the target clouds of the applications, in the same order as appNames.
+func (g *Greedy) unitOptions(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution, appNames []string) [][]string {
+	firstApp := apps[appNames[0]] // the replicas have the same constraints and dependencies
+	cloudNames := depRttSortedClouds(clouds, soln, firstApp)
+
+	if len(firstApp.ReplicaOf) == 0 {
+		options := make([][]string, len(cloudNames))
+		for i, cloudName := range cloudNames {
+			options[i] = []string{cloudName}
+		}
+		return options
+	}
+
+	// The replicas are put on "cloudsToUse" adjacent clouds in the order in turn. Fewer clouds are tried first, so the replicas are near to their dependencies.
+	leastClouds := int(math.Max(float64(firstApp.MinClouds), 1))
+	mostClouds := int(math.Min(float64(len(appNames)), float64(len(cloudNames))))
+	var options [][]string
+	for cloudsToUse := leastClouds; cloudsToUse <= mostClouds; cloudsToUse++ {
+		for start := 0; start+cloudsToUse <= len(cloudNames); start++ {
+			option := make([]string, len(appNames))
+			for i := range appNames {
+				option[i] = cloudNames[start+i%cloudsToUse]
+			}
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// Get the clouds allowed for an application in the ascending order of the sum of the RTTs to its dependencies, which are accepted or running. The order of the clouds with the same sum is by name.
+func depRttSortedClouds(clouds map[string]asmodel.Cloud, soln asmodel.Solution, app asmodel.Application) []string {
+	cloudNames := sortedCloudNames(candidateClouds(clouds, app))
+	rttSums := make(map[string]float64)
+	for _, cloudName := range cloudNames {
+		for _, dep := range app.Dependencies {
+			depLocs, available := depLocations(clouds, soln, dep.AppName)
+			if !available {
+				continue
+			}
+			// the node in the solution is not decided yet, so the RTT is between clouds.
+			rtt, _ := accessDep(clouds, asmodel.SingleAppSolution{Accepted: true, TargetCloudName: cloudName}, depLocs, dep)
+			rttSums[cloudName] += rtt
+		}
+	}
+	sort.SliceStable(cloudNames, func(i, j int) bool {
+		return rttSums[cloudNames[i]] < rttSums[cloudNames[j]]
+	})
+	return cloudNames
+}
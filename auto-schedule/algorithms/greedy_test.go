@@ -0,0 +1,56 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func TestInnerDependencyFirstUnits(t *testing.T) {
+	_, apps, _ := exactCloudsAppsForTest()
+	units, unitIdxOf := dependencyFirstUnits(apps, func(a, b asmodel.Application) bool {
+		return float64(a.Priority)*a.Resources.CpuCore > float64(b.Priority)*b.Resources.CpuCore
+	})
+	// frontend (5*2) is before worker (2*4), but after its dependencies backend and db. batch (1*4) is the last.
+	assert.Equal(t, [][]string{{"db"}, {"backend"}, {"frontend#0", "frontend#1"}, {"worker"}, {"batch"}}, units)
+	assert.Equal(t, 4, unitIdxOf["batch"])
+}
+
+func TestGreedySchedule(t *testing.T) {
+	clouds, apps, appsOrder := exactCloudsAppsForTest()
+
+	solution, err := NewGreedy().Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	t.Log(models.JsonString(solution))
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution should be acceptable")
+	for _, appName := range []string{"db", "backend", "frontend#0", "frontend#1"} {
+		assert.True(t, solution.AppsSolution[appName].Accepted, fmt.Sprintf("%s should be accepted", appName))
+	}
+	// backend is put on the cloud of its dependency db, which has the smallest RTT.
+	assert.Equal(t, solution.AppsSolution["db"].TargetCloudName, solution.AppsSolution["backend"].TargetCloudName)
+	// the replicas across 2 clouds
+	assert.NotEqual(t, solution.AppsSolution["frontend#0"].TargetCloudName, solution.AppsSolution["frontend#1"].TargetCloudName)
+
+	// Greedy is deterministic
+	solutionAgain, err := NewGreedy().Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err)
+	assert.Equal(t, solution, solutionAgain)
+}
+
+func TestInnerDepRttSortedClouds(t *testing.T) {
+	clouds, apps, _ := exactCloudsAppsForTest()
+	soln := asmodel.GenEmptySoln()
+	for appName := range apps {
+		soln.AppsSolution[appName] = asmodel.SasCopy(asmodel.RejSoln)
+	}
+
+	// without accepted dependencies, the clouds are sorted by name.
+	assert.Equal(t, []string{"cloud1", "cloud2"}, depRttSortedClouds(clouds, soln, apps["backend"]))
+
+	soln.AppsSolution["db"] = asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node2-1", AllocatedCpuCore: 2}
+	assert.Equal(t, []string{"cloud2", "cloud1"}, depRttSortedClouds(clouds, soln, apps["backend"]))
+}
@@ -1,14 +1,14 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
 	"net/http"
 	"strconv"
-	"sync"
 
-	"github.com/KeepTheBeats/routing-algorithms/random"
 	"github.com/astaxie/beego"
 	chart "github.com/wcharczuk/go-chart"
 
@@ -25,10 +25,7 @@ Instead, in our init function, mutation selector, crossover selector, we should
 */
 
 const (
-	// TODO: currently, this is measured. In the future, this may be able to:
-	// possibility 1. be set by a dedicated user request;
-	// possibility 2. be set in the user request for auto-scheduling;
-	// possibility 3. be set for every application separately;
+	// Currently, this is measured. It can be set in the user request for auto-scheduling (header Expected-Time-One-Cpu), and every application can also set its own value (ExpCompuTimeOneCpu).
 	// DefaultExpAppCompuTimeOneCpu float64 = 50
 	DefaultExpAppCompuTimeOneCpu float64 = 42.629 // expected computation time by one CPU core, unit: ms
 
@@ -36,6 +33,23 @@ const (
 	enlargerScaleMaxRTT       float64 = 1.25 // because the minimum computation part is not 0, minimum RTT should also not be 0.
 )
 
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        McssgaName,
+		Description: "Multi-Cloud Service Scheduling Genetic Algorithm, optimizing both computation and communication.",
+		Params: func() []ParamSchema {
+			return append(gaParamsSchema(), ParamSchema{Name: "expAppCompuTimeOneCpu", Type: "float", Description: "the expected computation time (ms) of applications by one CPU core", Default: DefaultExpAppCompuTimeOneCpu})
+		},
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			m := NewMcssga(params.Ga.ChromosomesCount, params.Ga.IterationCount, params.Ga.CrossoverProbability, params.Ga.MutationProbability, params.Ga.StopNoUpdateIteration, params.ExpAppCompuTimeOneCpu)
+			m.MaxHourlyCost = params.MaxHourlyCost
+			m.Seed = params.Seed
+			m.Ctx = params.Ctx
+			return m
+		},
+	})
+}
+
 // Multi-Cloud Service Scheduling Genetic Algorithm (MCSSGA)
 type Mcssga struct {
 	ChromosomesCount     int // One chromosome is a solution
@@ -49,8 +63,14 @@ type Mcssga struct {
 
 	MaxReachableRtt       float64            // The biggest RTT between any 2 (or 1) reachable clouds, used to calculate fitness values. unit millisecond (ms)
 	AvgDepNum             float64            // Average dependent application number of all applications
-	ExpAppCompuTimeOneCpu float64            // the expected computation time of every application by one CPU core.  unit millisecond (ms)
+	ExpAppCompuTimeOneCpu float64            // the expected computation time of every application by one CPU core, used for the applications without their own values.  unit millisecond (ms)
 	FitnessNonPriDp       map[string]float64 // the record for dynamic programming in Fitness calculation, to reduce the scheduling time.
+	MaxHourlyCost         float64            // the budget per hour of the VMs to create, see SolutionCost. Not more than 0 means no limit.
+
+	Ctx context.Context // when it is done, e.g., its deadline is reached or the client disconnects, the algorithm stops and returns the best solution so far. nil means no deadline.
+
+	Seed   int64      // the seed of the random generator, with which the same input always gives the same solution
+	rander *rand.Rand // the random generator of this scheduling, created from Seed
 
 	// these 2 member variables record the best solution in all iteration as well as its fitness value
 	BestFitnessRecords []float64
@@ -70,6 +90,7 @@ func NewMcssga(chromosomesCount int, iterationCount int, crossoverProbability fl
 		CurNoUpdateIteration:  0,
 		MaxReachableRtt:       0,
 		ExpAppCompuTimeOneCpu: exTimeOneCpu,
+		Seed:                  NewSeed(),
 		BestFitnessRecords:    nil,
 		BestSolnRecords:       nil,
 		BestFitnessEachIter:   nil,
@@ -101,6 +122,7 @@ func (m *Mcssga) SetAvgDepNum(apps map[string]asmodel.Application) {
 
 func (m *Mcssga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
 	beego.Info("Using scheduling algorithm:", McssgaName)
+	m.rander = NewRander(m.Seed)
 	m.SetMaxReaRtt(clouds)
 	beego.Info("MaxReachableRtt:", m.MaxReachableRtt)
 	m.SetAvgDepNum(apps)
@@ -130,6 +152,11 @@ func (m *Mcssga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmod
 
 	// No. 1 iteration to No. m.IterationCount iteration
 	for iteration := 1; iteration <= m.IterationCount; iteration++ {
+		// If the deadline is reached or the scheduling is cancelled, we stop the algorithm and return the best solution so far.
+		if searchDone(m.Ctx) {
+			beego.Info(fmt.Sprintf("%s stopped before iteration %d, because: %s.", McssgaName, iteration, m.Ctx.Err()))
+			break
+		}
 
 		currentPopulation = m.crossoverOperator(clouds, apps, appsOrder, currentPopulation)
 
@@ -151,12 +178,9 @@ func (m *Mcssga) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmod
 
 // randomly generate some solutions as the init population
 func (m *Mcssga) initialize(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) []asmodel.Solution {
-	var initPopulation []asmodel.Solution
-	for i := 0; i < m.ChromosomesCount; i++ {
-		var oneSolution asmodel.Solution = RandomAcceptMostSolution(clouds, apps, appsOrder)
-		initPopulation = append(initPopulation, oneSolution)
-	}
-	return initPopulation
+	return genInitPopulation(m.Ctx, m.ChromosomesCount, m.rander, func(rander *rand.Rand) asmodel.Solution {
+		return RandomAcceptMostSolution(clouds, apps, appsOrder, rander)
+	})
 }
 
 // the crossover operator of Genetic Algorithm.
@@ -169,7 +193,7 @@ func (m *Mcssga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[str
 	// randomly choose the chromosomes that need crossover. We save their indexes.
 	var idxNeedCrossover []int
 	for i := 0; i < len(population); i++ {
-		if random.RandomFloat64(0, 1) < m.CrossoverProbability {
+		if randomFloat64(m.rander, 0, 1) < m.CrossoverProbability {
 			idxNeedCrossover = append(idxNeedCrossover, i)
 		}
 	}
@@ -181,8 +205,8 @@ func (m *Mcssga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[str
 	// randomly choose chromosome pairs to do crossover
 	var whetherCrossover []bool = make([]bool, len(population))
 
-	var crpoMu sync.Mutex // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // calculate the fitness of every chromosome in parallel
+	// the pairs of chromosomes to do crossover
+	var pairs [][2]asmodel.Solution
 
 	for len(idxNeedCrossover) > 1 { // we can only do crossover when we have at list 2 chromosomes
 		/**
@@ -193,7 +217,7 @@ func (m *Mcssga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[str
 		*/
 
 		// choose first index
-		first := random.RandomInt(0, len(idxNeedCrossover)-1)
+		first := randomInt(m.rander, 0, len(idxNeedCrossover)-1)
 		firstIndex := idxNeedCrossover[first]
 		// mark
 		whetherCrossover[firstIndex] = true
@@ -201,7 +225,7 @@ func (m *Mcssga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[str
 		idxNeedCrossover = append(idxNeedCrossover[:first], idxNeedCrossover[first+1:]...)
 
 		// choose second index
-		second := random.RandomInt(0, len(idxNeedCrossover)-1)
+		second := randomInt(m.rander, 0, len(idxNeedCrossover)-1)
 		secondIndex := idxNeedCrossover[second]
 		// mark
 		whetherCrossover[secondIndex] = true
@@ -216,18 +240,18 @@ func (m *Mcssga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[str
 		firstChromosome := asmodel.SolutionCopy(population[firstIndex])
 		secondChromosome := asmodel.SolutionCopy(population[secondIndex])
 
-		// this AllPossTwoPointCrossover has big workload, and it can be concurrent so we do it concurrently.
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			newFirstChromosome, newSecondChromosome := AllPossTwoPointCrossover(firstChromosome, secondChromosome, clouds, apps, appsOrder)
-			// append the two new chromosomes in crossoveredPopulation
-			crpoMu.Lock()
-			crossoveredPopulation = append(crossoveredPopulation, newFirstChromosome, newSecondChromosome)
-			crpoMu.Unlock()
-		}()
+		pairs = append(pairs, [2]asmodel.Solution{firstChromosome, secondChromosome})
+	}
+
+	// this AllPossTwoPointCrossover has big workload, and it can be concurrent so we do it in parallel. The new chromosomes are put in the order of the pairs, so that the result does not depend on which pair finishes first.
+	crossoveredPairs := make([][2]asmodel.Solution, len(pairs))
+	randers := childRanders(m.rander, len(pairs))
+	parallelFor(len(pairs), func(pairIdx int) {
+		crossoveredPairs[pairIdx][0], crossoveredPairs[pairIdx][1] = AllPossTwoPointCrossover(pairs[pairIdx][0], pairs[pairIdx][1], clouds, apps, appsOrder, randers[pairIdx])
+	})
+	for _, pair := range crossoveredPairs {
+		crossoveredPopulation = append(crossoveredPopulation, pair[0], pair[1])
 	}
-	wg.Wait()
 
 	//beego.Info("whetherCrossover:", whetherCrossover) // for debug
 
@@ -242,7 +266,7 @@ func (m *Mcssga) crossoverOperator(clouds map[string]asmodel.Cloud, apps map[str
 }
 
 // Randomly explore all possibilities of 2-point crossover, to try to get an acceptable solution. If this function cannot find an acceptable solution after trying all possibilities, it will return the original 2 chromosomes without doing crossover.
-func AllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromosome asmodel.Solution, clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, asmodel.Solution) {
+func AllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromosome asmodel.Solution, clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, rander *rand.Rand) (asmodel.Solution, asmodel.Solution) {
 	// in our unit tests, we will set both the input cloud and apps as nil
 	var testMode bool = clouds == nil && apps == nil
 
@@ -265,7 +289,7 @@ func AllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromosome
 	}
 	for len(possiblePointWidths) > 0 {
 		// randomly select a possible point width, and then remove it from the array, in order not to select it again.
-		widthIdx := random.RandomInt(0, len(possiblePointWidths)-1)
+		widthIdx := randomInt(rander, 0, len(possiblePointWidths)-1)
 		pointWidth := possiblePointWidths[widthIdx]
 		possiblePointWidths = append(possiblePointWidths[:widthIdx], possiblePointWidths[widthIdx+1:]...)
 		if testMode {
@@ -279,7 +303,7 @@ func AllPossTwoPointCrossover(firstChromosome asmodel.Solution, secondChromosome
 		}
 		for len(possiblePoint1) > 0 {
 			// randomly select a possible point1, and then remove it from the array, in order not to select it again.
-			pointIdx := random.RandomInt(0, len(possiblePoint1)-1)
+			pointIdx := randomInt(rander, 0, len(possiblePoint1)-1)
 			point1 := possiblePoint1[pointIdx]
 			possiblePoint1 = append(possiblePoint1[:pointIdx], possiblePoint1[pointIdx+1:]...)
 
@@ -341,50 +365,44 @@ func calcPoint2(point1 int, pointWidth int) int {
 func (m *Mcssga) mutationOperator(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, population []asmodel.Solution) []asmodel.Solution {
 	var mutatedPopulation []asmodel.Solution = make([]asmodel.Solution, len(population))
 
-	var popuMu sync.Mutex // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // mutate every chromosome in parallel
-
-	for i := 0; i < len(population); i++ { // a chromosome
-		wg.Add(1)
-
-		go func(chromIdx int) {
-			defer wg.Done()
-
-			for { // We repeat mutating a chromosome until the mutated new one is acceptable.
-				mutatedChromosome := asmodel.GenEmptySoln()
-
-				// gene-based mutation
-				for appName, oriGene := range population[chromIdx].AppsSolution {
-					// every gene has the probability "m.MutationProbability" to mutate
-					if random.RandomFloat64(0, 1) < m.MutationProbability {
-						mutatedChromosome.AppsSolution[appName] = m.geneMutate(clouds, oriGene) // mutate
-					} else {
-						mutatedChromosome.AppsSolution[appName] = asmodel.SasCopy(oriGene) // do not mutate
-					}
-				}
-
-				// refine the mutated chromosome and check whether it is acceptable
-				mutatedChromosome, acceptable := RefineSoln(clouds, apps, appsOrder, mutatedChromosome)
-				if acceptable {
-					popuMu.Lock()
-					mutatedPopulation[chromIdx] = mutatedChromosome
-					popuMu.Unlock()
-					break
+	// mutate every chromosome in parallel
+	randers := childRanders(m.rander, len(population))
+	parallelFor(len(population), func(chromIdx int) {
+		rander := randers[chromIdx]
+		for { // We repeat mutating a chromosome until the mutated new one is acceptable.
+			mutatedChromosome := asmodel.GenEmptySoln()
+
+			// gene-based mutation
+			for _, appName := range appsOrder { // in a fixed order, so that the same seed gives the same mutation
+				oriGene := population[chromIdx].AppsSolution[appName]
+				// every gene has the probability "m.MutationProbability" to mutate
+				if randomFloat64(rander, 0, 1) < m.MutationProbability {
+					mutatedChromosome.AppsSolution[appName] = m.geneMutate(candidateClouds(clouds, apps[appName]), oriGene, rander) // mutate, only to the clouds allowed by the placement constraints
+				} else {
+					mutatedChromosome.AppsSolution[appName] = asmodel.SasCopy(oriGene) // do not mutate
 				}
 			}
+			// the replicas of an application should be accepted or rejected together
+			alignReplicaGenes(clouds, apps, mutatedChromosome, rander)
+
+			// refine the mutated chromosome and check whether it is acceptable
+			mutatedChromosome, acceptable := RefineSoln(clouds, apps, appsOrder, mutatedChromosome)
+			if acceptable {
+				mutatedPopulation[chromIdx] = mutatedChromosome
+				break
+			}
+		}
 
-			/**
-			In the above loop, we do not need to save the unacceptable solutions/chromosomes, because the mutated solutions/chromosomes are generated randomly and it is almost impossible to exclude the tried solutions from the following random attempts, so even if we save the unacceptable solutions/chromosomes, it can only save some time to run the function RefineSoln, but cannot reduce the number of random attempts, which I think is not worthy enough.
-			*/
-		}(i)
-	}
-	wg.Wait()
+		/**
+		In the above loop, we do not need to save the unacceptable solutions/chromosomes, because the mutated solutions/chromosomes are generated randomly and it is almost impossible to exclude the tried solutions from the following random attempts, so even if we save the unacceptable solutions/chromosomes, it can only save some time to run the function RefineSoln, but cannot reduce the number of random attempts, which I think is not worthy enough.
+		*/
+	})
 
 	return mutatedPopulation
 }
 
 // The function to mutate a gene. After the mutation, a gene should become a different one, unless it is not accepted originally.
-func (m *Mcssga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleAppSolution) asmodel.SingleAppSolution {
+func (m *Mcssga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleAppSolution, rander *rand.Rand) asmodel.SingleAppSolution {
 	var mutated asmodel.SingleAppSolution = asmodel.SasCopy(asmodel.RejSoln)
 
 	cloudsToPick := asmodel.CloudMapCopy(clouds)
@@ -392,9 +410,9 @@ func (m *Mcssga) geneMutate(clouds map[string]asmodel.Cloud, ori asmodel.SingleA
 		delete(cloudsToPick, ori.TargetCloudName) // after mutation, the target cloud should be different
 	}
 
-	mutated.Accepted = random.RandomInt(0, 1) == 0 // 50% accept 50% not
-	if mutated.Accepted {                          // Only when accepted, this gene needs a target cloud.
-		mutated.TargetCloudName, _ = randomCloudMapPick(cloudsToPick)
+	mutated.Accepted = randomInt(rander, 0, 1) == 0 && len(cloudsToPick) > 0 // 50% accept 50% not. If no cloud can be picked, it can only be rejected.
+	if mutated.Accepted {                                                    // Only when accepted, this gene needs a target cloud.
+		mutated.TargetCloudName, _ = randomCloudMapPick(cloudsToPick, rander)
 	}
 
 	return mutated
@@ -406,21 +424,10 @@ func (m *Mcssga) selectionOperator(clouds map[string]asmodel.Cloud, apps map[str
 	// calculate the fitness of every chromosome in the current (old) population
 	fitnesses := make([]float64, len(population))
 
-	var fitMu sync.Mutex  // the slice in golang is not safe for concurrent read/write
-	var wg sync.WaitGroup // calculate the fitness of every chromosome in parallel
-	for i := 0; i < len(population); i++ {
-		wg.Add(1)
-		go func(chromIdx int) {
-			defer wg.Done()
-
-			thisFitness := m.Fitness(clouds, apps, population[chromIdx])
-
-			fitMu.Lock()
-			fitnesses[chromIdx] = thisFitness
-			fitMu.Unlock()
-		}(i)
-	}
-	wg.Wait()
+	// calculate the fitness of every chromosome in parallel
+	parallelFor(len(population), func(chromIdx int) {
+		fitnesses[chromIdx] = m.Fitness(clouds, apps, population[chromIdx])
+	})
 
 	beego.Info("fitness values this iteration:", fitnesses)
 
@@ -437,7 +444,7 @@ func (m *Mcssga) selectionOperator(clouds map[string]asmodel.Cloud, apps map[str
 		var selChrIdx int // selected chromosome index in the input population
 
 		// binary tournament selection
-		picked := random.RandomPickN(pickHelper, 2)
+		picked := randomPickN(m.rander, len(pickHelper), 2)
 		if fitnesses[picked[0]] > fitnesses[picked[1]] { // the larger, the better
 			selChrIdx = picked[0]
 		} else {
@@ -494,10 +501,21 @@ func (m *Mcssga) Fitness(clouds map[string]asmodel.Cloud, apps map[string]asmode
 	var fitnessValue float64
 
 	//m.FitnessNonPriDp = make(map[string]float64) // clear the dp record
-	for appName, _ := range apps {
+	for _, appName := range sortedAppNames(apps) { // the sum of floats depends on the order, so we use a fixed order
 		fitnessValue += m.fitnessOneApp(clouds, apps, chromosome, appName)
 	}
 
+	// With a budget, a solution costing more is worse than rejecting all applications, which costs nothing, and the more it costs, the worse it is. Then the algorithm prefers existing VMs and cheaper new VMs.
+	if !WithinBudget(clouds, chromosome, m.MaxHourlyCost) {
+		var rejectAllFitness float64
+		for _, appName := range sortedAppNames(apps) {
+			app := apps[appName]
+			rejectAllFitness += -(m.expCompuTimeOneCpu(app) + m.MaxReachableRtt*m.AvgDepNum) / 2 * float64(app.Priority)
+		}
+		overRate := (SolutionCost(clouds, chromosome) - m.MaxHourlyCost) / m.MaxHourlyCost
+		fitnessValue = math.Min(fitnessValue, rejectAllFitness) * (1 + overRate)
+	}
+
 	return fitnessValue
 }
 
@@ -507,14 +525,23 @@ func (m *Mcssga) fitnessOneApp(clouds map[string]asmodel.Cloud, apps map[string]
 	return m.fitnessOneAppNonPri(clouds, apps, chromosome, thisAppName) * float64(thisPri)
 }
 
+// get the expected computation time of an application by one CPU core. If the application does not have its own value, we use the one for all applications.
+func (m *Mcssga) expCompuTimeOneCpu(app asmodel.Application) float64 {
+	if app.ExpCompuTimeOneCpu > 0 {
+		return app.ExpCompuTimeOneCpu
+	}
+	return m.ExpAppCompuTimeOneCpu
+}
+
 // calculate the fitness value contributed by an application without the consideration of its priority
 func (m *Mcssga) fitnessOneAppNonPri(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, chromosome asmodel.Solution, thisAppName string) float64 {
 
 	var thisAppFitnessNonPri float64 // result
+	expCompuTime := m.expCompuTimeOneCpu(apps[thisAppName])
 
 	// if an application is rejected, it contributes a big negative fitness value, this is to encourage higher priority-weighted acceptance rate
 	if !chromosome.AppsSolution[thisAppName].Accepted {
-		thisAppFitnessNonPri = -(m.ExpAppCompuTimeOneCpu + m.MaxReachableRtt*m.AvgDepNum) / 2
+		thisAppFitnessNonPri = -(expCompuTime + m.MaxReachableRtt*m.AvgDepNum) / 2
 	} else {
 
 		// if this app is accepted, all its dependent apps are also accepted, which is guaranteed by our dependency acceptable check
@@ -522,8 +549,8 @@ func (m *Mcssga) fitnessOneAppNonPri(clouds map[string]asmodel.Cloud, apps map[s
 		// calculate the computation part of this application
 		thisAlloCpu := chromosome.AppsSolution[thisAppName].AllocatedCpuCore
 
-		// the maximum possible computation part of fitness of an application without priority should be "m.ExpAppCompuTimeOneCpu"
-		thisAppPart := m.ExpAppCompuTimeOneCpu - m.ExpAppCompuTimeOneCpu/thisAlloCpu
+		// the maximum possible computation part of fitness of an application without priority should be "expCompuTime"
+		thisAppPart := expCompuTime - expCompuTime/thisAlloCpu
 
 		/**
 		An application's fitness is only affected by its computation part and the network part to its dependent applications. We do not need to consider its dependent applications' computation parts, because that parts are already considered in the dependent applications' fitness values.
@@ -531,21 +558,9 @@ func (m *Mcssga) fitnessOneAppNonPri(clouds map[string]asmodel.Cloud, apps map[s
 
 		netPart := m.MaxReachableRtt * m.AvgDepNum // the base network part of fitness.
 		for _, dep := range apps[thisAppName].Dependencies {
-			depAppName := dep.AppName
-
 			// calculate the network part of the fitness value of this dependency
-			thisCloudName := chromosome.AppsSolution[thisAppName].TargetCloudName
-			depCloudName := chromosome.AppsSolution[depAppName].TargetCloudName
-			thisNodeName := chromosome.AppsSolution[thisAppName].K8sNodeName
-			depNodeName := chromosome.AppsSolution[depAppName].K8sNodeName
-
-			var thisRtt float64 // RTT from this application to the dependent application
-
-			if thisNodeName == depNodeName { // We consider the RTT inside a same VM as 0.
-				thisRtt = 0
-			} else {
-				thisRtt = clouds[thisCloudName].NetState[depCloudName].Rtt
-			}
+			// RTT from this application to the dependent application, which may be a running one. We consider the RTT inside a same VM as 0.
+			thisRtt := depRtt(clouds, chromosome, thisAppName, dep)
 
 			netPart -= thisRtt
 			/**
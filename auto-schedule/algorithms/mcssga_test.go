@@ -1,12 +1,14 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
 )
 
 func TestSetMaxReaRtt(t *testing.T) {
@@ -128,7 +130,7 @@ func TestInnerGeneMutate(t *testing.T) {
 		}
 
 		for j := 0; j < loopTimes; j++ {
-			mutated := testCase.m.geneMutate(testCase.clouds, testCase.ori)
+			mutated := testCase.m.geneMutate(testCase.clouds, testCase.ori, NewRander(NewSeed()))
 			t.Logf("mutated %d: %v", j, mutated)
 			if testCase.ori.Accepted {
 				assert.NotEqual(t, testCase.ori, mutated)
@@ -206,7 +208,7 @@ func TestAllPossTwoPointCrossover(t *testing.T) {
 
 	for i, testCase := range testCases {
 		t.Logf("test: %d, %s", i, testCase.name)
-		actualNewCh1, actualNewCh2 := AllPossTwoPointCrossover(testCase.firstChromosome, testCase.secondChromosome, nil, nil, testCase.appsOrder)
+		actualNewCh1, actualNewCh2 := AllPossTwoPointCrossover(testCase.firstChromosome, testCase.secondChromosome, nil, nil, testCase.appsOrder, NewRander(NewSeed()))
 		assert.Equal(t, testCase.expectedNewCh1, actualNewCh1, fmt.Sprintf("%s: result is not expected", testCase.name))
 		assert.Equal(t, testCase.expectedNewCh2, actualNewCh2, fmt.Sprintf("%s: result is not expected", testCase.name))
 	}
@@ -460,3 +462,95 @@ func TestInnerTwoPointCrossover(t *testing.T) {
 		assert.Equal(t, testCase.expectedNewCh2, actualNewCh2, fmt.Sprintf("%s: result is not expected", testCase.name))
 	}
 }
+
+func TestInnerFitnessOneAppNonPri(t *testing.T) {
+	clouds := map[string]asmodel.Cloud{
+		"cloud1": {Name: "cloud1"},
+	}
+	testCases := []struct {
+		name           string
+		app            asmodel.Application
+		appSoln        asmodel.SingleAppSolution
+		expectedResult float64
+	}{
+		{
+			name:           "case accepted with the time for all applications",
+			app:            asmodel.Application{Name: "app1", Priority: 1},
+			appSoln:        asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 2},
+			expectedResult: 50 - 50.0/2,
+		},
+		{
+			name:           "case accepted with its own time",
+			app:            asmodel.Application{Name: "app1", Priority: 1, ExpCompuTimeOneCpu: 2000},
+			appSoln:        asmodel.SingleAppSolution{Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 4},
+			expectedResult: 2000 - 2000.0/4,
+		},
+		{
+			name:           "case rejected with the time for all applications",
+			app:            asmodel.Application{Name: "app1", Priority: 1},
+			appSoln:        asmodel.SingleAppSolution{Accepted: false},
+			expectedResult: -50.0 / 2,
+		},
+		{
+			name:           "case rejected with its own time",
+			app:            asmodel.Application{Name: "app1", Priority: 1, ExpCompuTimeOneCpu: 5},
+			appSoln:        asmodel.SingleAppSolution{Accepted: false},
+			expectedResult: -5.0 / 2,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		m := NewMcssga(100, 5000, 0.7, 0.2, 200, 50)
+		apps := map[string]asmodel.Application{testCase.app.Name: testCase.app}
+		soln := asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{testCase.app.Name: testCase.appSoln}}
+		actualResult := m.fitnessOneAppNonPri(clouds, apps, soln, testCase.app.Name)
+		assert.InDelta(t, testCase.expectedResult, actualResult, testDelta, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestMcssgaScheduleReplicas(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	mcssga := NewMcssga(20, 50, 0.7, 0.2, 20, 100)
+
+	solution, err := mcssga.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err, "Schedule should not return error")
+	t.Log(models.JsonString(solution))
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution should be acceptable")
+	assert.True(t, solution.AppsSolution["frontend#0"].Accepted, "the replicas should be accepted")
+}
+
+func TestGaDeadline(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	params := AlgoParams{
+		ExpAppCompuTimeOneCpu: DefaultExpAppCompuTimeOneCpu,
+		Ga:                    GaParams{ChromosomesCount: 20, IterationCount: 1000, CrossoverProbability: 0.7, MutationProbability: 0.2, StopNoUpdateIteration: 1000},
+		Seed:                  20231017,
+		Ctx:                   cancelledCtx,
+	}
+
+	for _, algoName := range []string{McssgaName, AmagaName, AmpgaName, DiktyogaName} {
+		t.Logf("test: %s", algoName)
+		algo, err := NewAlgorithm(algoName, params)
+		assert.Nil(t, err)
+		solution, err := algo.Schedule(clouds, apps, appsOrder)
+		assert.Nil(t, err, fmt.Sprintf("%s: Schedule should not return error", algoName))
+		assert.True(t, Acceptable(clouds, apps, appsOrder, solution), fmt.Sprintf("%s: the best solution so far should be acceptable", algoName))
+
+		// only the initial population is evaluated
+		var iterCount int
+		switch realAlgo := algo.(type) {
+		case *Mcssga:
+			iterCount = len(realAlgo.BestFitnessEachIter)
+		case *Amaga:
+			iterCount = len(realAlgo.BestFitnessEachIter)
+		case *Ampga:
+			iterCount = len(realAlgo.BestFitnessEachIter)
+		case *Diktyoga:
+			iterCount = len(realAlgo.BestFitnessEachIter)
+		}
+		assert.Equal(t, 1, iterCount, fmt.Sprintf("%s: the algorithm should stop after the No. 0 iteration", algoName))
+	}
+}
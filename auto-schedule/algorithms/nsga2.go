@@ -0,0 +1,682 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+/**
+NOTE:
+Mcssga collapses the acceptance, computation, and communication into one fitness value with some constants. Nsga2 optimizes them as separate objectives in the way of NSGA-II (Non-dominated Sorting Genetic Algorithm II), and works out a Pareto front, i.e., the solutions that are not worse than any other one on all objectives. Then a selection policy picks one solution of the front to deploy.
+The crossover and mutation operators are the same as those of Mcssga, so all solutions in the populations are acceptable.
+*/
+
+// the names of the objectives of Nsga2
+const (
+	ObjAcceptance    string = "acceptance"    // priority-weighted acceptance rate in [0, 1], the larger, the better
+	ObjComputation   string = "computation"   // priority-weighted average expected computation time (ms) of the accepted applications, the smaller, the better
+	ObjCommunication string = "communication" // priority-weighted average sum of the RTTs (ms) from the accepted applications to their dependencies, the smaller, the better
+	ObjCost          string = "cost"          // the cost of the solution, the smaller, the better. Only used when Nsga2.CostFunc is set.
+)
+
+// the policies to pick the solution to deploy from a Pareto front
+const (
+	ParetoKnee    string = "knee"    // the knee point of the front, i.e., the solution farthest from the hyperplane through the extreme points of the front, where improving one objective costs the most of the others
+	ParetoWeights string = "weights" // the solution with the best weighted sum of objectives
+)
+
+// ParetoSelection is how to pick the solution to deploy from a Pareto front.
+type ParetoSelection struct {
+	Policy string `json:"policy"`
+	// Only used by ParetoWeights. The key is the name of an objective, and the objectives not set have weight 0. The objectives are normalized in the front before weighted, so the weights do not depend on the units.
+	Weights map[string]float64 `json:"weights,omitempty"`
+}
+
+// DefaultParetoSelection is used when a request does not set the selection policy.
+var DefaultParetoSelection ParetoSelection = ParetoSelection{Policy: ParetoKnee}
+
+// ParetoPoint is a solution on the Pareto front and its values of the objectives.
+type ParetoPoint struct {
+	Objectives map[string]float64 `json:"objectives"`
+	Solution   asmodel.Solution   `json:"solution"`
+}
+
+// ParetoScheduler is implemented by the multi-objective algorithms, which work out a Pareto front and pick one solution of it as the output of Schedule.
+type ParetoScheduler interface {
+	SchedulingAlgorithm
+	// the Pareto front of the last Schedule, and the index of the picked solution in it.
+	ParetoFront() ([]ParetoPoint, int)
+	// the names of the objectives, which can be weighted in ParetoSelection.
+	ObjectiveNames() []string
+}
+
+func init() {
+	RegisterAlgorithm(AlgoRegistration{
+		Name:        Nsga2Name,
+		Description: "Multi-objective genetic algorithm in the way of NSGA-II, optimizing the acceptance, computation, communication, and the cost of new VMs (if clouds set pricing) separately. It works out a Pareto front and picks one solution by the policy in the header Mcm-Pareto-Selection.",
+		Params: func() []ParamSchema {
+			return append(gaParamsSchema(), ParamSchema{Name: "expAppCompuTimeOneCpu", Type: "float", Description: "the expected computation time (ms) of applications by one CPU core", Default: DefaultExpAppCompuTimeOneCpu})
+		},
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			n := NewNsga2(params.Ga.ChromosomesCount, params.Ga.IterationCount, params.Ga.CrossoverProbability, params.Ga.MutationProbability, params.Ga.StopNoUpdateIteration, params.ExpAppCompuTimeOneCpu, params.Pareto)
+			// the cost is an objective only when some clouds set pricing
+			if len(models.CloudPricings) != 0 {
+				n.CostFunc = func(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) float64 {
+					return SolutionCost(clouds, soln)
+				}
+			}
+			n.MaxHourlyCost = params.MaxHourlyCost
+			n.Seed = params.Seed
+			n.Ctx = params.Ctx
+			return n
+		},
+	})
+}
+
+// Non-dominated Sorting Genetic Algorithm II for multi-cloud service scheduling
+type Nsga2 struct {
+	ChromosomesCount     int // One chromosome is a solution
+	IterationCount       int // In each iteration, a population will be generated. One population consists of some solutions.
+	CrossoverProbability float64
+	MutationProbability  float64
+
+	// If in the past StopNoUpdateIteration iterations, the Pareto front has not been updated, we should end this algorithm.
+	StopNoUpdateIteration int
+	CurNoUpdateIteration  int
+
+	Selection ParetoSelection
+
+	// The function to calculate the cost of a solution. If it is nil, the cost is not an objective.
+	CostFunc func(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) float64
+	// The budget per hour of the VMs to create, see SolutionCost. If some solutions in the front are within it, only they can be picked. Not more than 0 means no limit.
+	MaxHourlyCost float64
+
+	Ctx context.Context // when it is done, e.g., its deadline is reached or the client disconnects, the algorithm stops and works out the Pareto front of the current population. nil means no deadline.
+
+	Seed   int64      // the seed of the random generator, with which the same input always gives the same solution
+	rander *rand.Rand // the random generator of this scheduling, created from Seed, also used by the operators of Mcssga
+
+	// Mcssga provides the crossover and mutation operators and the expected computation time of applications.
+	ops *Mcssga
+
+	// the result of the last Schedule
+	Front    []ParetoPoint
+	Selected int
+}
+
+func NewNsga2(chromosomesCount int, iterationCount int, crossoverProbability float64, mutationProbability float64, stopNoUpdateIteration int, exTimeOneCpu float64, selection ParetoSelection) *Nsga2 {
+	if len(selection.Policy) == 0 {
+		selection = DefaultParetoSelection
+	}
+	return &Nsga2{
+		ChromosomesCount:      chromosomesCount,
+		IterationCount:        iterationCount,
+		CrossoverProbability:  crossoverProbability,
+		MutationProbability:   mutationProbability,
+		StopNoUpdateIteration: stopNoUpdateIteration,
+		CurNoUpdateIteration:  0,
+		Selection:             selection,
+		Seed:                  NewSeed(),
+		ops:                   NewMcssga(chromosomesCount, iterationCount, crossoverProbability, mutationProbability, stopNoUpdateIteration, exTimeOneCpu),
+	}
+}
+
+func (n *Nsga2) ParetoFront() ([]ParetoPoint, int) {
+	return n.Front, n.Selected
+}
+
+// ObjectiveNames returns the names of the objectives used in this instance, in a fixed order.
+func (n *Nsga2) ObjectiveNames() []string {
+	names := []string{ObjAcceptance, ObjComputation, ObjCommunication}
+	if n.CostFunc != nil {
+		names = append(names, ObjCost)
+	}
+	return names
+}
+
+// a solution in the population with the information used by non-dominated sorting.
+type nsgaIndividual struct {
+	soln       asmodel.Solution
+	objectives []float64 // the values to minimize, in the order of ObjectiveNames. The acceptance is negated.
+	rank       int       // 0 is the Pareto front
+	crowding   float64   // crowding distance, the larger, the more diverse
+}
+
+func (n *Nsga2) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
+	beego.Info("Using scheduling algorithm:", Nsga2Name)
+	if errs := ValidateParetoSelection(n.Selection, n.ObjectiveNames()); len(errs) != 0 {
+		return asmodel.Solution{}, fmt.Errorf("invalid Pareto selection: %v", errs)
+	}
+
+	n.rander = NewRander(n.Seed)
+	n.ops.rander = n.rander
+	n.ops.Ctx = n.Ctx
+
+	// randomly generate the init population
+	initSolns := genInitPopulation(n.Ctx, n.ChromosomesCount, n.rander, func(rander *rand.Rand) asmodel.Solution {
+		return RandomAcceptMostSolution(clouds, apps, appsOrder, rander)
+	})
+	population := make([]nsgaIndividual, len(initSolns))
+	parallelFor(len(initSolns), func(i int) {
+		population[i] = n.evaluate(clouds, apps, initSolns[i])
+	})
+	population = n.survive(population)
+	front := frontObjectives(population)
+
+	for iteration := 1; iteration <= n.IterationCount; iteration++ {
+		// If the deadline is reached or the scheduling is cancelled, we stop the algorithm and pick the solution from the current Pareto front.
+		if searchDone(n.Ctx) {
+			beego.Info(fmt.Sprintf("%s stopped before iteration %d, because: %s.", Nsga2Name, iteration, n.Ctx.Err()))
+			break
+		}
+
+		// parents are chosen by binary tournament, and the offspring are generated by the operators of Mcssga.
+		parents := make([]asmodel.Solution, 0, len(population))
+		for i := 0; i < len(population); i++ {
+			parents = append(parents, asmodel.SolutionCopy(tournament(population, n.rander).soln))
+		}
+		offspring := n.ops.crossoverOperator(clouds, apps, appsOrder, parents)
+		offspring = n.ops.mutationOperator(clouds, apps, appsOrder, offspring)
+
+		// the parents and the offspring compete to survive
+		evaluated := make([]nsgaIndividual, len(offspring))
+		parallelFor(len(offspring), func(i int) {
+			evaluated[i] = n.evaluate(clouds, apps, offspring[i])
+		})
+		combined := append(population, evaluated...)
+		population = n.survive(combined)
+
+		// If the front has not been updated in the past some iterations, we stop the algorithm.
+		newFront := frontObjectives(population)
+		if frontUpdated(front, newFront) {
+			n.CurNoUpdateIteration = 0
+		} else {
+			n.CurNoUpdateIteration++
+		}
+		front = newFront
+		if n.CurNoUpdateIteration > n.StopNoUpdateIteration {
+			break
+		}
+	}
+
+	n.Front = n.generateFront(population)
+	n.Selected = n.selectWithinBudget(clouds)
+	beego.Info(fmt.Sprintf("Nsga2 works out a Pareto front with %d solutions, and picks No. %d by the policy [%s].", len(n.Front), n.Selected, n.Selection.Policy))
+	return asmodel.SolutionCopy(n.Front[n.Selected].Solution), nil
+}
+
+// calculate the objectives of a solution
+func (n *Nsga2) evaluate(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) nsgaIndividual {
+	values := n.Objectives(clouds, apps, soln)
+	names := n.ObjectiveNames()
+	objectives := make([]float64, len(names))
+	for i, name := range names {
+		objectives[i] = values[name]
+	}
+	objectives[0] = -objectives[0] // the acceptance is the larger, the better
+	return nsgaIndividual{soln: soln, objectives: objectives}
+}
+
+// Objectives calculates the values of all objectives of a solution.
+func (n *Nsga2) Objectives(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) map[string]float64 {
+	var sumPri, acceptedPri, compuTime, commTime float64
+	for _, appName := range sortedAppNames(apps) { // the sum of floats depends on the order, so we use a fixed order
+		app := apps[appName]
+		pri := float64(app.Priority)
+		sumPri += pri
+		appSoln := soln.AppsSolution[appName]
+		if !appSoln.Accepted {
+			continue
+		}
+		acceptedPri += pri
+		if appSoln.AllocatedCpuCore > 0 {
+			compuTime += pri * n.ops.expCompuTimeOneCpu(app) / appSoln.AllocatedCpuCore
+		}
+		for _, dep := range app.Dependencies {
+			commTime += pri * depRtt(clouds, soln, appName, dep)
+		}
+	}
+
+	values := map[string]float64{
+		ObjAcceptance:    0,
+		ObjComputation:   0,
+		ObjCommunication: 0,
+	}
+	if sumPri > 0 {
+		values[ObjAcceptance] = acceptedPri / sumPri
+	}
+	if acceptedPri > 0 {
+		values[ObjComputation] = compuTime / acceptedPri
+		values[ObjCommunication] = commTime / acceptedPri
+	}
+	if n.CostFunc != nil {
+		values[ObjCost] = n.CostFunc(clouds, apps, soln)
+	}
+	return values
+}
+
+// Do non-dominated sorting and calculate the crowding distances, and then keep ChromosomesCount solutions: the better ranks first, and in the same rank, the larger crowding distances first.
+func (n *Nsga2) survive(population []nsgaIndividual) []nsgaIndividual {
+	fronts := nonDominatedSort(population)
+	survivors := make([]nsgaIndividual, 0, n.ChromosomesCount)
+	for _, front := range fronts {
+		setCrowding(population, front)
+		sort.SliceStable(front, func(i, j int) bool {
+			return population[front[i]].crowding > population[front[j]].crowding
+		})
+		for _, idx := range front {
+			if len(survivors) >= n.ChromosomesCount {
+				return survivors
+			}
+			survivors = append(survivors, population[idx])
+		}
+	}
+	return survivors
+}
+
+// whether a dominates b, i.e., a is not worse on any objective and better on at least one.
+func dominates(a, b []float64) bool {
+	better := false
+	for i := range a {
+		if a[i] > b[i]+floatDelta {
+			return false
+		}
+		if a[i] < b[i]-floatDelta {
+			better = true
+		}
+	}
+	return better
+}
+
+// the fast non-dominated sorting of NSGA-II. It sets the ranks of the individuals and returns the indexes in every front, the best front first.
+func nonDominatedSort(population []nsgaIndividual) [][]int {
+	dominatedBy := make([][]int, len(population)) // dominatedBy[i] are the individuals that i dominates
+	dominationCount := make([]int, len(population))
+	var fronts [][]int
+	var current []int
+	for i := range population {
+		for j := range population {
+			if i == j {
+				continue
+			}
+			if dominates(population[i].objectives, population[j].objectives) {
+				dominatedBy[i] = append(dominatedBy[i], j)
+			} else if dominates(population[j].objectives, population[i].objectives) {
+				dominationCount[i]++
+			}
+		}
+		if dominationCount[i] == 0 {
+			population[i].rank = 0
+			current = append(current, i)
+		}
+	}
+	for rank := 0; len(current) != 0; rank++ {
+		fronts = append(fronts, current)
+		var next []int
+		for _, i := range current {
+			for _, j := range dominatedBy[i] {
+				dominationCount[j]--
+				if dominationCount[j] == 0 {
+					population[j].rank = rank + 1
+					next = append(next, j)
+				}
+			}
+		}
+		current = next
+	}
+	return fronts
+}
+
+// set the crowding distances of the individuals in a front. The ones at the boundaries of any objective have infinite distances.
+func setCrowding(population []nsgaIndividual, front []int) {
+	for _, idx := range front {
+		population[idx].crowding = 0
+	}
+	if len(front) == 0 {
+		return
+	}
+	sorted := append([]int{}, front...)
+	for obj := range population[front[0]].objectives {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return population[sorted[i]].objectives[obj] < population[sorted[j]].objectives[obj]
+		})
+		minValue, maxValue := population[sorted[0]].objectives[obj], population[sorted[len(sorted)-1]].objectives[obj]
+		population[sorted[0]].crowding = math.Inf(1)
+		population[sorted[len(sorted)-1]].crowding = math.Inf(1)
+		if maxValue-minValue < floatDelta {
+			continue
+		}
+		for i := 1; i < len(sorted)-1; i++ {
+			population[sorted[i]].crowding += (population[sorted[i+1]].objectives[obj] - population[sorted[i-1]].objectives[obj]) / (maxValue - minValue)
+		}
+	}
+}
+
+// binary tournament selection by the rank and then the crowding distance
+func tournament(population []nsgaIndividual, rander *rand.Rand) nsgaIndividual {
+	if len(population) < 2 {
+		return population[0]
+	}
+	picked := randomPickN(rander, len(population), 2)
+	a, b := population[picked[0]], population[picked[1]]
+	if a.rank != b.rank {
+		if a.rank < b.rank {
+			return a
+		}
+		return b
+	}
+	if a.crowding > b.crowding {
+		return a
+	}
+	return b
+}
+
+// the objectives of the solutions in the Pareto front of a population
+func frontObjectives(population []nsgaIndividual) [][]float64 {
+	var front [][]float64
+	for _, individual := range population {
+		if individual.rank == 0 {
+			front = append(front, individual.objectives)
+		}
+	}
+	return front
+}
+
+// The front is updated if any solution in the new front is not in the old front.
+func frontUpdated(oldFront, newFront [][]float64) bool {
+	for _, newObjs := range newFront {
+		found := false
+		for _, oldObjs := range oldFront {
+			if sameObjectives(newObjs, oldObjs) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return true
+		}
+	}
+	return false
+}
+
+func sameObjectives(a, b []float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > floatDelta {
+			return false
+		}
+	}
+	return true
+}
+
+// generate the output Pareto front from the final population. The solutions with the same objectives are only kept once, and the front is sorted by the acceptance from high to low, and then by the other objectives from good to bad.
+func (n *Nsga2) generateFront(population []nsgaIndividual) []ParetoPoint {
+	var unique []nsgaIndividual
+	for _, individual := range population {
+		if individual.rank != 0 {
+			continue
+		}
+		duplicated := false
+		for _, kept := range unique {
+			if sameObjectives(kept.objectives, individual.objectives) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			unique = append(unique, individual)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		for obj := range unique[i].objectives {
+			if math.Abs(unique[i].objectives[obj]-unique[j].objectives[obj]) > floatDelta {
+				return unique[i].objectives[obj] < unique[j].objectives[obj]
+			}
+		}
+		return false
+	})
+
+	names := n.ObjectiveNames()
+	front := make([]ParetoPoint, 0, len(unique))
+	for _, individual := range unique {
+		objectives := make(map[string]float64)
+		for i, name := range names {
+			objectives[name] = individual.objectives[i]
+		}
+		objectives[ObjAcceptance] = -objectives[ObjAcceptance]
+		front = append(front, ParetoPoint{Objectives: objectives, Solution: asmodel.SolutionCopy(individual.soln)})
+	}
+	return front
+}
+
+// pick the solution from the front by the selection policy. With a budget, if some solutions are within it, the solution is picked from them.
+func (n *Nsga2) selectWithinBudget(clouds map[string]asmodel.Cloud) int {
+	var candidates []ParetoPoint
+	var candidateIdxs []int
+	for i, point := range n.Front {
+		if WithinBudget(clouds, point.Solution, n.MaxHourlyCost) {
+			candidates = append(candidates, point)
+			candidateIdxs = append(candidateIdxs, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return SelectFromFront(n.Front, n.Selection, n.ObjectiveNames())
+	}
+	return candidateIdxs[SelectFromFront(candidates, n.Selection, n.ObjectiveNames())]
+}
+
+// ValidateParetoSelection checks the selection policy. objNames are the objectives that can be weighted.
+func ValidateParetoSelection(selection ParetoSelection, objNames []string) []error {
+	var errs []error
+	switch selection.Policy {
+	case ParetoKnee:
+		if len(selection.Weights) != 0 {
+			errs = append(errs, fmt.Errorf("the weights are only used by the policy [%s], but the policy is [%s]", ParetoWeights, selection.Policy))
+		}
+	case ParetoWeights:
+		var sumWeights float64
+		for name, weight := range selection.Weights {
+			found := false
+			for _, objName := range objNames {
+				if name == objName {
+					found = true
+					break
+				}
+			}
+			if !found {
+				errs = append(errs, fmt.Errorf("the weight of unknown objective [%s], the objectives are %v", name, objNames))
+			}
+			if weight < 0 {
+				errs = append(errs, fmt.Errorf("the weight of objective [%s] is %g, but it should not be negative", name, weight))
+			}
+			sumWeights += weight
+		}
+		if sumWeights <= 0 {
+			errs = append(errs, fmt.Errorf("with the policy [%s], at least one weight should be positive", ParetoWeights))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown Pareto selection policy [%s], it should be [%s] or [%s]", selection.Policy, ParetoKnee, ParetoWeights))
+	}
+	return errs
+}
+
+// ParseParetoSelection parses the selection policy in the format "<policy>[:<objective>=<weight>,<objective>=<weight>...]", e.g., "knee" or "weights:acceptance=1,communication=0.5". An empty string means DefaultParetoSelection.
+func ParseParetoSelection(value string) (ParetoSelection, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return DefaultParetoSelection, nil
+	}
+	policy, weightsStr, hasWeights := strings.Cut(value, ":")
+	selection := ParetoSelection{Policy: strings.TrimSpace(policy)}
+	if !hasWeights {
+		return selection, nil
+	}
+	selection.Weights = make(map[string]float64)
+	for _, pair := range strings.Split(weightsStr, ",") {
+		name, weightStr, found := strings.Cut(pair, "=")
+		if !found {
+			return ParetoSelection{}, fmt.Errorf("the weight [%s] should be in the format <objective>=<weight>", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+		if err != nil {
+			return ParetoSelection{}, fmt.Errorf("parse the weight of objective [%s] to float64, error: [%w]", name, err)
+		}
+		selection.Weights[strings.TrimSpace(name)] = weight
+	}
+	return selection, nil
+}
+
+// SelectFromFront picks a solution from the Pareto front by the selection policy, and returns its index. The objectives are normalized to [0, 1] in the front, where 0 is the best value in the front and 1 is the worst.
+func SelectFromFront(front []ParetoPoint, selection ParetoSelection, objNames []string) int {
+	if len(front) == 0 {
+		return -1
+	}
+
+	// the best and worst value of every objective in the front
+	best, worst := make(map[string]float64), make(map[string]float64)
+	for _, name := range objNames {
+		best[name], worst[name] = front[0].Objectives[name], front[0].Objectives[name]
+		for _, point := range front {
+			value := point.Objectives[name]
+			if name == ObjAcceptance {
+				best[name], worst[name] = math.Max(best[name], value), math.Min(worst[name], value)
+			} else {
+				best[name], worst[name] = math.Min(best[name], value), math.Max(worst[name], value)
+			}
+		}
+	}
+	normalized := func(point ParetoPoint, name string) float64 {
+		if math.Abs(worst[name]-best[name]) < floatDelta {
+			return 0
+		}
+		return (point.Objectives[name] - best[name]) / (worst[name] - best[name])
+	}
+
+	// the knee is the solution farthest from the hyperplane through the extreme points of the front, on the side of the ideal point.
+	var kneeCoef map[string]float64
+	if selection.Policy != ParetoWeights {
+		kneeCoef = kneeHyperplane(front, objNames, normalized)
+	}
+
+	selected, minScore := 0, math.MaxFloat64
+	for i, point := range front {
+		var score float64
+		for _, name := range objNames {
+			if selection.Policy == ParetoWeights {
+				score += selection.Weights[name] * normalized(point, name)
+			} else { // The hyperplane is "coef · x = 1", so the distance to it on the side of the ideal point is "(1 - coef · x) / |coef|". The smallest "coef · x" is the farthest.
+				score += kneeCoef[name] * normalized(point, name)
+			}
+		}
+		if score < minScore-floatDelta {
+			selected, minScore = i, score
+		}
+	}
+	return selected
+}
+
+// kneeHyperplane returns the coefficients of the hyperplane "coef · x = 1" through the extreme points of the front with normalized objectives. The extreme point of an objective is the solution with the best value of it. The objectives with the same value in the whole front are not dimensions of the hyperplane.
+// If the extreme points cannot decide one hyperplane, e.g., one solution is the extreme point of 2 objectives, we use the hyperplane through the worst value of every objective, i.e., all coefficients are 1.
+func kneeHyperplane(front []ParetoPoint, objNames []string, normalized func(ParetoPoint, string) float64) map[string]float64 {
+	var dims []string
+	for _, name := range objNames {
+		for _, point := range front {
+			if normalized(point, name) > floatDelta {
+				dims = append(dims, name)
+				break
+			}
+		}
+	}
+
+	coef := make(map[string]float64)
+	for _, name := range objNames {
+		coef[name] = 1
+	}
+
+	// the extreme point of every dimension, and if some solutions have the best value, we choose the one with the best other values.
+	matrix := make([][]float64, len(dims))
+	for i, dim := range dims {
+		extreme, minOthers := 0, math.MaxFloat64
+		for j, point := range front {
+			if normalized(point, dim) > floatDelta {
+				continue
+			}
+			var others float64
+			for _, name := range dims {
+				others += normalized(point, name)
+			}
+			if others < minOthers-floatDelta {
+				extreme, minOthers = j, others
+			}
+		}
+		matrix[i] = make([]float64, len(dims))
+		for k, name := range dims {
+			matrix[i][k] = normalized(front[extreme], name)
+		}
+	}
+
+	ones := make([]float64, len(dims))
+	for i := range ones {
+		ones[i] = 1
+	}
+	solved, ok := solveLinearEquations(matrix, ones)
+	if !ok {
+		return coef
+	}
+	for k, name := range dims {
+		coef[name] = solved[k]
+	}
+	return coef
+}
+
+// solveLinearEquations solves "matrix · x = rhs" by Gaussian elimination. The bool is false if the matrix is singular.
+func solveLinearEquations(matrix [][]float64, rhs []float64) ([]float64, bool) {
+	n := len(rhs)
+	if n == 0 {
+		return nil, false
+	}
+	// the augmented matrix, so that we do not change the input
+	aug := make([][]float64, n)
+	for i := range matrix {
+		aug[i] = append(append([]float64{}, matrix[i]...), rhs[i])
+	}
+
+	for col := 0; col < n; col++ {
+		// partial pivoting to reduce the rounding errors
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(aug[row][col]) > math.Abs(aug[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(aug[pivot][col]) < floatDelta {
+			return nil, false
+		}
+		aug[col], aug[pivot] = aug[pivot], aug[col]
+		for row := col + 1; row < n; row++ {
+			factor := aug[row][col] / aug[col][col]
+			for k := col; k <= n; k++ {
+				aug[row][k] -= factor * aug[col][k]
+			}
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := aug[row][n]
+		for k := row + 1; k < n; k++ {
+			sum -= aug[row][k] * x[k]
+		}
+		x[row] = sum / aug[row][row]
+	}
+	return x, true
+}
@@ -0,0 +1,215 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func TestInnerDominates(t *testing.T) {
+	testCases := []struct {
+		name           string
+		a              []float64
+		b              []float64
+		expectedResult bool
+	}{
+		{name: "case better on all", a: []float64{-1, 10, 20}, b: []float64{-0.5, 20, 30}, expectedResult: true},
+		{name: "case better on one", a: []float64{-1, 10, 20}, b: []float64{-1, 10, 30}, expectedResult: true},
+		{name: "case equal", a: []float64{-1, 10, 20}, b: []float64{-1, 10, 20}, expectedResult: false},
+		{name: "case trade-off", a: []float64{-1, 10, 40}, b: []float64{-1, 20, 30}, expectedResult: false},
+		{name: "case worse", a: []float64{-0.5, 20, 30}, b: []float64{-1, 10, 20}, expectedResult: false},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		assert.Equal(t, testCase.expectedResult, dominates(testCase.a, testCase.b), fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerNonDominatedSort(t *testing.T) {
+	population := []nsgaIndividual{
+		{objectives: []float64{-1, 10, 40}},   // front 0
+		{objectives: []float64{-1, 20, 30}},   // front 0
+		{objectives: []float64{-1, 20, 40}},   // front 1, dominated by 0 and 1
+		{objectives: []float64{-0.5, 30, 50}}, // front 2, dominated by 2
+	}
+	fronts := nonDominatedSort(population)
+	assert.Equal(t, [][]int{{0, 1}, {2}, {3}}, fronts)
+	for i, expectedRank := range []int{0, 0, 1, 2} {
+		assert.Equal(t, expectedRank, population[i].rank, fmt.Sprintf("the rank of individual %d is not expected", i))
+	}
+
+	setCrowding(population, fronts[0])
+	assert.True(t, population[0].crowding > 1e9, "the boundary individual should have infinite crowding distance")
+}
+
+func TestParseParetoSelection(t *testing.T) {
+	testCases := []struct {
+		name           string
+		value          string
+		expectedResult ParetoSelection
+		expectedErr    bool
+	}{
+		{name: "case empty", value: "", expectedResult: DefaultParetoSelection},
+		{name: "case knee", value: "knee", expectedResult: ParetoSelection{Policy: ParetoKnee}},
+		{
+			name:           "case weights",
+			value:          "weights: acceptance=1, communication=0.5",
+			expectedResult: ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjAcceptance: 1, ObjCommunication: 0.5}},
+		},
+		{name: "case no equal sign", value: "weights:acceptance", expectedErr: true},
+		{name: "case weight not float", value: "weights:acceptance=high", expectedErr: true},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult, err := ParseParetoSelection(testCase.value)
+		if testCase.expectedErr {
+			assert.NotNil(t, err, fmt.Sprintf("%s: should return error", testCase.name))
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestValidateParetoSelection(t *testing.T) {
+	objNames := []string{ObjAcceptance, ObjComputation, ObjCommunication}
+	testCases := []struct {
+		name        string
+		selection   ParetoSelection
+		expectedLen int
+	}{
+		{name: "case knee", selection: ParetoSelection{Policy: ParetoKnee}, expectedLen: 0},
+		{name: "case knee with weights", selection: ParetoSelection{Policy: ParetoKnee, Weights: map[string]float64{ObjAcceptance: 1}}, expectedLen: 1},
+		{name: "case weights", selection: ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjAcceptance: 1, ObjComputation: 0}}, expectedLen: 0},
+		{name: "case cost not configured", selection: ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjCost: 1}}, expectedLen: 1},
+		{name: "case negative weight", selection: ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjAcceptance: 2, ObjComputation: -1}}, expectedLen: 1},
+		{name: "case all zero", selection: ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjAcceptance: 0}}, expectedLen: 1},
+		{name: "case unknown policy", selection: ParetoSelection{Policy: "best"}, expectedLen: 1},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		errs := ValidateParetoSelection(testCase.selection, objNames)
+		assert.Len(t, errs, testCase.expectedLen, fmt.Sprintf("%s: errors are %v", testCase.name, errs))
+	}
+}
+
+func TestSelectFromFront(t *testing.T) {
+	objNames := []string{ObjAcceptance, ObjComputation, ObjCommunication}
+	front := []ParetoPoint{
+		{Objectives: map[string]float64{ObjAcceptance: 1, ObjComputation: 100, ObjCommunication: 100}},
+		{Objectives: map[string]float64{ObjAcceptance: 0.9, ObjComputation: 20, ObjCommunication: 30}},
+		{Objectives: map[string]float64{ObjAcceptance: 0.5, ObjComputation: 10, ObjCommunication: 10}},
+	}
+
+	testCases := []struct {
+		name           string
+		selection      ParetoSelection
+		expectedResult int
+	}{
+		{name: "case knee", selection: ParetoSelection{Policy: ParetoKnee}, expectedResult: 1},
+		{name: "case only acceptance", selection: ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjAcceptance: 1}}, expectedResult: 0},
+		{name: "case only communication", selection: ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjCommunication: 1}}, expectedResult: 2},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := SelectFromFront(front, testCase.selection, objNames)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+	assert.Equal(t, -1, SelectFromFront(nil, DefaultParetoSelection, objNames))
+
+	// the normalized objectives (computation, communication) are (0, 1), (1, 0), (0.02, 0.45), and (0.3, 0.3). The last one is the nearest to the ideal point, but the knee is the third one, farthest from the line through the extreme points.
+	kneeFront := []ParetoPoint{
+		{Objectives: map[string]float64{ObjAcceptance: 1, ObjComputation: 10, ObjCommunication: 110}},
+		{Objectives: map[string]float64{ObjAcceptance: 1, ObjComputation: 110, ObjCommunication: 10}},
+		{Objectives: map[string]float64{ObjAcceptance: 1, ObjComputation: 12, ObjCommunication: 55}},
+		{Objectives: map[string]float64{ObjAcceptance: 1, ObjComputation: 40, ObjCommunication: 40}},
+	}
+	assert.Equal(t, 2, SelectFromFront(kneeFront, ParetoSelection{Policy: ParetoKnee}, objNames))
+	assert.Equal(t, 0, SelectFromFront(kneeFront[:1], ParetoSelection{Policy: ParetoKnee}, objNames))
+}
+
+func TestInnerSolveLinearEquations(t *testing.T) {
+	testCases := []struct {
+		name           string
+		matrix         [][]float64
+		rhs            []float64
+		expectedResult []float64
+		expectedOk     bool
+	}{
+		{name: "case 2 dimensions", matrix: [][]float64{{0, 1}, {1, 0}}, rhs: []float64{1, 1}, expectedResult: []float64{1, 1}, expectedOk: true},
+		{name: "case 3 dimensions", matrix: [][]float64{{0, 0.5, 1}, {1, 0, 0.5}, {0.5, 1, 0}}, rhs: []float64{1, 1, 1}, expectedResult: []float64{2.0 / 3, 2.0 / 3, 2.0 / 3}, expectedOk: true},
+		{name: "case singular", matrix: [][]float64{{0, 1, 1}, {1, 0, 0}, {1, 0, 0}}, rhs: []float64{1, 1, 1}, expectedOk: false},
+		{name: "case empty", matrix: nil, rhs: nil, expectedOk: false},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult, actualOk := solveLinearEquations(testCase.matrix, testCase.rhs)
+		assert.Equal(t, testCase.expectedOk, actualOk, fmt.Sprintf("%s: ok is not expected", testCase.name))
+		assert.InDeltaSlice(t, testCase.expectedResult, actualResult, floatDelta, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestNsga2Schedule(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	nsga2 := NewNsga2(20, 50, 0.7, 0.2, 20, 100, ParetoSelection{})
+	assert.Equal(t, DefaultParetoSelection, nsga2.Selection)
+
+	solution, err := nsga2.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err, "Schedule should not return error")
+	t.Log(models.JsonString(nsga2.Front))
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution should be acceptable")
+
+	front, selected := nsga2.ParetoFront()
+	assert.NotEmpty(t, front, "the Pareto front should not be empty")
+	assert.Equal(t, asmodel.SolutionCopy(front[selected].Solution), solution, "the output should be the selected solution in the front")
+	for i := range front {
+		for j := range front {
+			if i == j {
+				continue
+			}
+			assert.False(t, dominates(nsga2.evaluate(clouds, apps, front[i].Solution).objectives, nsga2.evaluate(clouds, apps, front[j].Solution).objectives), fmt.Sprintf("solution %d in the front dominates solution %d", i, j))
+		}
+	}
+}
+
+func TestNsga2Deadline(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	params := AlgoParams{
+		ExpAppCompuTimeOneCpu: DefaultExpAppCompuTimeOneCpu,
+		Ga:                    GaParams{ChromosomesCount: 20, IterationCount: 1000, CrossoverProbability: 0.7, MutationProbability: 0.2, StopNoUpdateIteration: 5},
+		Seed:                  20231017,
+		Ctx:                   cancelledCtx,
+	}
+
+	algo, err := NewAlgorithm(Nsga2Name, params)
+	assert.Nil(t, err)
+	nsga2 := algo.(*Nsga2)
+
+	solution, err := nsga2.Schedule(clouds, apps, appsOrder)
+	assert.Nil(t, err, "Schedule should not return error")
+	assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution picked from the current front should be acceptable")
+	assert.Equal(t, cancelledCtx, nsga2.ops.Ctx, "the operators of Mcssga should use the context")
+	front, _ := nsga2.ParetoFront()
+	assert.NotEmpty(t, front, "the Pareto front of the initial population should not be empty")
+	// no iteration is run, otherwise the algorithm only stops when the front is not updated for StopNoUpdateIteration iterations.
+	assert.Equal(t, 0, nsga2.CurNoUpdateIteration, "the algorithm should stop before iteration 1")
+}
+
+func TestNsga2ScheduleInvalidSelection(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	nsga2 := NewNsga2(20, 50, 0.7, 0.2, 20, 100, ParetoSelection{Policy: ParetoWeights, Weights: map[string]float64{ObjCost: 1}})
+	_, err := nsga2.Schedule(clouds, apps, appsOrder)
+	assert.NotNil(t, err, "cost is not an objective without CostFunc")
+}
@@ -1,6 +1,10 @@
 package algorithms
 
-import asmodel "emcontroller/auto-schedule/model"
+import (
+	"sort"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
 
 // With a solution, Find out the applications scheduled on this cloud
 func findAppsOneCloud(cloud asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) map[string]asmodel.Application {
@@ -77,3 +81,55 @@ func calcNeededRes(apps map[string]asmodel.Application, appNames []string, minCp
 	}
 	return neededRes
 }
+
+// Group the applications into units, i.e., one application or all replicas of an application, for the algorithms deciding the units one by one (e.g., Exact and Greedy).
+// Every unit is after the units of its dependencies, and otherwise, a unit is earlier if its first application is "before" that of another unit, or if it has a smaller name when neither is "before" the other. Also output the index of the unit of every application.
+func dependencyFirstUnits(apps map[string]asmodel.Application, before func(a, b asmodel.Application) bool) ([][]string, map[string]int) {
+	// the key of a unit is the original application name
+	unitKeyOf := make(map[string]string)
+	unitApps := make(map[string][]string)
+	for _, appName := range sortedAppNames(apps) {
+		key := appName
+		if len(apps[appName].ReplicaOf) != 0 {
+			key = apps[appName].ReplicaOf
+		}
+		unitKeyOf[appName] = key
+		unitApps[key] = append(unitApps[key], appName)
+	}
+
+	keys := make([]string, 0, len(unitApps))
+	for key := range unitApps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return before(apps[unitApps[keys[i]][0]], apps[unitApps[keys[j]][0]])
+	})
+
+	// depth-first search to put the dependencies first. The dependencies among the applications are validated to have no cycles.
+	var units [][]string
+	unitIdxOf := make(map[string]int)
+	visited := make(map[string]struct{})
+	var visit func(key string)
+	visit = func(key string) {
+		if _, exist := visited[key]; exist {
+			return
+		}
+		visited[key] = struct{}{}
+		for _, appName := range unitApps[key] {
+			for _, dep := range apps[appName].Dependencies {
+				if depKey, exist := unitKeyOf[dep.AppName]; exist { // the running dependencies are not scheduled
+					visit(depKey)
+				}
+			}
+		}
+		for _, appName := range unitApps[key] {
+			unitIdxOf[appName] = len(units)
+		}
+		units = append(units, unitApps[key])
+	}
+	for _, key := range keys {
+		visit(key)
+	}
+	return units, unitIdxOf
+}
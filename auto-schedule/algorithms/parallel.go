@@ -0,0 +1,79 @@
+package algorithms
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+// GaWorkers is the number of goroutines that the genetic algorithms use to initialize, evaluate, and operate the chromosomes in parallel. Not more than 0 means runtime.GOMAXPROCS(0), i.e., all CPUs. It can be set by "GaWorkers" in app.conf.
+var GaWorkers int = 0
+
+// the number of workers actually used
+func gaWorkerCount() int {
+	if GaWorkers > 0 {
+		return GaWorkers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// parallelFor runs fn(0), fn(1), ..., fn(n-1) on a pool of gaWorkerCount() workers and waits for all of them.
+// fn(i) should only write its own results, e.g., the i-th element of a slice, and should not depend on the other calls, so that the results are the same no matter how the goroutines are scheduled.
+func parallelFor(n int, fn func(i int)) {
+	workers := gaWorkerCount()
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 { // no need to start goroutines
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	tasks := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+}
+
+// how many chromosomes of the initial population are always generated, even if the scheduling is stopped, because the binary tournament selection needs at least 2 chromosomes.
+const minInitChromosomes int = 2
+
+// genInitPopulation generates the initial population of a genetic algorithm in parallel, the i-th chromosome by gen with the i-th child random generator of rander.
+// Generating a big population can take a long time, so if ctx is done, the chromosomes not started yet are skipped except the first minInitChromosomes, and the population is smaller than count.
+func genInitPopulation(ctx context.Context, count int, rander *rand.Rand, gen func(rander *rand.Rand) asmodel.Solution) []asmodel.Solution {
+	population := make([]asmodel.Solution, count)
+	generated := make([]bool, count)
+	randers := childRanders(rander, count)
+	parallelFor(count, func(i int) {
+		if i >= minInitChromosomes && searchDone(ctx) {
+			return
+		}
+		population[i] = gen(randers[i])
+		generated[i] = true
+	})
+
+	var result []asmodel.Solution
+	for i := range population {
+		if generated[i] {
+			result = append(result, population[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+func TestInnerParallelFor(t *testing.T) {
+	oriWorkers := GaWorkers
+	defer func() { GaWorkers = oriWorkers }()
+
+	testCases := []struct {
+		name    string
+		workers int
+		n       int
+	}{
+		{name: "case default workers", workers: 0, n: 100},
+		{name: "case 1 worker", workers: 1, n: 100},
+		{name: "case more workers than tasks", workers: 16, n: 3},
+		{name: "case no task", workers: 4, n: 0},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		GaWorkers = testCase.workers
+		results := make([]int, testCase.n)
+		parallelFor(testCase.n, func(i int) {
+			results[i] = i * i
+		})
+		for j := 0; j < testCase.n; j++ {
+			assert.Equal(t, j*j, results[j], fmt.Sprintf("%s: result %d is not expected", testCase.name, j))
+		}
+	}
+}
+
+func TestInnerGenInitPopulation(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	gen := func(rander *rand.Rand) asmodel.Solution {
+		return RandomAcceptMostSolution(clouds, apps, appsOrder, rander)
+	}
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		expectedCount int
+	}{
+		{name: "case no deadline", ctx: nil, expectedCount: 10},
+		{name: "case not done", ctx: context.Background(), expectedCount: 10},
+		{name: "case cancelled", ctx: cancelledCtx, expectedCount: minInitChromosomes},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		population := genInitPopulation(testCase.ctx, 10, NewRander(20231017), gen)
+		assert.Len(t, population, testCase.expectedCount, fmt.Sprintf("%s: population size is not expected", testCase.name))
+		for j, soln := range population {
+			assert.True(t, Acceptable(clouds, apps, appsOrder, soln), fmt.Sprintf("%s: chromosome %d should be acceptable", testCase.name, j))
+		}
+	}
+
+	// the first chromosomes are the same as without the deadline
+	full := genInitPopulation(nil, 10, NewRander(20231017), gen)
+	stopped := genInitPopulation(cancelledCtx, 10, NewRander(20231017), gen)
+	assert.Equal(t, full[:minInitChromosomes], stopped)
+}
+
+// go test -run NONE -bench BenchmarkMcssgaWorkers -benchtime 3x
+func BenchmarkMcssgaWorkers(b *testing.B) {
+	oriWorkers := GaWorkers
+	defer func() { GaWorkers = oriWorkers }()
+
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	for name, workers := range map[string]int{"one worker": 1, "all CPUs": 0} {
+		GaWorkers = workers
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mcssga := NewMcssga(100, 100, 0.7, 0.02, 100, 100)
+				if _, err := mcssga.Schedule(clouds, apps, appsOrder); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
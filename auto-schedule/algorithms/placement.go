@@ -0,0 +1,104 @@
+package algorithms
+
+import (
+	"fmt"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+// Check whether an application can be scheduled to a cloud according to its allowed clouds, forbidden clouds, and cloud types.
+// These constraints only depend on the cloud itself, so the algorithms can avoid the clouds that are not allowed when they choose clouds randomly.
+func cloudAllowed(cloudName string, cloud asmodel.Cloud, app asmodel.Application) bool {
+	placement := app.Placement
+	if len(placement.AllowedClouds) != 0 && !containsString(placement.AllowedClouds, cloudName) {
+		return false
+	}
+	if containsString(placement.ForbiddenClouds, cloudName) {
+		return false
+	}
+	if len(placement.CloudTypes) != 0 && !containsString(placement.CloudTypes, cloud.Type) {
+		return false
+	}
+	return true
+}
+
+// get the clouds to which an application can be scheduled. If the application has no constraints about clouds, we return the input map directly without copying it.
+func candidateClouds(clouds map[string]asmodel.Cloud, app asmodel.Application) map[string]asmodel.Cloud {
+	if len(app.Placement.AllowedClouds) == 0 && len(app.Placement.ForbiddenClouds) == 0 && len(app.Placement.CloudTypes) == 0 {
+		return clouds
+	}
+	candidates := make(map[string]asmodel.Cloud)
+	for cloudName, cloud := range clouds {
+		if cloudAllowed(cloudName, cloud, app) {
+			candidates[cloudName] = cloud
+		}
+	}
+	return candidates
+}
+
+// Check whether a solution is acceptable in terms of the placement constraints of applications.
+func placementAcc(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution) bool {
+	appClouds := cloudsOfApps(apps, soln)
+	for appName, app := range apps {
+		appSoln := soln.AppsSolution[appName]
+		if !appSoln.Accepted {
+			continue
+		}
+		if !cloudAllowed(appSoln.TargetCloudName, clouds[appSoln.TargetCloudName], app) {
+			return false
+		}
+		if len(coLocationViolation(app, appSoln.TargetCloudName, appClouds)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// get the clouds where every application is accepted. The key of the output map is the original application name, because the constraints use the original names, and an application with multiple replicas can be on multiple clouds.
+func cloudsOfApps(apps map[string]asmodel.Application, soln asmodel.Solution) map[string]map[string]struct{} {
+	appClouds := make(map[string]map[string]struct{})
+	for appName, app := range apps {
+		appSoln := soln.AppsSolution[appName]
+		if !appSoln.Accepted {
+			continue
+		}
+		originalName := app.Name
+		if len(app.ReplicaOf) != 0 {
+			originalName = app.ReplicaOf
+		}
+		if _, exist := appClouds[originalName]; !exist {
+			appClouds[originalName] = make(map[string]struct{})
+		}
+		appClouds[originalName][appSoln.TargetCloudName] = struct{}{}
+	}
+	return appClouds
+}
+
+// Check whether an application on a cloud meets its co-location and anti-co-location constraints. If not, return the description of the violated constraint, otherwise return an empty string.
+// An application that is not accepted does not constrain others.
+func coLocationViolation(app asmodel.Application, cloudName string, appClouds map[string]map[string]struct{}) string {
+	for _, otherName := range app.Placement.CoLocateWith {
+		otherClouds, accepted := appClouds[otherName]
+		if !accepted {
+			continue
+		}
+		if _, exist := otherClouds[cloudName]; !exist {
+			return fmt.Sprintf("it should be on the same cloud as [%s]", otherName)
+		}
+	}
+	for _, otherName := range app.Placement.AntiCoLocateWith {
+		if _, exist := appClouds[otherName][cloudName]; exist {
+			return fmt.Sprintf("it cannot be on the same cloud as [%s]", otherName)
+		}
+	}
+	return ""
+}
+
+func containsString(slice []string, target string) bool {
+	for _, s := range slice {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func TestInnerCloudAllowed(t *testing.T) {
+	cloud := asmodel.Cloud{Name: "pve1", Type: models.ProxmoxIaas}
+
+	testCases := []struct {
+		name           string
+		placement      models.Placement
+		expectedResult bool
+	}{
+		{
+			name:           "case no constraint",
+			placement:      models.Placement{},
+			expectedResult: true,
+		},
+		{
+			name:           "case allowed",
+			placement:      models.Placement{AllowedClouds: []string{"pve1", "pve2"}},
+			expectedResult: true,
+		},
+		{
+			name:           "case not in allowed",
+			placement:      models.Placement{AllowedClouds: []string{"pve2"}},
+			expectedResult: false,
+		},
+		{
+			name:           "case forbidden",
+			placement:      models.Placement{ForbiddenClouds: []string{"pve1"}},
+			expectedResult: false,
+		},
+		{
+			name:           "case type required",
+			placement:      models.Placement{CloudTypes: []string{models.ProxmoxIaas}},
+			expectedResult: true,
+		},
+		{
+			name:           "case type not required",
+			placement:      models.Placement{CloudTypes: []string{models.OpenstackIaas}},
+			expectedResult: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		app := asmodel.Application{Name: "app1", Placement: testCase.placement}
+		actualResult := cloudAllowed(cloud.Name, cloud, app)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerPlacementAcc(t *testing.T) {
+	clouds := map[string]asmodel.Cloud{
+		"pve1":  {Name: "pve1", Type: models.ProxmoxIaas},
+		"pve2":  {Name: "pve2", Type: models.ProxmoxIaas},
+		"open1": {Name: "open1", Type: models.OpenstackIaas},
+	}
+
+	testCases := []struct {
+		name           string
+		apps           map[string]asmodel.Application
+		soln           asmodel.Solution
+		expectedResult bool
+	}{
+		{
+			name: "case on-premises data stays on proxmox",
+			apps: map[string]asmodel.Application{
+				"db": {Name: "db", Placement: models.Placement{CloudTypes: []string{models.ProxmoxIaas}}},
+			},
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"db": {Accepted: true, TargetCloudName: "pve2"},
+			}},
+			expectedResult: true,
+		},
+		{
+			name: "case on-premises data on openstack",
+			apps: map[string]asmodel.Application{
+				"db": {Name: "db", Placement: models.Placement{CloudTypes: []string{models.ProxmoxIaas}}},
+			},
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"db": {Accepted: true, TargetCloudName: "open1"},
+			}},
+			expectedResult: false,
+		},
+		{
+			name: "case rejected application is not checked",
+			apps: map[string]asmodel.Application{
+				"db": {Name: "db", Placement: models.Placement{ForbiddenClouds: []string{"open1"}}},
+			},
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"db": {Accepted: false, TargetCloudName: "open1"},
+			}},
+			expectedResult: true,
+		},
+		{
+			name: "case co-located",
+			apps: map[string]asmodel.Application{
+				"cache": {Name: "cache", Placement: models.Placement{CoLocateWith: []string{"db"}}},
+				"db":    {Name: "db"},
+			},
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"cache": {Accepted: true, TargetCloudName: "pve1"},
+				"db":    {Accepted: true, TargetCloudName: "pve1"},
+			}},
+			expectedResult: true,
+		},
+		{
+			name: "case not co-located",
+			apps: map[string]asmodel.Application{
+				"cache": {Name: "cache", Placement: models.Placement{CoLocateWith: []string{"db"}}},
+				"db":    {Name: "db"},
+			},
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"cache": {Accepted: true, TargetCloudName: "pve1"},
+				"db":    {Accepted: true, TargetCloudName: "pve2"},
+			}},
+			expectedResult: false,
+		},
+		{
+			name: "case co-located application rejected",
+			apps: map[string]asmodel.Application{
+				"cache": {Name: "cache", Placement: models.Placement{CoLocateWith: []string{"db"}}},
+				"db":    {Name: "db"},
+			},
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"cache": {Accepted: true, TargetCloudName: "pve1"},
+				"db":    {Accepted: false},
+			}},
+			expectedResult: true,
+		},
+		{
+			name: "case co-located with a replica",
+			apps: asmodel.ExpandReplicas(map[string]asmodel.Application{
+				"cache": {Name: "cache", Placement: models.Placement{CoLocateWith: []string{"db"}}},
+				"db":    {Name: "db", Replicas: 2},
+			}),
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"cache": {Accepted: true, TargetCloudName: "pve2"},
+				"db#0":  {Accepted: true, TargetCloudName: "pve1"},
+				"db#1":  {Accepted: true, TargetCloudName: "pve2"},
+			}},
+			expectedResult: true,
+		},
+		{
+			name: "case anti-co-located with a replica",
+			apps: asmodel.ExpandReplicas(map[string]asmodel.Application{
+				"batch": {Name: "batch", Placement: models.Placement{AntiCoLocateWith: []string{"db"}}},
+				"db":    {Name: "db", Replicas: 2},
+			}),
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"batch": {Accepted: true, TargetCloudName: "pve2"},
+				"db#0":  {Accepted: true, TargetCloudName: "pve1"},
+				"db#1":  {Accepted: true, TargetCloudName: "pve2"},
+			}},
+			expectedResult: false,
+		},
+		{
+			name: "case anti-co-located",
+			apps: map[string]asmodel.Application{
+				"batch": {Name: "batch", Placement: models.Placement{AntiCoLocateWith: []string{"db"}}},
+				"db":    {Name: "db"},
+			},
+			soln: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"batch": {Accepted: true, TargetCloudName: "open1"},
+				"db":    {Accepted: true, TargetCloudName: "pve1"},
+			}},
+			expectedResult: true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := placementAcc(clouds, testCase.apps, testCase.soln)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerRandomAcceptMostSolutionPlacement(t *testing.T) {
+	clouds, _, _ := replicaCloudsAppsForTest()
+	cloud1 := clouds["cloud1"]
+	cloud1.Type = models.ProxmoxIaas
+	clouds["cloud1"] = cloud1
+	cloud2 := clouds["cloud2"]
+	cloud2.Type = models.OpenstackIaas
+	clouds["cloud2"] = cloud2
+
+	res := asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 1, Memory: 512, Storage: 5}}
+	apps := map[string]asmodel.Application{
+		"db":    {Name: "db", Priority: 8, Resources: res, Placement: models.Placement{CloudTypes: []string{models.ProxmoxIaas}}},
+		"cache": {Name: "cache", Priority: 8, Resources: res, Placement: models.Placement{CoLocateWith: []string{"db"}}},
+		"batch": {Name: "batch", Priority: 5, Resources: res, Placement: models.Placement{AntiCoLocateWith: []string{"db"}}},
+		"web":   {Name: "web", Priority: 5, Resources: res, Placement: models.Placement{ForbiddenClouds: []string{"cloud2"}}},
+	}
+	appsOrder := []string{"batch", "cache", "db", "web"}
+
+	for i := 0; i < 20; i++ {
+		t.Logf("test: %d", i)
+		solution := RandomAcceptMostSolution(clouds, apps, appsOrder, NewRander(NewSeed()))
+		t.Log(models.JsonString(solution))
+
+		assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution should be acceptable")
+		dbSoln := solution.AppsSolution["db"]
+		if dbSoln.Accepted {
+			assert.Equal(t, "cloud1", dbSoln.TargetCloudName, "db should be on the proxmox cloud")
+		}
+		if solution.AppsSolution["web"].Accepted {
+			assert.Equal(t, "cloud1", solution.AppsSolution["web"].TargetCloudName, "web cannot be on the forbidden cloud")
+		}
+		if dbSoln.Accepted && solution.AppsSolution["cache"].Accepted {
+			assert.Equal(t, dbSoln.TargetCloudName, solution.AppsSolution["cache"].TargetCloudName, "cache should be with db")
+		}
+		if dbSoln.Accepted && solution.AppsSolution["batch"].Accepted {
+			assert.NotEqual(t, dbSoln.TargetCloudName, solution.AppsSolution["batch"].TargetCloudName, "batch cannot be with db")
+		}
+	}
+}
+
+func TestInnerGeneMutateNoCloud(t *testing.T) {
+	mcssga := NewMcssga(10, 10, 0.5, 0.5, 10, 100)
+	for i := 0; i < 10; i++ {
+		mutated := mcssga.geneMutate(map[string]asmodel.Cloud{}, asmodel.SingleAppSolution{Accepted: false}, NewRander(NewSeed()))
+		assert.False(t, mutated.Accepted, "without any cloud to pick, the gene can only be rejected")
+	}
+}
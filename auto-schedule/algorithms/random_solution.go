@@ -1,13 +1,14 @@
 package algorithms
 
 import (
-	"github.com/KeepTheBeats/routing-algorithms/random"
+	"math/rand"
+	"sort"
 
 	asmodel "emcontroller/auto-schedule/model"
 )
 
-// Generate a solution randomly, doing the best to accept more applications.
-func RandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) asmodel.Solution {
+// Generate a solution randomly, doing the best to accept more applications. All randomness comes from rander.
+func RandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, rander *rand.Rand) asmodel.Solution {
 
 	// initialize an all-reject solution with all applications rejected.
 	var solution asmodel.Solution = asmodel.GenEmptySoln()
@@ -22,14 +23,24 @@ func RandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[string]a
 	// traverse apps in random order
 	for len(untriedApps) > 0 {
 		// randomly choose an application, trying to deploy it to a cloud.
-		pickedAppName, _ := randomAppMapPick(untriedApps)
+		pickedAppName, pickedApp := randomAppMapPick(untriedApps, rander)
 
-		// avoiding changing the original cloud map
-		untriedClouds := asmodel.CloudMapCopy(clouds)
+		// The replicas of an application are accepted or rejected together, so we try to place all of them at once.
+		if len(pickedApp.ReplicaOf) != 0 {
+			replicaNames := asmodel.ReplicaGroups(apps)[pickedApp.ReplicaOf]
+			solution = randomAcceptReplicas(clouds, apps, appsOrder, solution, replicaNames, RefineSoln, rander)
+			for _, replicaName := range replicaNames {
+				delete(untriedApps, replicaName)
+			}
+			continue
+		}
+
+		// avoiding changing the original cloud map. We only try the clouds allowed by the placement constraints.
+		untriedClouds := asmodel.CloudMapCopy(candidateClouds(clouds, pickedApp))
 		// traverse clouds in random order
 		for len(untriedClouds) > 0 {
 			// randomly choose a cloud, trying to deploy the application to it.
-			pickedCloudName, _ := randomCloudMapPick(untriedClouds)
+			pickedCloudName, _ := randomCloudMapPick(untriedClouds, rander)
 			solution.AppsSolution[pickedAppName] = asmodel.SingleAppSolution{
 				Accepted:        true,
 				TargetCloudName: pickedCloudName,
@@ -58,26 +69,100 @@ func RandomAcceptMostSolution(clouds map[string]asmodel.Cloud, apps map[string]a
 	return solution
 }
 
-// randomly pick an item from a cloud map
-func randomCloudMapPick(m map[string]asmodel.Cloud) (string, asmodel.Cloud) {
-	k := random.RandomInt(0, len(m)-1)
-	for name, cloud := range m {
-		if k == 0 {
-			return name, cloud
+// Try to accept all replicas of an application in the solution, spreading them across random clouds. refine is the function to refine and check a solution, e.g., RefineSoln.
+// Every attempt randomly chooses how many clouds to use (at least MinClouds) and which clouds, and then puts the replicas on these clouds in turn. We try at most len(clouds) times. If no attempt is acceptable, the replicas stay rejected.
+func randomAcceptReplicas(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, solution asmodel.Solution, replicaNames []string, refine func(map[string]asmodel.Cloud, map[string]asmodel.Application, []string, asmodel.Solution) (asmodel.Solution, bool), rander *rand.Rand) asmodel.Solution {
+	if len(replicaNames) == 0 {
+		return solution
+	}
+
+	leastClouds := apps[replicaNames[0]].MinClouds
+	if leastClouds < 1 {
+		leastClouds = 1
+	}
+	// the replicas have the same placement constraints
+	allowedClouds := candidateClouds(clouds, apps[replicaNames[0]])
+	mostClouds := len(replicaNames)
+	if mostClouds > len(allowedClouds) {
+		mostClouds = len(allowedClouds)
+	}
+	if leastClouds > mostClouds { // not enough clouds for the replicas
+		return solution
+	}
+
+	for attempt := 0; attempt < len(allowedClouds); attempt++ {
+		cloudNames := shuffledCloudNames(allowedClouds, rander)
+		cloudsToUse := randomInt(rander, leastClouds, mostClouds)
+
+		triedSoln := asmodel.SolutionCopy(solution)
+		for i, replicaName := range replicaNames {
+			triedSoln.AppsSolution[replicaName] = asmodel.SingleAppSolution{
+				Accepted:        true,
+				TargetCloudName: cloudNames[i%cloudsToUse],
+			}
+		}
+
+		if refinedSoln, acceptable := refine(clouds, apps, appsOrder, triedSoln); acceptable {
+			return asmodel.SolutionCopy(refinedSoln)
 		}
-		k--
 	}
-	panic("Unexpected condition.")
+
+	return solution
 }
 
-// randomly pick an item from an application map
-func randomAppMapPick(m map[string]asmodel.Application) (string, asmodel.Application) {
-	k := random.RandomInt(0, len(m)-1)
-	for name, app := range m {
-		if k == 0 {
-			return name, app
+// After the genes are generated or mutated randomly, the replicas of an application may be partly accepted, which is never acceptable.
+// This function makes all replicas of an application follow the first replica: if it is accepted, the rejected replicas are accepted on random clouds; if it is rejected, all replicas are rejected.
+// NOTE: This function changes the input solution.
+func alignReplicaGenes(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, soln asmodel.Solution, rander *rand.Rand) {
+	replicaGroups := asmodel.ReplicaGroups(apps)
+	groupNames := make([]string, 0, len(replicaGroups))
+	for groupName := range replicaGroups {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames) // the random clouds are picked in a fixed order of groups
+	for _, groupName := range groupNames {
+		replicaNames := replicaGroups[groupName]
+		leaderAccepted := soln.AppsSolution[replicaNames[0]].Accepted
+		for _, replicaName := range replicaNames[1:] {
+			if !leaderAccepted {
+				soln.AppsSolution[replicaName] = asmodel.SasCopy(asmodel.RejSoln)
+				continue
+			}
+			if !soln.AppsSolution[replicaName].Accepted {
+				allowedClouds := candidateClouds(clouds, apps[replicaName])
+				if len(allowedClouds) == 0 {
+					continue // it cannot be accepted, so the solution will not be acceptable
+				}
+				pickedCloudName, _ := randomCloudMapPick(allowedClouds, rander)
+				soln.AppsSolution[replicaName] = asmodel.SingleAppSolution{
+					Accepted:        true,
+					TargetCloudName: pickedCloudName,
+				}
+			}
 		}
-		k--
 	}
-	panic("Unexpected condition.")
+}
+
+// get the names of clouds in random order
+func shuffledCloudNames(clouds map[string]asmodel.Cloud, rander *rand.Rand) []string {
+	names := sortedCloudNames(clouds) // the order of a map is not fully random, so we sort the names first and shuffle them by ourselves
+	for i := len(names) - 1; i > 0; i-- {
+		j := randomInt(rander, 0, i)
+		names[i], names[j] = names[j], names[i]
+	}
+	return names
+}
+
+// randomly pick an item from a cloud map
+func randomCloudMapPick(m map[string]asmodel.Cloud, rander *rand.Rand) (string, asmodel.Cloud) {
+	names := sortedCloudNames(m) // pick from a fixed order, so that the same random number picks the same item
+	name := names[randomInt(rander, 0, len(names)-1)]
+	return name, m[name]
+}
+
+// randomly pick an item from an application map
+func randomAppMapPick(m map[string]asmodel.Application, rander *rand.Rand) (string, asmodel.Application) {
+	names := sortedAppNames(m) // pick from a fixed order, so that the same random number picks the same item
+	name := names[randomInt(rander, 0, len(names)-1)]
+	return name, m[name]
 }
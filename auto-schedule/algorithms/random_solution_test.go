@@ -1,9 +1,14 @@
 package algorithms
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
 )
 
 func TestInnerRandomCloudMapPick(t *testing.T) {
@@ -29,7 +34,7 @@ func TestInnerRandomCloudMapPick(t *testing.T) {
 	}
 
 	for len(testOrder) > 0 {
-		pickedKey, pickedValue := randomCloudMapPick(testOrder)
+		pickedKey, pickedValue := randomCloudMapPick(testOrder, NewRander(NewSeed()))
 		t.Log(pickedKey, pickedValue)
 		delete(testOrder, pickedKey)
 		t.Logf("The rest of the map: %v\n", testOrder)
@@ -61,7 +66,7 @@ func TestInnerRandomAppMapPick(t *testing.T) {
 
 	for len(testOrder) > 0 {
 		func() {
-			pickedKey, pickedApp := randomAppMapPick(testOrder)
+			pickedKey, pickedApp := randomAppMapPick(testOrder, NewRander(NewSeed()))
 			defer delete(testOrder, pickedKey)
 
 			t.Log(pickedKey, pickedApp)
@@ -70,3 +75,120 @@ func TestInnerRandomAppMapPick(t *testing.T) {
 	}
 
 }
+
+// 2 clouds without the support of creating new VMs, each with 2 nodes, and a 3-replica application which should be spread across 2 clouds.
+func replicaCloudsAppsForTest() (map[string]asmodel.Cloud, map[string]asmodel.Application, []string) {
+	genNode := func(name string) asmodel.K8sNode {
+		return asmodel.K8sNode{
+			Name:              name,
+			ResidualResources: asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 100},
+		}
+	}
+	clouds := map[string]asmodel.Cloud{
+		"cloud1": {
+			Name:     "cloud1",
+			NetState: map[string]models.NetworkState{"cloud1": {Rtt: 0.5}, "cloud2": {Rtt: 5}},
+			K8sNodes: []asmodel.K8sNode{genNode("node1-1"), genNode("node1-2")},
+		},
+		"cloud2": {
+			Name:     "cloud2",
+			NetState: map[string]models.NetworkState{"cloud1": {Rtt: 5}, "cloud2": {Rtt: 0.5}},
+			K8sNodes: []asmodel.K8sNode{genNode("node2-1"), genNode("node2-2")},
+		},
+	}
+
+	res := asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 1, Memory: 512, Storage: 5}}
+	apps := asmodel.ExpandReplicas(map[string]asmodel.Application{
+		"frontend": {Name: "frontend", Priority: 5, Resources: res, Replicas: 3, MinClouds: 2},
+		"backend":  {Name: "backend", Priority: 8, Resources: res},
+	})
+	appsOrder := GenerateAppsOrder(apps)
+	sort.Strings(appsOrder)
+
+	return clouds, apps, appsOrder
+}
+
+func TestInnerRandomAcceptReplicas(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	replicaNames := asmodel.ReplicaGroups(apps)["frontend"]
+
+	for i := 0; i < 20; i++ {
+		t.Logf("test: %d", i)
+		solution := RandomAcceptMostSolution(clouds, apps, appsOrder, NewRander(NewSeed()))
+		t.Log(models.JsonString(solution))
+
+		assert.True(t, Acceptable(clouds, apps, appsOrder, solution), "the solution should be acceptable")
+		assert.True(t, solution.AppsSolution["backend"].Accepted, "backend should be accepted")
+
+		usedNodes := make(map[string]struct{})
+		usedClouds := make(map[string]struct{})
+		for _, replicaName := range replicaNames {
+			replicaSoln := solution.AppsSolution[replicaName]
+			assert.True(t, replicaSoln.Accepted, fmt.Sprintf("%s should be accepted", replicaName))
+			usedNodes[replicaSoln.K8sNodeName] = struct{}{}
+			usedClouds[replicaSoln.TargetCloudName] = struct{}{}
+		}
+		assert.Equal(t, len(replicaNames), len(usedNodes), "every replica should be on a different node")
+		assert.GreaterOrEqual(t, len(usedClouds), 2, "the replicas should be across at least 2 clouds")
+	}
+}
+
+func TestInnerRandomAcceptReplicasNotEnoughClouds(t *testing.T) {
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	delete(clouds, "cloud2")
+
+	solution := RandomAcceptMostSolution(clouds, apps, appsOrder, NewRander(NewSeed()))
+	t.Log(models.JsonString(solution))
+	for _, replicaName := range asmodel.ReplicaGroups(apps)["frontend"] {
+		assert.False(t, solution.AppsSolution[replicaName].Accepted, fmt.Sprintf("%s should be rejected, because MinClouds is 2 but there is only 1 cloud", replicaName))
+	}
+	assert.True(t, solution.AppsSolution["backend"].Accepted, "backend should be accepted")
+}
+
+func TestInnerAlignReplicaGenes(t *testing.T) {
+	clouds, apps, _ := replicaCloudsAppsForTest()
+
+	testCases := []struct {
+		name             string
+		soln             asmodel.Solution
+		expectedAccepted bool
+	}{
+		{
+			name: "case leader accepted",
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"backend":    {Accepted: true, TargetCloudName: "cloud1"},
+					"frontend#0": {Accepted: true, TargetCloudName: "cloud1"},
+					"frontend#1": {Accepted: false},
+					"frontend#2": {Accepted: true, TargetCloudName: "cloud2"},
+				},
+			},
+			expectedAccepted: true,
+		},
+		{
+			name: "case leader rejected",
+			soln: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"backend":    {Accepted: true, TargetCloudName: "cloud1"},
+					"frontend#0": {Accepted: false},
+					"frontend#1": {Accepted: true, TargetCloudName: "cloud1"},
+					"frontend#2": {Accepted: true, TargetCloudName: "cloud2"},
+				},
+			},
+			expectedAccepted: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		alignReplicaGenes(clouds, apps, testCase.soln, NewRander(NewSeed()))
+		for _, replicaName := range []string{"frontend#0", "frontend#1", "frontend#2"} {
+			replicaSoln := testCase.soln.AppsSolution[replicaName]
+			assert.Equal(t, testCase.expectedAccepted, replicaSoln.Accepted, fmt.Sprintf("%s: %s Accepted is not expected", testCase.name, replicaName))
+			if replicaSoln.Accepted {
+				assert.Contains(t, clouds, replicaSoln.TargetCloudName, fmt.Sprintf("%s: %s should have a target cloud", testCase.name, replicaName))
+			}
+		}
+		assert.True(t, testCase.soln.AppsSolution["backend"].Accepted, fmt.Sprintf("%s: backend should not be changed", testCase.name))
+	}
+}
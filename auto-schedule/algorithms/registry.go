@@ -0,0 +1,117 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownAlgorithm is returned when an algorithm is not registered.
+var ErrUnknownAlgorithm = errors.New("unknown scheduling algorithm")
+
+// AlgoParams includes all parameters that the factories can use to create algorithm instances. Every algorithm only uses the parameters it needs.
+type AlgoParams struct {
+	ExpAppCompuTimeOneCpu float64         // the expected computation time of every application by one CPU core.  unit millisecond (ms)
+	Ga                    GaParams        // the parameters of genetic algorithms
+	Pareto                ParetoSelection // how multi-objective algorithms pick the solution from the Pareto front
+	MaxHourlyCost         float64         // the budget per hour of the VMs to create, not more than 0 means no limit
+	Seed                  int64           // the seed of the random generator, with which the same input always gives the same solution, see NewSeed
+	TimeLimit             time.Duration   // how long the algorithms searching until an optimal solution (e.g., Exact) can run, not more than 0 means the default of the algorithm
+	Ctx                   context.Context // with a deadline or cancellation, the anytime algorithms (Mcssga, Amaga, Ampga, Diktyoga, Nsga2, and Exact) stop when it is done and return the best solution found so far. nil means no deadline.
+}
+
+// AlgoFactory creates an instance of an algorithm. Algorithms like genetic algorithms have states, so every scheduling needs a new instance.
+type AlgoFactory func(params AlgoParams) SchedulingAlgorithm
+
+// ParamSchema describes a tunable parameter of an algorithm.
+type ParamSchema struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"` // "int" or "float"
+	Description string      `json:"description"`
+	Default     interface{} `json:"default"`
+}
+
+// AlgoInfo is the information of a registered algorithm shown to users.
+type AlgoInfo struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Params      []ParamSchema `json:"params"`
+}
+
+// AlgoRegistration is what an algorithm needs to register itself.
+type AlgoRegistration struct {
+	Name        string
+	Description string
+	Params      func() []ParamSchema // a function, because the default values (e.g., DefaultGaParams) can be changed by app.conf after registration. It can be nil if the algorithm has no parameters.
+	Factory     AlgoFactory
+}
+
+var (
+	algoRegistry   map[string]AlgoRegistration = make(map[string]AlgoRegistration)
+	algoRegistryMu sync.RWMutex
+)
+
+// RegisterAlgorithm makes an algorithm available by its name. It is usually called in the init function of the file implementing the algorithm.
+// The same as sql.Register, it panics if the name is empty or already registered, or if the factory is nil, because these are bugs in the code.
+func RegisterAlgorithm(reg AlgoRegistration) {
+	algoRegistryMu.Lock()
+	defer algoRegistryMu.Unlock()
+	if len(reg.Name) == 0 {
+		panic("algorithms: RegisterAlgorithm with an empty name")
+	}
+	if reg.Factory == nil {
+		panic(fmt.Sprintf("algorithms: RegisterAlgorithm [%s] with a nil factory", reg.Name))
+	}
+	if _, exist := algoRegistry[reg.Name]; exist {
+		panic(fmt.Sprintf("algorithms: RegisterAlgorithm called twice for [%s]", reg.Name))
+	}
+	algoRegistry[reg.Name] = reg
+}
+
+// NewAlgorithm creates an instance of the algorithm with the name. If the name is not registered, the error wraps ErrUnknownAlgorithm.
+func NewAlgorithm(name string, params AlgoParams) (SchedulingAlgorithm, error) {
+	algoRegistryMu.RLock()
+	reg, exist := algoRegistry[name]
+	algoRegistryMu.RUnlock()
+	if !exist {
+		return nil, fmt.Errorf("%w [%s], the available ones are %v", ErrUnknownAlgorithm, name, AlgorithmNames())
+	}
+	return reg.Factory(params), nil
+}
+
+// AlgorithmNames returns the names of all registered algorithms in order.
+func AlgorithmNames() []string {
+	algoRegistryMu.RLock()
+	defer algoRegistryMu.RUnlock()
+	names := make([]string, 0, len(algoRegistry))
+	for name := range algoRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ListAlgorithms returns the information of all registered algorithms, ordered by name.
+func ListAlgorithms() []AlgoInfo {
+	algoRegistryMu.RLock()
+	defer algoRegistryMu.RUnlock()
+	infos := make([]AlgoInfo, 0, len(algoRegistry))
+	for _, reg := range algoRegistry {
+		info := AlgoInfo{
+			Name:        reg.Name,
+			Description: reg.Description,
+			Params:      []ParamSchema{},
+		}
+		if reg.Params != nil {
+			info.Params = reg.Params()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
@@ -0,0 +1,73 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+// an algorithm to test the registration, which rejects all applications
+type rejectAllForTest struct{}
+
+func (r *rejectAllForTest) Schedule(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string) (asmodel.Solution, error) {
+	return asmodel.GenEmptySoln(), nil
+}
+
+func TestBuiltInAlgorithms(t *testing.T) {
+	assert.Equal(t, []string{AmagaName, AmpgaName, AnnealingName, BERandName, CompRandName, DiktyogaName, ExactName, GreedyName, McssgaName, Nsga2Name}, AlgorithmNames())
+
+	for _, info := range ListAlgorithms() {
+		t.Logf("test: %s", info.Name)
+		assert.NotEmpty(t, info.Description, fmt.Sprintf("%s: description is empty", info.Name))
+		algo, err := NewAlgorithm(info.Name, AlgoParams{ExpAppCompuTimeOneCpu: DefaultExpAppCompuTimeOneCpu, Ga: DefaultGaParams})
+		assert.Nil(t, err)
+		assert.NotNil(t, algo)
+	}
+
+	var mcssgaInfo AlgoInfo
+	for _, info := range ListAlgorithms() {
+		if info.Name == McssgaName {
+			mcssgaInfo = info
+		}
+	}
+	assert.Len(t, mcssgaInfo.Params, 6)
+	assert.Equal(t, DefaultGaParams.ChromosomesCount, mcssgaInfo.Params[0].Default)
+}
+
+func TestNewAlgorithmUnknown(t *testing.T) {
+	algo, err := NewAlgorithm("NotExist", AlgoParams{})
+	assert.Nil(t, algo)
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	const name string = "RejectAllForTest"
+	defer func() {
+		algoRegistryMu.Lock()
+		delete(algoRegistry, name)
+		algoRegistryMu.Unlock()
+	}()
+
+	reg := AlgoRegistration{
+		Name:        name,
+		Description: "reject all applications",
+		Factory: func(params AlgoParams) SchedulingAlgorithm {
+			return &rejectAllForTest{}
+		},
+	}
+	RegisterAlgorithm(reg)
+	assert.Contains(t, AlgorithmNames(), name)
+
+	algo, err := NewAlgorithm(name, AlgoParams{})
+	assert.Nil(t, err)
+	assert.IsType(t, &rejectAllForTest{}, algo)
+
+	// invalid registrations
+	assert.Panics(t, func() { RegisterAlgorithm(reg) }, "duplicate name")
+	assert.Panics(t, func() { RegisterAlgorithm(AlgoRegistration{Factory: reg.Factory}) }, "empty name")
+	assert.Panics(t, func() { RegisterAlgorithm(AlgoRegistration{Name: "NilFactoryForTest"}) }, "nil factory")
+}
@@ -0,0 +1,83 @@
+package algorithms
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+/**
+NOTE:
+All randomness of the algorithms comes from a random generator (rander) created by the seed of the algorithm, so with the same seed and the same input, an algorithm gives the same solution. To make this true:
+1. a rand.Rand is not safe for concurrent use, so every task of parallelFor uses its own generator made by childRanders in the calling goroutine;
+2. the order of a map is random in golang, so we traverse maps in a sorted order wherever the random numbers or float sums depend on the order.
+*/
+
+// NewSeed generates a seed from the current time, for the scheduling without a seed set by users.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// NewRander creates a random generator with the seed.
+func NewRander(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// childRanders creates n random generators seeded by rander, one for every task of parallelFor, so that the random numbers used by every task do not depend on how the goroutines are scheduled.
+func childRanders(rander *rand.Rand, n int) []*rand.Rand {
+	randers := make([]*rand.Rand, n)
+	for i := 0; i < n; i++ {
+		randers[i] = NewRander(rander.Int63())
+	}
+	return randers
+}
+
+// generate int in [start,end], the same as random.RandomInt in "github.com/KeepTheBeats/routing-algorithms/random" but with the given generator
+func randomInt(rander *rand.Rand, start, end int) int {
+	return rander.Intn(end-start+1) + start
+}
+
+// generate float64 in [start,end), the same as random.RandomFloat64 in "github.com/KeepTheBeats/routing-algorithms/random" but with the given generator
+func randomFloat64(rander *rand.Rand, start, end float64) float64 {
+	return rander.Float64()*(end-start) + start
+}
+
+// pick m different indexes from [0, n), the same as random.RandomPickN in "github.com/KeepTheBeats/routing-algorithms/random" but with the given generator
+func randomPickN(rander *rand.Rand, n int, m int) []int {
+	if m > n {
+		return []int{}
+	}
+	indexes := make([]int, n)
+	for i := 0; i < len(indexes); i++ {
+		indexes[i] = i
+	}
+	var result []int
+	for i := 0; i < m; i++ {
+		picked := randomInt(rander, 0, len(indexes)-1)
+		result = append(result, indexes[picked])
+		indexes = append(indexes[:picked], indexes[picked+1:]...)
+	}
+	return result
+}
+
+// get the names of clouds in order
+func sortedCloudNames(clouds map[string]asmodel.Cloud) []string {
+	names := make([]string, 0, len(clouds))
+	for name := range clouds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// get the names of applications in order
+func sortedAppNames(apps map[string]asmodel.Application) []string {
+	names := make([]string, 0, len(apps))
+	for name := range apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,67 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+)
+
+func TestInnerRandomPickN(t *testing.T) {
+	testCases := []struct {
+		name        string
+		n           int
+		m           int
+		expectedLen int
+	}{
+		{name: "case pick 2 from 5", n: 5, m: 2, expectedLen: 2},
+		{name: "case pick all", n: 3, m: 3, expectedLen: 3},
+		{name: "case pick too many", n: 2, m: 3, expectedLen: 0},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		picked := randomPickN(NewRander(NewSeed()), testCase.n, testCase.m)
+		assert.Len(t, picked, testCase.expectedLen, fmt.Sprintf("%s: result is not expected", testCase.name))
+		seen := make(map[int]struct{})
+		for _, idx := range picked {
+			assert.True(t, idx >= 0 && idx < testCase.n, fmt.Sprintf("%s: index %d out of range", testCase.name, idx))
+			seen[idx] = struct{}{}
+		}
+		assert.Len(t, seen, len(picked), fmt.Sprintf("%s: indexes should be different", testCase.name))
+	}
+}
+
+func TestScheduleSameSeed(t *testing.T) {
+	oriWorkers := GaWorkers
+	defer func() { GaWorkers = oriWorkers }()
+
+	clouds, apps, appsOrder := replicaCloudsAppsForTest()
+	params := AlgoParams{
+		ExpAppCompuTimeOneCpu: DefaultExpAppCompuTimeOneCpu,
+		Ga:                    GaParams{ChromosomesCount: 10, IterationCount: 10, CrossoverProbability: 0.7, MutationProbability: 0.2, StopNoUpdateIteration: 10},
+		Pareto:                DefaultParetoSelection,
+		Seed:                  20231017,
+	}
+
+	for _, algoName := range []string{McssgaName, AmagaName, AmpgaName, DiktyogaName, Nsga2Name, BERandName, CompRandName} {
+		t.Logf("test: %s", algoName)
+		var solutions []asmodel.Solution
+		// the same seed should give the same solution, no matter how many workers run the operators.
+		for _, workers := range []int{1, 4, 4} {
+			GaWorkers = workers
+			algo, err := NewAlgorithm(algoName, params)
+			assert.Nil(t, err)
+			solution, err := algo.Schedule(clouds, apps, appsOrder)
+			if err != nil { // the completely random algorithm may get an unusable solution, which is also reproducible.
+				solution = asmodel.Solution{}
+			}
+			solutions = append(solutions, solution)
+		}
+		for i := 1; i < len(solutions); i++ {
+			assert.Equal(t, solutions[0], solutions[i], fmt.Sprintf("%s: run %d with the same seed gives a different solution", algoName, i))
+		}
+	}
+}
@@ -29,9 +29,9 @@ func allocateVms(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Applic
 	// If there is original VmsToCreate in the input solution, we ignore them, as this function will generate a new VM allocation scheme from zero.
 	solnWithVm.VmsToCreate = nil
 
-	// We should allocate VMs cloud by cloud.
-	for _, cloud := range clouds {
-		solnWithVmsThisCloud, allocType := allocateVmsOneCloud(cloud, apps, appsOrder, soln)
+	// We should allocate VMs cloud by cloud, in a fixed order, so that the VMs to create are always in the same order.
+	for _, cloudName := range sortedCloudNames(clouds) {
+		solnWithVmsThisCloud, allocType := allocateVmsOneCloud(clouds[cloudName], apps, appsOrder, soln)
 		if allocType == UnAcceptable { // if any cloud cannot accept the scheduled applications, this whole solution is not acceptable.
 			return asmodel.Solution{}, false
 		}
@@ -133,7 +133,7 @@ func resAccOneCloudSharedVm(cloud asmodel.Cloud, apps map[string]asmodel.Applica
 	cloudLeastResPct := cloud.Resources.LeastRemainPct()
 
 	if cloudLeastResPct > biggerVmResPct { // try 1
-		vmToCreate := cloud.GetSharedVmToCreate(biggerVmResPct, false)
+		vmToCreate, vmFound := cloud.GetSharedVmToCreate(biggerVmResPct, false)
 		k8sNodeToCreate := asmodel.GenK8sNodeFromPods(vmToCreate, []apiv1.Pod{})
 
 		// if the 1 does not work, we should do 3, so we should copy the iter and curAppName, avoiding changing the environment.
@@ -143,7 +143,8 @@ func resAccOneCloudSharedVm(cloud asmodel.Cloud, apps map[string]asmodel.Applica
 		appNamesToThisVm, meetAllRest := vmResMeetAllRestApps(k8sNodeToCreate, apps, &curAppNameCopy, iterCopy.nextAppName, true)
 
 		// Only if this VM can meet all rest applications, we apply this vm scheme and put the vm allocation information into the solution.
-		if meetAllRest {
+		// On the clouds using flavors, if no flavor fits this percentage, we also try 3.
+		if vmFound && meetAllRest {
 			// modify single app solutions
 			for _, appName := range appNamesToThisVm {
 				thisAppSoln := solnWithVm.AppsSolution[appName]
@@ -158,7 +159,7 @@ func resAccOneCloudSharedVm(cloud asmodel.Cloud, apps map[string]asmodel.Applica
 		}
 
 	} else if cloudLeastResPct > smallerVmResPct { // try 2
-		vmToCreate := cloud.GetSharedVmToCreate(smallerVmResPct, false)
+		vmToCreate, vmFound := cloud.GetSharedVmToCreate(smallerVmResPct, false)
 		k8sNodeToCreate := asmodel.GenK8sNodeFromPods(vmToCreate, []apiv1.Pod{})
 
 		// if the 2 does not work, we should do 3, so we should copy the iter and curAppName, avoiding changing the environment.
@@ -168,7 +169,8 @@ func resAccOneCloudSharedVm(cloud asmodel.Cloud, apps map[string]asmodel.Applica
 		appNamesToThisVm, meetAllRest := vmResMeetAllRestApps(k8sNodeToCreate, apps, &curAppNameCopy, iterCopy.nextAppName, true)
 
 		// Only if this VM can meet all rest applications, we apply this vm scheme and put the vm allocation information into the solution.
-		if meetAllRest {
+		// On the clouds using flavors, if no flavor fits this percentage, we also try 3.
+		if vmFound && meetAllRest {
 			// modify single app solutions
 			for _, appName := range appNamesToThisVm {
 				thisAppSoln := solnWithVm.AppsSolution[appName]
@@ -187,7 +189,10 @@ func resAccOneCloudSharedVm(cloud asmodel.Cloud, apps map[string]asmodel.Applica
 	// If the rest percentage of this cloud's resources are less than 30%,
 	// or if the vmToCreate in the above tried 1 or 2 cannot meet all rest applications scheduled to this cloud,
 	// we try 3.
-	vmToCreate := cloud.GetSharedVmToCreate(0, true)
+	vmToCreate, vmFound := cloud.GetSharedVmToCreate(0, true)
+	if !vmFound {
+		return solnWithVm, false // On the clouds using flavors, no flavor fits the rest resources of this cloud.
+	}
 	k8sNodeToCreate := asmodel.GenK8sNodeFromPods(vmToCreate, []apiv1.Pod{})
 
 	appNamesToThisVm, meetAllRest := vmResMeetAllRestApps(k8sNodeToCreate, apps, &curAppName, appsIter.nextAppName, true)
@@ -234,8 +239,19 @@ func resAccOneCloudDedicatedVms(cloud asmodel.Cloud, apps map[string]asmodel.App
 
 	// group the max-priority applications according to their dependencies. The applications with dependencies should be in the same group, and we will create one dedicated VM for one group.
 	maxPriAppsGroups := groupByDep(maxPriApps)
+	// The replicas of an application cannot be on the same VM, so if a group includes 2 replicas of an application, we cannot use the dedicated VMs.
+	for _, group := range maxPriAppsGroups {
+		for i, appName := range group {
+			if hasSiblingReplica(apps, group[:i], appName) {
+				return asmodel.Solution{}, false
+			}
+		}
+	}
 	simulatedCloud := asmodel.CloudCopy(cloud) // avoid changing the original cloud variable
-	dedicatedVmsToCreate := getDedicatedVmsToCreate(&simulatedCloud, apps, maxPriAppsGroups)
+	dedicatedVmsToCreate, vmsFound := getDedicatedVmsToCreate(&simulatedCloud, apps, maxPriAppsGroups)
+	if !vmsFound {
+		return asmodel.Solution{}, false // On the clouds using flavors, no flavor can meet some group of applications.
+	}
 
 	// put the app scheduling vm information into the solution
 	for i := 0; i < len(maxPriAppsGroups); i++ {
@@ -266,20 +282,25 @@ func resAccOneCloudDedicatedVms(cloud asmodel.Cloud, apps map[string]asmodel.App
 }
 
 // In some conditions, we create a dedicated VM for each application group.
+// If the VM for any group cannot be created (no flavor can meet it on the clouds using flavors), it returns false.
 // NOTE: This function changes the cloud, and we need to pass a copy to avoid the change.
-func getDedicatedVmsToCreate(cloud *asmodel.Cloud, apps map[string]asmodel.Application, appGroups [][]string) []models.IaasVm {
+func getDedicatedVmsToCreate(cloud *asmodel.Cloud, apps map[string]asmodel.Application, appGroups [][]string) ([]models.IaasVm, bool) {
 	var vmsToCreate []models.IaasVm = make([]models.IaasVm, len(appGroups))
 
 	for i := 0; i < len(appGroups); i++ {
-		vmsToCreate[i] = getDedVmOneGroup(*cloud, apps, appGroups[i]) // The apps in appGroups[i] are scheduled to vmsToCreate[i].
-		simulateCreateVm(cloud, vmsToCreate[i], apps, appGroups[i])   // subtract the resources and add the new vm record to this cloud
+		var found bool
+		vmsToCreate[i], found = getDedVmOneGroup(*cloud, apps, appGroups[i]) // The apps in appGroups[i] are scheduled to vmsToCreate[i].
+		if !found {
+			return nil, false
+		}
+		simulateCreateVm(cloud, vmsToCreate[i], apps, appGroups[i]) // subtract the resources and add the new vm record to this cloud
 	}
 
-	return vmsToCreate
+	return vmsToCreate, true
 }
 
-// get the dedicated vm to create for an app group
-func getDedVmOneGroup(cloud asmodel.Cloud, apps map[string]asmodel.Application, appGroup []string) models.IaasVm {
+// get the dedicated vm to create for an app group. On the clouds using flavors, the VM uses the smallest flavor that can meet the group, and if there is no such flavor, it returns false.
+func getDedVmOneGroup(cloud asmodel.Cloud, apps map[string]asmodel.Application, appGroup []string) (models.IaasVm, bool) {
 
 	// calculate the total resources that are needed by this group of applications.
 	// for max-priority applications on dedicated VMs, we regulate that it occupy its requested CPU cores rather than minCpu
@@ -294,7 +315,7 @@ func getDedVmOneGroup(cloud asmodel.Cloud, apps map[string]asmodel.Application,
 		Storage: models.CalcVmTotalStorGiB(neededAvailRes.Storage),
 	}
 
-	return deDVmToCreate
+	return cloud.FitVmToFlavor(deDVmToCreate)
 }
 
 // simulate to create a vm on a simulated cloud
@@ -345,8 +366,8 @@ func vmResMeetAllRestApps(vm asmodel.K8sNode, apps map[string]asmodel.Applicatio
 
 	var appNamesToThisVm []string // the application names that are scheduled to this VM
 
-	// we loop until the resources of this VM is used up.
-	for isResEnough(vm, apps[*curAppName], minCpu) {
+	// we loop until the resources of this VM is used up, or until the next application is a replica of an application which already has a replica on this VM, because the replicas of an application must be on different VMs.
+	for isResEnough(vm, apps[*curAppName], minCpu) && !hasSiblingReplica(apps, appNamesToThisVm, *curAppName) {
 		// simulate deploying this application on this VM.
 		subRes(&vm, apps[*curAppName], minCpu)
 		appNamesToThisVm = append(appNamesToThisVm, *curAppName)
@@ -362,3 +383,17 @@ func vmResMeetAllRestApps(vm asmodel.K8sNode, apps map[string]asmodel.Applicatio
 
 	return appNamesToThisVm, false // this means that the rest of the VM's resources can not meet the next application, so the resources are not enough
 }
+
+// check whether any of the applications is another replica of the same application as the input application.
+func hasSiblingReplica(apps map[string]asmodel.Application, appNames []string, appName string) bool {
+	replicaOf := apps[appName].ReplicaOf
+	if len(replicaOf) == 0 {
+		return false
+	}
+	for _, name := range appNames {
+		if name != appName && apps[name].ReplicaOf == replicaOf {
+			return true
+		}
+	}
+	return false
+}
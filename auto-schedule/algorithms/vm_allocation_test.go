@@ -177,6 +177,7 @@ func TestInnerGetDedVmOneGroup(t *testing.T) {
 		apps           map[string]asmodel.Application
 		appGroup       []string
 		expectedResult models.IaasVm
+		expectedFound  bool
 	}{
 		{
 			name:     "case1",
@@ -190,6 +191,7 @@ func TestInnerGetDedVmOneGroup(t *testing.T) {
 				Ram:     models.CalcVmTotalRamMiB(990 + 990 + 540),
 				Storage: models.CalcVmTotalStorGiB(15 + 15 + 35),
 			},
+			expectedFound: true,
 		},
 		{
 			name:     "case2",
@@ -203,13 +205,37 @@ func TestInnerGetDedVmOneGroup(t *testing.T) {
 				Ram:     models.CalcVmTotalRamMiB(660 + 990 + 540),
 				Storage: models.CalcVmTotalStorGiB(6 + 15 + 15),
 			},
+			expectedFound: true,
+		},
+		{
+			name:     "case openstack flavor",
+			cloud:    openstackCloudForTest(),
+			apps:     apps,
+			appGroup: []string{"app3", "app6", "app7"},
+			expectedResult: models.IaasVm{
+				Name:    "auto-sched-claaudia-0",
+				Cloud:   "CLAAUDIA",
+				VCpu:    16,
+				Ram:     32768,
+				Storage: models.CalcVmTotalStorGiB(15 + 15 + 35),
+			},
+			expectedFound: true,
+		},
+		{
+			name:           "case openstack no flavor big enough",
+			cloud:          openstackCloudForTest(),
+			apps:           apps,
+			appGroup:       []string{"app1", "app2", "app3", "app4", "app5", "app6", "app7", "app8"},
+			expectedResult: models.IaasVm{},
+			expectedFound:  false,
 		},
 	}
 
 	for i, testCase := range testCases {
 		t.Logf("test: %d, %s", i, testCase.name)
-		actualResult := getDedVmOneGroup(testCase.cloud, testCase.apps, testCase.appGroup)
+		actualResult, actualFound := getDedVmOneGroup(testCase.cloud, testCase.apps, testCase.appGroup)
 		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedFound, actualFound, fmt.Sprintf("%s: found is not expected", testCase.name))
 	}
 
 }
@@ -328,7 +354,8 @@ func TestInnerGetDedicatedVmsToCreate(t *testing.T) {
 
 	for i, testCase := range testCases {
 		t.Logf("test: %d, %s", i, testCase.name)
-		actualVms := getDedicatedVmsToCreate(testCase.cloud, testCase.apps, testCase.appGroups)
+		actualVms, actualFound := getDedicatedVmsToCreate(testCase.cloud, testCase.apps, testCase.appGroups)
+		assert.True(t, actualFound, fmt.Sprintf("%s: found is not expected", testCase.name))
 		assert.Equal(t, testCase.expectedVms, actualVms, fmt.Sprintf("%s: result is not expected", testCase.name))
 		assert.Equal(t, testCase.expectedCloudAfter, *testCase.cloud, fmt.Sprintf("%s: result is not expected", testCase.name))
 	}
@@ -1532,3 +1559,88 @@ func TestInnerVmResMeetAllRestAppsNotMaxPri(t *testing.T) {
 	}()
 
 }
+
+func TestInnerHasSiblingReplica(t *testing.T) {
+	apps := asmodel.ExpandReplicas(map[string]asmodel.Application{
+		"frontend": {Name: "frontend", Replicas: 2},
+		"backend":  {Name: "backend", Replicas: 2},
+		"db":       {Name: "db"},
+	})
+
+	testCases := []struct {
+		name           string
+		appNames       []string
+		appName        string
+		expectedResult bool
+	}{
+		{
+			name:           "case sibling",
+			appNames:       []string{"db", "frontend#0"},
+			appName:        "frontend#1",
+			expectedResult: true,
+		},
+		{
+			name:           "case replicas of another application",
+			appNames:       []string{"db", "backend#0", "backend#1"},
+			appName:        "frontend#1",
+			expectedResult: false,
+		},
+		{
+			name:           "case itself",
+			appNames:       []string{"frontend#1"},
+			appName:        "frontend#1",
+			expectedResult: false,
+		},
+		{
+			name:           "case single-replica application",
+			appNames:       []string{"frontend#0"},
+			appName:        "db",
+			expectedResult: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := hasSiblingReplica(apps, testCase.appNames, testCase.appName)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerVmResMeetAllRestAppsReplicas(t *testing.T) {
+	apps := asmodel.ExpandReplicas(map[string]asmodel.Application{
+		"frontend": {Name: "frontend", Replicas: 2, Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 1, Memory: 100, Storage: 1}}},
+	})
+	appsOrder := []string{"frontend#0", "frontend#1"}
+	vm := asmodel.K8sNode{
+		Name:              "node1",
+		ResidualResources: asmodel.GenericResources{CpuCore: 8, Memory: 8192, Storage: 100},
+	}
+
+	appsIter := newIterForApps(apps, appsOrder)
+	curAppName := appsIter.nextAppName()
+	appNamesToThisVm, meetAllRest := vmResMeetAllRestApps(vm, apps, &curAppName, appsIter.nextAppName, true)
+
+	// The resources are enough for both replicas, but they cannot be on the same VM.
+	assert.Equal(t, []string{"frontend#0"}, appNamesToThisVm, "appNamesToThisVm is not expected")
+	assert.False(t, meetAllRest, "meetAllRest is not expected")
+	assert.Equal(t, "frontend#1", curAppName, "curAppName is not expected")
+}
+
+// an Openstack cloud with some flavors, on which the resources are enough for at most 1 VM with 16 cores.
+func openstackCloudForTest() asmodel.Cloud {
+	return asmodel.Cloud{
+		Name: "CLAAUDIA",
+		Type: models.OpenstackIaas,
+		Resources: models.ResourceStatus{
+			Limit: models.ResSet{VCpu: 24, Ram: 65536, Storage: 1000, Vm: 10, Port: 10, Volume: 10},
+			InUse: models.ResSet{VCpu: 4, Ram: 8192, Storage: 100, Vm: 1, Port: 1, Volume: 1},
+		},
+		K8sNodes: []asmodel.K8sNode{},
+		Flavors: []models.VmFlavor{
+			{Name: "m1.small", VCpu: 2, Ram: 4096},
+			{Name: "m1.large", VCpu: 8, Ram: 16384},
+			{Name: "m1.xlarge", VCpu: 16, Ram: 32768},
+			{Name: "m1.huge", VCpu: 32, Ram: 65536},
+		},
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/astaxie/beego"
+
+	"emcontroller/auto-schedule/algorithms"
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// The output of this program.
+type schedulingResult struct {
+	Algorithm    string                   `json:"algorithm"`
+	Seed         int64                    `json:"seed"`
+	GaParams     algorithms.GaParams      `json:"gaParams"`
+	SchedTimeSec float64                  `json:"schedTimeSec"`
+	Fitness      float64                  `json:"fitness"` // the fitness value of the solution calculated by Mcssga, the same as the plan of Multi-cloud Manager
+	Stats        acceptanceStats          `json:"stats"`
+	NewVmsHourly float64                  `json:"newVmsHourly"` // the price per hour of the VMs to create, only counting the clouds with pricing
+	VmsToCreate  []models.IaasVm          `json:"vmsToCreate"`
+	Solution     asmodel.Solution         `json:"solution"`
+	ParetoFront  []algorithms.ParetoPoint `json:"paretoFront,omitempty"` // only with multi-objective algorithms, e.g., Nsga2
+}
+
+// The acceptance statistics of a solution. The replicas of an application are accepted or rejected together, so they are counted as one application.
+type acceptanceStats struct {
+	AppCount                          int      `json:"appCount"`
+	AcceptedAppCount                  int      `json:"acceptedAppCount"`
+	AppAcceptanceRate                 float64  `json:"appAcceptanceRate"`
+	AppPriorityWeightedAcceptanceRate float64  `json:"appPriorityWeightedAcceptanceRate"`
+	RejectedApps                      []string `json:"rejectedApps"`
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	snapshotFile := flag.String("snapshot", "", "The snapshot exported by \"/appGroup/snapshot\" of Multi-cloud Manager. If it is set, -clouds and -apps are not needed.")
+	cloudsFile := flag.String("clouds", "", "The clouds in the JSON format of map[string]asmodel.Cloud.")
+	appsFile := flag.String("apps", "", "The applications in the JSON format of map[string]asmodel.Application.")
+	algoName := flag.String("algo", algorithms.McssgaName, fmt.Sprintf("The scheduling algorithm, one of %v.", algorithms.AlgorithmNames()))
+	chromosomesCount := flag.Int("chromosomes", algorithms.DefaultGaParams.ChromosomesCount, "The number of chromosomes of genetic algorithms.")
+	iterationCount := flag.Int("iterations", algorithms.DefaultGaParams.IterationCount, "The maximum number of iterations of genetic algorithms.")
+	crossoverProbability := flag.Float64("crossover", algorithms.DefaultGaParams.CrossoverProbability, "The crossover probability of genetic algorithms.")
+	mutationProbability := flag.Float64("mutation", algorithms.DefaultGaParams.MutationProbability, "The mutation probability of genetic algorithms.")
+	stopNoUpdateIteration := flag.Int("stop-no-update", algorithms.DefaultGaParams.StopNoUpdateIteration, "Genetic algorithms stop if the best solution is not updated for so many iterations.")
+	exTimeOneCpu := flag.Float64("exp-time-one-cpu", algorithms.DefaultExpAppCompuTimeOneCpu, "The expected computation time of every application by one CPU core, unit millisecond.")
+	paretoStr := flag.String("pareto", "", "How multi-objective algorithms pick the solution from the Pareto front, the same format as the header \"Mcm-Pareto-Selection\".")
+	maxHourlyCost := flag.Float64("max-hourly-cost", 0, "The budget per hour of the VMs to create, 0 means no limit.")
+	timeLimit := flag.Duration("time-limit", algorithms.DefaultExactTimeLimit, "How long the algorithms searching until an optimal solution (e.g., Exact) can run, e.g., 30s.")
+	deadline := flag.Duration("deadline", 0, "The time limit of scheduling, the same as the header \"Mcm-Scheduling-Time-Limit\", after which the genetic algorithms and Exact return the best solution found so far, e.g., 10s. 0 means no limit.")
+	seed := flag.Int64("seed", 0, "The seed of the random generator, e.g., the one in the log or the plan of Multi-cloud Manager. Without it, a seed is generated.")
+	workers := flag.Int("workers", 0, "The number of goroutines of genetic algorithms, 0 means all CPUs.")
+	outFile := flag.String("out", "", "The file to write the result in. Without it, the result is printed.")
+	verbose := flag.Bool("verbose", false, "Print the logs of the algorithms, e.g., the fitness values of every iteration.")
+	flag.Parse()
+
+	// the algorithms log a lot to stdout, which would be mixed with the result.
+	if !*verbose {
+		beego.SetLevel(beego.LevelWarning)
+	}
+
+	// the seed 0 is also valid, so we check whether the flag is set.
+	seedSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedSet = true
+		}
+	})
+	if !seedSet {
+		*seed = algorithms.NewSeed()
+	}
+
+	snapshot, err := loadSnapshot(*snapshotFile, *cloudsFile, *appsFile)
+	if err != nil {
+		log.Fatalf("Load the input, error: %s", err.Error())
+	}
+
+	pareto := algorithms.DefaultParetoSelection
+	if len(*paretoStr) != 0 {
+		if pareto, err = algorithms.ParseParetoSelection(*paretoStr); err != nil {
+			log.Fatalf("Parse -pareto [%s], error: %s", *paretoStr, err.Error())
+		}
+	}
+
+	algorithms.GaWorkers = *workers
+	params := algorithms.AlgoParams{
+		ExpAppCompuTimeOneCpu: *exTimeOneCpu,
+		Ga: algorithms.GaParams{
+			ChromosomesCount:      *chromosomesCount,
+			IterationCount:        *iterationCount,
+			CrossoverProbability:  *crossoverProbability,
+			MutationProbability:   *mutationProbability,
+			StopNoUpdateIteration: *stopNoUpdateIteration,
+		},
+		Pareto:        pareto,
+		MaxHourlyCost: *maxHourlyCost,
+		Seed:          *seed,
+		TimeLimit:     *timeLimit,
+	}
+	if *deadline > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), *deadline)
+		defer cancel()
+		params.Ctx = ctx
+	}
+
+	result, err := schedule(*algoName, params, snapshot)
+	if err != nil {
+		log.Fatalf("Schedule, error: %s", err.Error())
+	}
+	log.Printf("Algorithm [%s] with the seed [%d] accepts %d of %d applications in %g seconds, fitness value %g, %d VMs to create.", result.Algorithm, result.Seed, result.Stats.AcceptedAppCount, result.Stats.AppCount, result.SchedTimeSec, result.Fitness, len(result.VmsToCreate))
+
+	resultJson, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatalf("json.MarshalIndent the result, error: %s", err.Error())
+	}
+	if len(*outFile) == 0 {
+		fmt.Println(string(resultJson))
+		return
+	}
+	if err := os.WriteFile(*outFile, resultJson, 0644); err != nil {
+		log.Fatalf("Write the result in file [%s], error: %s", *outFile, err.Error())
+	}
+	log.Printf("The result is written in file [%s].", *outFile)
+}
+
+// read the input from a snapshot file, or from a clouds file and an applications file.
+func loadSnapshot(snapshotFile, cloudsFile, appsFile string) (asmodel.Snapshot, error) {
+	var snapshot asmodel.Snapshot
+	if len(snapshotFile) != 0 {
+		if err := readJsonFile(snapshotFile, &snapshot); err != nil {
+			return asmodel.Snapshot{}, err
+		}
+	} else {
+		if len(cloudsFile) == 0 || len(appsFile) == 0 {
+			return asmodel.Snapshot{}, fmt.Errorf("either -snapshot, or both -clouds and -apps should be set")
+		}
+		if err := readJsonFile(cloudsFile, &snapshot.Clouds); err != nil {
+			return asmodel.Snapshot{}, err
+		}
+		if err := readJsonFile(appsFile, &snapshot.Apps); err != nil {
+			return asmodel.Snapshot{}, err
+		}
+	}
+
+	// The applications in a file can have multiple replicas, which need to be expanded, the same as Multi-cloud Manager. The applications in a snapshot are already expanded, and expanding them again changes nothing.
+	snapshot.Apps = asmodel.ExpandReplicas(snapshot.Apps)
+	if len(snapshot.AppsOrder) == 0 {
+		snapshot.AppsOrder = algorithms.GenerateAppsOrder(snapshot.Apps)
+		sort.Strings(snapshot.AppsOrder)
+	}
+	return snapshot, nil
+}
+
+func readJsonFile(fileName string, v interface{}) error {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("read file [%s], error: %w", fileName, err)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("json.Unmarshal file [%s], error: %w", fileName, err)
+	}
+	return nil
+}
+
+// schedule the applications in the snapshot with the algorithm, and evaluate the solution in the same way as Multi-cloud Manager.
+func schedule(algoName string, params algorithms.AlgoParams, snapshot asmodel.Snapshot) (schedulingResult, error) {
+	if errs := algorithms.ValidateGaParams(params.Ga); len(errs) != 0 {
+		return schedulingResult{}, fmt.Errorf("the GA parameters are invalid, error: %w", models.HandleErrSlice(errs))
+	}
+	algo, err := algorithms.NewAlgorithm(algoName, params)
+	if err != nil {
+		return schedulingResult{}, err
+	}
+
+	startTime := time.Now()
+	solution, err := algo.Schedule(snapshot.Clouds, snapshot.Apps, snapshot.AppsOrder)
+	if err != nil {
+		return schedulingResult{}, fmt.Errorf("run the Schedule method of %s, error: %w", algoName, err)
+	}
+	schedTimeSec := time.Since(startTime).Seconds()
+
+	// the Mcssga instance to calculate the fitness value of solutions
+	mcssgaInstance := algorithms.NewMcssga(params.Ga.ChromosomesCount, params.Ga.IterationCount, params.Ga.CrossoverProbability, params.Ga.MutationProbability, params.Ga.StopNoUpdateIteration, params.ExpAppCompuTimeOneCpu)
+	mcssgaInstance.MaxHourlyCost = params.MaxHourlyCost
+	mcssgaInstance.SetMaxReaRtt(snapshot.Clouds)
+	mcssgaInstance.SetAvgDepNum(snapshot.Apps)
+
+	result := schedulingResult{
+		Algorithm:    algoName,
+		Seed:         params.Seed,
+		GaParams:     params.Ga,
+		SchedTimeSec: schedTimeSec,
+		Fitness:      mcssgaInstance.Fitness(snapshot.Clouds, snapshot.Apps, solution),
+		Stats:        calcAcceptanceStats(snapshot.Apps, solution),
+		NewVmsHourly: algorithms.SolutionCost(snapshot.Clouds, solution),
+		VmsToCreate:  solution.VmsToCreate,
+		Solution:     solution,
+	}
+	if result.VmsToCreate == nil {
+		result.VmsToCreate = []models.IaasVm{}
+	}
+	if paretoAlgo, isPareto := algo.(algorithms.ParetoScheduler); isPareto {
+		result.ParetoFront, _ = paretoAlgo.ParetoFront()
+	}
+	return result, nil
+}
+
+// calculate the acceptance statistics of a solution. apps are the expanded applications.
+func calcAcceptanceStats(apps map[string]asmodel.Application, solution asmodel.Solution) acceptanceStats {
+	// the replicas of an application are accepted or rejected together, so we count an application once.
+	priorities := make(map[string]int)
+	accepted := make(map[string]bool)
+	for name, app := range apps {
+		appName, _, _ := asmodel.SplitReplicaName(name)
+		priorities[appName] = app.Priority
+		accepted[appName] = solution.AppsSolution[name].Accepted
+	}
+
+	stats := acceptanceStats{RejectedApps: []string{}}
+	var totalPriority, acceptedPriority int
+	for appName, priority := range priorities {
+		stats.AppCount++
+		totalPriority += priority
+		if accepted[appName] {
+			stats.AcceptedAppCount++
+			acceptedPriority += priority
+		} else {
+			stats.RejectedApps = append(stats.RejectedApps, appName)
+		}
+	}
+	sort.Strings(stats.RejectedApps)
+
+	if stats.AppCount != 0 {
+		stats.AppAcceptanceRate = float64(stats.AcceptedAppCount) / float64(stats.AppCount)
+	}
+	if totalPriority != 0 {
+		stats.AppPriorityWeightedAcceptanceRate = float64(acceptedPriority) / float64(totalPriority)
+	}
+	return stats
+}
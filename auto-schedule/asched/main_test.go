@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/auto-schedule/algorithms"
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func TestCalcAcceptanceStats(t *testing.T) {
+	apps := map[string]asmodel.Application{
+		"app1":   {Name: "app1", Priority: 10},
+		"app2":   {Name: "app2", Priority: 5},
+		"app3#0": {Name: "app3#0", Priority: 5, ReplicaOf: "app3"},
+		"app3#1": {Name: "app3#1", Priority: 5, ReplicaOf: "app3"},
+	}
+
+	testCases := []struct {
+		name          string
+		acceptedNames []string
+		expected      acceptanceStats
+	}{
+		{
+			name:          "case all rejected",
+			acceptedNames: nil,
+			expected:      acceptanceStats{AppCount: 3, RejectedApps: []string{"app1", "app2", "app3"}},
+		},
+		{
+			name:          "case replicas counted once",
+			acceptedNames: []string{"app1", "app3#0", "app3#1"},
+			expected:      acceptanceStats{AppCount: 3, AcceptedAppCount: 2, AppAcceptanceRate: 2.0 / 3, AppPriorityWeightedAcceptanceRate: 15.0 / 20, RejectedApps: []string{"app2"}},
+		},
+		{
+			name:          "case all accepted",
+			acceptedNames: []string{"app1", "app2", "app3#0", "app3#1"},
+			expected:      acceptanceStats{AppCount: 3, AcceptedAppCount: 3, AppAcceptanceRate: 1, AppPriorityWeightedAcceptanceRate: 1, RejectedApps: []string{}},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		solution := asmodel.GenEmptySoln()
+		for name := range apps {
+			solution.AppsSolution[name] = asmodel.SingleAppSolution{Accepted: false}
+		}
+		for _, name := range testCase.acceptedNames {
+			solution.AppsSolution[name] = asmodel.SingleAppSolution{Accepted: true}
+		}
+		assert.Equal(t, testCase.expected, calcAcceptanceStats(apps, solution), fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestLoadSnapshotAndSchedule(t *testing.T) {
+	dir := t.TempDir()
+	cloudsFile := filepath.Join(dir, "clouds.json")
+	appsFile := filepath.Join(dir, "apps.json")
+	clouds := map[string]asmodel.Cloud{
+		"cloud1": {
+			Name: "cloud1",
+			Type: models.ProxmoxIaas,
+			Resources: models.ResourceStatus{
+				Limit: models.ResSet{VCpu: 20, Ram: 40960, Vm: -1, Volume: -1, Storage: 500, Port: -1},
+				InUse: models.ResSet{VCpu: 2, Ram: 4096, Vm: -1, Volume: -1, Storage: 50, Port: -1},
+			},
+			NetState: map[string]models.NetworkState{"cloud1": {Rtt: 1}},
+		},
+	}
+	apps := map[string]asmodel.Application{
+		"app1": {Name: "app1", Priority: 5, Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 2, Memory: 1024, Storage: 10}}},
+		"app2": {Name: "app2", Priority: 3, Replicas: 2, Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 1, Memory: 512, Storage: 5}}, Dependencies: []models.Dependency{{AppName: "app1"}}},
+	}
+	assert.Nil(t, os.WriteFile(cloudsFile, []byte(models.JsonString(clouds)), 0644))
+	assert.Nil(t, os.WriteFile(appsFile, []byte(models.JsonString(apps)), 0644))
+
+	_, err := loadSnapshot("", cloudsFile, "")
+	assert.NotNil(t, err, "only -clouds should be an error")
+
+	snapshot, err := loadSnapshot("", cloudsFile, appsFile)
+	assert.Nil(t, err)
+	// the replicas should be expanded, and the order should be fixed.
+	assert.Equal(t, []string{"app1", "app2#0", "app2#1"}, snapshot.AppsOrder)
+
+	params := algorithms.AlgoParams{
+		ExpAppCompuTimeOneCpu: algorithms.DefaultExpAppCompuTimeOneCpu,
+		Ga:                    algorithms.GaParams{ChromosomesCount: 10, IterationCount: 10, CrossoverProbability: 0.7, MutationProbability: 0.02, StopNoUpdateIteration: 10},
+		Pareto:                algorithms.DefaultParetoSelection,
+		Seed:                  20231017,
+	}
+	result1, err := schedule(algorithms.McssgaName, params, snapshot)
+	assert.Nil(t, err)
+	result2, err := schedule(algorithms.McssgaName, params, snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result1.Stats.AppCount)
+	assert.Equal(t, result1.Solution, result2.Solution, "the same seed should give the same solution")
+	assert.Equal(t, result1.Fitness, result2.Fitness)
+
+	_, err = schedule("noSuchAlgorithm", params, snapshot)
+	assert.ErrorIs(t, err, algorithms.ErrUnknownAlgorithm)
+}
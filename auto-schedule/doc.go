@@ -9,6 +9,10 @@ Thus, "controllers" will import "this package" and "models", "this package" will
 
 /*
 For migration,
+when multi-cloud manager deploys an auto-scheduled application, it saves the information needed for auto-scheduling (the Json of model.Application) into the Annotation with key "auto-schedule/info" of the Kubernetes Deployment.
+When migrating, we read this information from all running auto-scheduled applications, simulate to remove their pods from the clouds, and schedule them again as new applications.
+Then, in the topological order of their dependencies, we update the node name and CPU of the Deployments whose Kubernetes nodes are changed, and wait for the rolling updates finished. The applications rejected by the algorithm stay where they are.
+Scheduling and migration share one lock, so they cannot be done at the same time.
 */
 
 package auto_schedule
@@ -1,9 +1,14 @@
 package executors
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"sort"
+	"strconv"
 
 	"github.com/astaxie/beego"
 
@@ -13,86 +18,406 @@ import (
 )
 
 // algoName is the name of the scheduling algorithm to use.
-func CreateAutoScheduleApps(apps []models.K8sApp, algoName string, exTimeOneCpu float64) ([]models.AppInfo, error, int) {
+// gaParams is the parameters used if the algorithm is a genetic algorithm.
+// preempt is the opt-in preemption mode. The evicted running applications are also returned.
+// reporter is used to report the sub-steps and progress when this function runs as a task.
+// ctx is passed to the scheduling algorithm. When its deadline is reached, the anytime algorithms return the best solution found so far, which is deployed. When it is cancelled, e.g., the client disconnects, nothing is deployed.
+func CreateAutoScheduleApps(ctx context.Context, apps []models.K8sApp, algoName string, exTimeOneCpu float64, gaParams algorithms.GaParams, pareto algorithms.ParetoSelection, maxHourlyCost float64, seed int64, preempt PreemptOptions, reporter models.TaskReporter) ([]models.AppInfo, []EvictedApp, error, int) {
+
+	reporter.Logf("Scheduling %d applications with the GA parameters %s.", len(apps), models.JsonString(gaParams))
+	scheOut, err, statusCode := scheduleApps(ctx, apps, algoName, exTimeOneCpu, gaParams, pareto, maxHourlyCost, seed, preempt)
+	if err != nil {
+		outErr := fmt.Errorf("Schedule applications, Error: [%w]", err)
+		beego.Error(outErr)
+		return []models.AppInfo{}, []EvictedApp{}, outErr, statusCode
+	}
+	if !scheOut.cost.WithinBudget {
+		outErr := fmt.Errorf("The new VMs of the solution cost [%g] per hour, more than the budget [%g]. Please check the plan by /appGroup/plan", scheOut.cost.NewVmsHourly, scheOut.cost.MaxHourly)
+		beego.Error(outErr)
+		return []models.AppInfo{}, []EvictedApp{}, outErr, http.StatusUnprocessableEntity
+	}
+	appsForScheduling, solution := scheOut.apps, scheOut.solution
+	for _, appName := range sortedKeys(scheOut.rejectReasons) {
+		reporter.Logf("Application [%s] is rejected, because %s.", appName, scheOut.rejectReasons[appName])
+	}
+	reporter.SetProgress(30)
+
+	// create the VMs and add them to Kubernetes. The VMs for the rescheduled evicted applications are also created.
+	vmsToCreate := append(append([]models.IaasVm{}, solution.VmsToCreate...), scheOut.evictionVms...)
+	reporter.Logf("Creating %d VMs and adding them to Kubernetes.", len(vmsToCreate))
+	if _, err := models.AddNewVms(vmsToCreate); err != nil {
+		outErr := fmt.Errorf("Add new auto-scheduling VMs, Error: [%w]", err)
+		beego.Error(outErr)
+		return []models.AppInfo{}, []EvictedApp{}, outErr, http.StatusInternalServerError
+	}
+
+	// evict the running applications in the way of the new applications before deploying them.
+	if len(scheOut.evictedApps) != 0 {
+		reporter.Logf("Evicting %d running applications.", len(scheOut.evictedApps))
+		if err := evictApps(scheOut.evictedApps, scheOut.evictables, reporter); err != nil {
+			outErr := fmt.Errorf("Evict running applications, Error: [%w]", err)
+			beego.Error(outErr)
+			return []models.AppInfo{}, scheOut.evictedApps, outErr, http.StatusInternalServerError
+		}
+	}
+
+	// add the auto-scheduling information into the applications to deploy.
+	appsToDeploy := addScheInfoToApps(apps, solution)
+	// add the information needed for auto-scheduling into the applications to deploy, so that we can migrate them later.
+	appsToDeploy, err = addAutoScheInfoToApps(appsToDeploy, appsForScheduling)
+	if err != nil {
+		outErr := fmt.Errorf("Add auto-scheduling information into applications, Error: [%w]", err)
+		beego.Error(outErr)
+		return []models.AppInfo{}, scheOut.evictedApps, outErr, http.StatusInternalServerError
+	}
+
+	reporter.SetProgress(60)
+
+	// deploy applications, and wait for them running.
+	reporter.Logf("Deploying %d accepted applications.", len(appsToDeploy))
+	createdAppsInfo, err := models.CreateAppsWait(appsToDeploy)
+	if err != nil {
+		outErr := fmt.Errorf("Create auto-scheduling applications [%s], Error: [%w]", models.JsonString(appsToDeploy), err)
+		beego.Error(outErr)
+		return []models.AppInfo{}, scheOut.evictedApps, outErr, http.StatusInternalServerError
+	}
+
+	return createdAppsInfo, scheOut.evictedApps, nil, http.StatusCreated
+}
+
+// After scheduling applications, we should use this functions to add the scheduling information to applications.
+func addScheInfoToApps(apps []models.K8sApp, scheSoln asmodel.Solution) []models.K8sApp {
+	var appsWithScheInfo []models.K8sApp
+
+	for _, app := range apps {
+		if app.Replicas > 1 {
+			appWithScheInfo, accepted := addScheInfoToMultiReplicaApp(app, scheSoln)
+			if accepted {
+				appsWithScheInfo = append(appsWithScheInfo, appWithScheInfo)
+			}
+			continue
+		}
+
+		// Remove the rejected applications from the array.
+		if !scheSoln.AppsSolution[app.Name].Accepted {
+			continue
+		}
+
+		// add node name
+		app.NodeName = scheSoln.AppsSolution[app.Name].K8sNodeName
+		// configure allocated CPU
+		app.Containers = setContainersCpu(app.Containers, scheSoln.AppsSolution[app.Name].AllocatedCpuCore)
+
+		appsWithScheInfo = append(appsWithScheInfo, app)
+	}
+
+	return appsWithScheInfo
+}
+
+// The replicas of a multi-replica application are scheduled separately (see asmodel.ExpandReplicas), and they are accepted or rejected together.
+// One Deployment cannot set a node for every pod, so we let the pods only run on the nodes of the replicas, and the pod anti-affinity makes every node run one pod.
+// One Deployment also cannot set different CPU for every pod, so we use the least CPU allocated to the replicas, which every node can provide.
+func addScheInfoToMultiReplicaApp(app models.K8sApp, scheSoln asmodel.Solution) (models.K8sApp, bool) {
+	var nodeNames []string
+	var leastCpu float64
+	for i := 0; i < int(app.Replicas); i++ {
+		replicaSoln := scheSoln.AppsSolution[asmodel.ReplicaName(app.Name, i)]
+		if !replicaSoln.Accepted {
+			return models.K8sApp{}, false
+		}
+		nodeNames = append(nodeNames, replicaSoln.K8sNodeName)
+		if i == 0 || replicaSoln.AllocatedCpuCore < leastCpu {
+			leastCpu = replicaSoln.AllocatedCpuCore
+		}
+	}
+
+	app.NodeNames = nodeNames
+	app.Containers = setContainersCpu(app.Containers, leastCpu)
+	return app, true
+}
+
+// The algorithms allocate CPU cores to an application. We give the CPU cores to the containers of the application proportionally to their requested CPU, and set the requests and limits of every container.
+// It returns new containers and does not change the input ones.
+func setContainersCpu(containers []models.K8sContainer, allocatedCpu float64) []models.K8sContainer {
+	outContainers := make([]models.K8sContainer, len(containers))
+	copy(outContainers, containers)
+
+	containersCpu := splitCpuToContainers(containers, allocatedCpu)
+	for i := range outContainers {
+		outContainers[i].Resources.Requests.CPU = strconv.Itoa(containersCpu[i])
+		outContainers[i].Resources.Limits.CPU = strconv.Itoa(containersCpu[i])
+	}
+	return outContainers
+}
+
+// Split the CPU cores allocated to an application to its containers proportionally to their requested CPU.
+// We use "static CPU Manager policy" in Kubernetes, so every container gets an integer number of CPU cores. We use the largest remainder method to round the proportional shares, and a container does not get more than its request.
+// If the cores are enough, every container requesting CPU gets at least 1 core, so that a small sidecar will not get nothing.
+func splitCpuToContainers(containers []models.K8sContainer, allocatedCpu float64) []int {
+	requests := make([]float64, len(containers))
+	for i, container := range containers {
+		// the requests are validated before scheduling, so they can be parsed.
+		requests[i], _ = strconv.ParseFloat(container.Resources.Requests.CPU, 64)
+	}
+	return splitCpu(requests, allocatedCpu)
+}
+
+// split the CPU cores allocated to an application in the way of splitCpuToContainers, according to the CPU requested by every container. It is also used when migrating applications.
+func splitCpu(requests []float64, allocatedCpu float64) []int {
+	shares := make([]int, len(requests))
+	var totalRequest float64
+	for _, request := range requests {
+		totalRequest += request
+	}
+	coresToSplit := int(math.Round(allocatedCpu))
+	if totalRequest <= 0 || coresToSplit <= 0 {
+		return shares
+	}
+	if float64(coresToSplit) > totalRequest {
+		coresToSplit = int(totalRequest)
+	}
+
+	// the integer part of the proportional shares
+	remainders := make([]float64, len(requests))
+	rest := coresToSplit
+	for i := range requests {
+		exact := float64(coresToSplit) * requests[i] / totalRequest
+		shares[i] = int(math.Floor(exact))
+		remainders[i] = exact - float64(shares[i])
+		rest -= shares[i]
+	}
+
+	// give the rest cores to the containers with the largest remainders
+	order := make([]int, len(requests))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for _, i := range order {
+		if rest == 0 {
+			break
+		}
+		if float64(shares[i]) < requests[i] {
+			shares[i]++
+			rest--
+		}
+	}
+
+	// every container requesting CPU should get at least 1 core, which we take from the container with the most cores.
+	for i := range requests {
+		if requests[i] <= 0 || shares[i] > 0 {
+			continue
+		}
+		richest := 0
+		for j := range shares {
+			if shares[j] > shares[richest] {
+				richest = j
+			}
+		}
+		if shares[richest] <= 1 {
+			break // the cores are not enough
+		}
+		shares[richest]--
+		shares[i]++
+	}
+
+	return shares
+}
+
+// Put the Json of the information needed for auto-scheduling into the applications, which will be saved in the Annotation of the Kubernetes Deployments, so that we can migrate these applications later.
+func addAutoScheInfoToApps(apps []models.K8sApp, appsForScheduling map[string]asmodel.Application) ([]models.K8sApp, error) {
+	for i := range apps {
+		infoJson, err := json.Marshal(appsForScheduling[apps[i].Name])
+		if err != nil {
+			outErr := fmt.Errorf("json.Marshal the auto-scheduling information of application [%s], Error: [%w]", apps[i].Name, err)
+			beego.Error(outErr)
+			return nil, outErr
+		}
+		apps[i].AutoScheduleInfo = string(infoJson)
+	}
+	return apps, nil
+}
+
+// select the scheduling algorithm to use according to the input algoName. This function returns the selected algorithm, its name, and the Mcssga instance which is also used to calculate the fitness value of solutions.
+// If algoName is empty, we use Mcssga by default. If algoName is not registered, the error wraps algorithms.ErrUnknownAlgorithm.
+func selectAlgorithm(ctx context.Context, algoName string, exTimeOneCpu float64, gaParams algorithms.GaParams, pareto algorithms.ParetoSelection, maxHourlyCost float64, seed int64) (algorithms.SchedulingAlgorithm, string, *algorithms.Mcssga, error) {
+	// the Mcssga instance to calculate the fitness value of solutions
+	mcssgaInstance := algorithms.NewMcssga(gaParams.ChromosomesCount, gaParams.IterationCount, gaParams.CrossoverProbability, gaParams.MutationProbability, gaParams.StopNoUpdateIteration, exTimeOneCpu)
+	mcssgaInstance.MaxHourlyCost = maxHourlyCost
+	mcssgaInstance.Seed = seed
+	mcssgaInstance.Ctx = ctx
+
+	if len(algoName) == 0 {
+		beego.Info(fmt.Sprintf("The algorithm is not set, so we use \"%s\" by default.", algorithms.McssgaName))
+		return mcssgaInstance, algorithms.McssgaName, mcssgaInstance, nil
+	}
+
+	beego.Info(fmt.Sprintf("Looking for the algorithm \"%s\".", algoName))
+	algo, err := algorithms.NewAlgorithm(algoName, algorithms.AlgoParams{
+		ExpAppCompuTimeOneCpu: exTimeOneCpu,
+		Ga:                    gaParams,
+		Pareto:                pareto,
+		MaxHourlyCost:         maxHourlyCost,
+		Seed:                  seed,
+		Ctx:                   ctx,
+	})
+	if err != nil {
+		outErr := fmt.Errorf("Create the algorithm \"%s\", Error: [%w]", algoName, err)
+		beego.Error(outErr)
+		return nil, "", nil, outErr
+	}
+	beego.Info(fmt.Sprintf("Algorithm \"%s\" is found.", algoName))
+	return algo, algoName, mcssgaInstance, nil
+}
+
+// If ctx is cancelled, e.g., the client disconnects, the solution is not needed any more, and the returned error wraps models.ErrTaskCancelled. If only the deadline of ctx is reached, the solution is the best one found before it, which is still used, so nil is returned.
+func schedulingCancelled(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("The scheduling is %w, Error: [%s]", models.ErrTaskCancelled, ctx.Err())
+	}
+	return nil
+}
+
+// The output of scheduleApps
+type schedulingOutput struct {
+	apps           map[string]asmodel.Application // the applications for scheduling, before the replicas are expanded
+	solution       asmodel.Solution               // the keys are the names of the expanded replicas, see asmodel.ExpandReplicas
+	fitness        float64                        // the Mcssga fitness value of the solution
+	algoName       string                         // the name of the algorithm actually used
+	rejectReasons  map[string]string              // key is the name of a rejected application (not replica), value is the reason
+	evictables     map[string]evictableApp        // the running applications that can be evicted, only with preemption
+	evictedApps    []EvictedApp                   // the running applications to evict, only with preemption
+	evictionVms    []models.IaasVm                // the VMs to create for the rescheduled evicted applications
+	paretoFront    []algorithms.ParetoPoint       // the Pareto front, only with multi-objective algorithms
+	paretoSelected int                            // the index of the picked solution in paretoFront
+	cost           CostPlan                       // the cost per hour of the solution
+}
+
+// Validate the input applications and run the scheduling algorithm, without creating any VMs or applications.
+func scheduleApps(ctx context.Context, apps []models.K8sApp, algoName string, exTimeOneCpu float64, gaParams algorithms.GaParams, pareto algorithms.ParetoSelection, maxHourlyCost float64, seed int64, preempt PreemptOptions) (schedulingOutput, error, int) {
+
+	// The applications may depend on running applications, whose places are fixed in scheduling.
+	runningDeps, err := getRunningAppLocations(externalDepNames(apps))
+	if err != nil {
+		outErr := fmt.Errorf("Get the running applications that the input applications depend on, Error: [%w]", err)
+		beego.Error(outErr)
+		return schedulingOutput{}, outErr, http.StatusInternalServerError
+	}
 
 	// we only accept the valid applications, or otherwise we will have too much unnecessary workload
-	if errs := ValidateAutoScheduleApps(apps); len(errs) != 0 {
+	if errs := ValidateAutoScheduleApps(apps, runningAppNameSet(runningDeps)); len(errs) != 0 {
 		outErr := fmt.Errorf("The input applicatios are invalid, Error: [%w]", models.HandleErrSlice(errs))
 		beego.Error(outErr)
-		return []models.AppInfo{}, outErr, http.StatusBadRequest
+		return schedulingOutput{}, outErr, http.StatusBadRequest
+	}
+	if errs := algorithms.ValidateGaParams(gaParams); len(errs) != 0 {
+		outErr := fmt.Errorf("The GA parameters are invalid, Error: [%w]", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		return schedulingOutput{}, outErr, http.StatusBadRequest
+	}
+	if maxHourlyCost < 0 {
+		outErr := fmt.Errorf("The maximum hourly cost should not be negative, but it is [%g]", maxHourlyCost)
+		beego.Error(outErr)
+		return schedulingOutput{}, outErr, http.StatusBadRequest
+	}
+	if errs := ValidatePreemptOptions(preempt); len(errs) != 0 {
+		outErr := fmt.Errorf("The preemption options are invalid, Error: [%w]", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		return schedulingOutput{}, outErr, http.StatusBadRequest
 	}
 
-	// make the asmodel.Cloud structure as the input of Schedule function
-	cloudsForScheduling, err := asmodel.GenerateClouds(models.Clouds)
+	// select the algorithm to use according to the input parameter algoName
+	algoToUse, algoNameToUse, mcssgaInstance, err := selectAlgorithm(ctx, algoName, exTimeOneCpu, gaParams, pareto, maxHourlyCost, seed)
 	if err != nil {
-		outErr := fmt.Errorf("Generate input clouds for auto-scheduling, Error: [%w]", err)
+		outErr := fmt.Errorf("Select the scheduling algorithm, Error: [%w]", err)
 		beego.Error(outErr)
-		return []models.AppInfo{}, outErr, http.StatusInternalServerError
+		return schedulingOutput{}, outErr, http.StatusBadRequest
+	}
+	// multi-objective algorithms pick the solution from the Pareto front by the selection policy
+	if paretoAlgo, isPareto := algoToUse.(algorithms.ParetoScheduler); isPareto {
+		if errs := algorithms.ValidateParetoSelection(pareto, paretoAlgo.ObjectiveNames()); len(errs) != 0 {
+			outErr := fmt.Errorf("The Pareto selection is invalid, Error: [%w]", models.HandleErrSlice(errs))
+			beego.Error(outErr)
+			return schedulingOutput{}, outErr, http.StatusBadRequest
+		}
 	}
 
-	// make the asmodel.Application structure as the input of Schedule function
-	appsForScheduling, err := asmodel.GenerateApplications(apps)
+	cloudsForScheduling, appsForScheduling, evictables, err, statusCode := generateSchedulingInput(apps, runningDeps, preempt)
 	if err != nil {
-		outErr := fmt.Errorf("Generate input applications for auto-scheduling, Error: [%w]", err)
-		beego.Error(outErr)
-		return []models.AppInfo{}, outErr, http.StatusInternalServerError
+		return schedulingOutput{}, err, statusCode
 	}
+
+	// every replica of an application needs its own placement, so we schedule them as separate applications.
+	expandedApps := asmodel.ExpandReplicas(appsForScheduling)
+
 	// In some steps of scheduling, we need a fixed order of applications.
-	appsOrder := algorithms.GenerateAppsOrder(appsForScheduling)
+	appsOrder := algorithms.GenerateAppsOrder(expandedApps)
 
 	// Whether this order is fixed or random does not affect the performance of algorithms, because the applications are generated randomly, which will not be changed by a fixed order. However, when we fix the order here, the comparison between different algorithms can have the same input, because apps order is one input parameter.
 	sort.Strings(appsOrder)
 
-	// the parameters for genetic algorithms
-	var chromosomesCount int = 200
-	var iterationCount int = 5000
-	var crossoverProbability float64 = 0.7
-	var mutationProbability float64 = 0.019
-	var stopNoUpdateIteration int = 200
-
-	// call the Scheduling method according to the input parameter "algo"
-
-	// create algorithm instances, and put them in a map
-	mcssgaInstance := algorithms.NewMcssga(chromosomesCount, iterationCount, crossoverProbability, mutationProbability, stopNoUpdateIteration, exTimeOneCpu)
-	var allAlgos map[string]algorithms.SchedulingAlgorithm = make(map[string]algorithms.SchedulingAlgorithm)
-	allAlgos[algorithms.McssgaName] = mcssgaInstance
-	allAlgos[algorithms.CompRandName] = algorithms.NewCompRand()
-	allAlgos[algorithms.BERandName] = algorithms.NewBERand()
-	allAlgos[algorithms.AmpgaName] = algorithms.NewAmpga(chromosomesCount, iterationCount, crossoverProbability, mutationProbability, stopNoUpdateIteration)
-	allAlgos[algorithms.AmagaName] = algorithms.NewAmaga(chromosomesCount, iterationCount, crossoverProbability, mutationProbability, stopNoUpdateIteration)
-	allAlgos[algorithms.DiktyogaName] = algorithms.NewDiktyoga(chromosomesCount, iterationCount, crossoverProbability, mutationProbability, stopNoUpdateIteration)
-
-	// select the algorithm to use according to the input parameter algoName
-	beego.Info(fmt.Sprintf("Looking for the algorithm \"%s\".", algoName))
-	var algoToUse algorithms.SchedulingAlgorithm
-	var algoNameToUse string = algoName
-	if algo, exist := allAlgos[algoName]; exist {
-		beego.Info(fmt.Sprintf("Algorithm \"%s\" is found.", algoName))
-		algoToUse = algo
-	} else { // if we cannot find the input algoName, we use MCASSGA algorithm by default.
-		algoNameToUse = algorithms.McssgaName
-		beego.Info(fmt.Sprintf("Algorithm \"%s\" is not found, so we use \"%s\" by default.", algoName, algoNameToUse))
-		algoToUse = mcssgaInstance
-	}
-
-	solution, err := algoToUse.Schedule(cloudsForScheduling, appsForScheduling, appsOrder)
+	beego.Info(fmt.Sprintf("Schedule with the seed [%d].", seed))
+	solution, err := algoToUse.Schedule(cloudsForScheduling, expandedApps, appsOrder)
 	if err != nil {
 		outErr := fmt.Errorf("Run the Schedule method of %s, Error: [%w]", algoNameToUse, err)
 		beego.Error(outErr)
-		return []models.AppInfo{}, outErr, http.StatusInternalServerError
+		return schedulingOutput{}, outErr, http.StatusInternalServerError
+	}
+	if err := schedulingCancelled(ctx); err != nil {
+		beego.Error(err)
+		return schedulingOutput{}, err, http.StatusConflict
 	}
 
-	// If we did not use Mcssga to schedule apps, now its max rtt has not been set, so we should set it now to calculate the fitness value in the following log.
+	// If we did not use Mcssga to schedule apps, now its max rtt has not been set, so we should set it now to calculate the fitness value.
 	mcssgaInstance.SetMaxReaRtt(cloudsForScheduling)
-	mcssgaInstance.SetAvgDepNum(appsForScheduling)
-	beego.Info(fmt.Sprintf("The algorithm works out the solution: %s\nIts fitness value is %g.", models.JsonString(solution), mcssgaInstance.Fitness(cloudsForScheduling, appsForScheduling, solution)))
+	mcssgaInstance.SetAvgDepNum(expandedApps)
+	fitness := mcssgaInstance.Fitness(cloudsForScheduling, expandedApps, solution)
+	beego.Info(fmt.Sprintf("The algorithm works out the solution: %s\nIts fitness value is %g.", models.JsonString(solution), fitness))
+
+	var paretoFront []algorithms.ParetoPoint
+	paretoSelected := -1
+	if paretoAlgo, isPareto := algoToUse.(algorithms.ParetoScheduler); isPareto {
+		paretoFront, paretoSelected = paretoAlgo.ParetoFront()
+	}
+
+	rejectReasons := collapseReplicaReasons(algorithms.ExplainRejections(cloudsForScheduling, expandedApps, solution))
+	for _, appName := range sortedKeys(rejectReasons) {
+		beego.Info(fmt.Sprintf("Application [%s] is rejected, because %s.", appName, rejectReasons[appName]))
+	}
+
+	// With preemption, only the evictable applications in the way of the new applications are evicted.
+	evictedApps := []EvictedApp{}
+	var evictionVms []models.IaasVm
+	if preempt.Enabled() {
+		evicted, keptClouds := decideEvictions(occupyBySolution(cloudsForScheduling, expandedApps, solution), evictables)
+		reschedSoln := asmodel.GenEmptySoln()
+		if preempt.Reschedule && len(evicted) != 0 {
+			reschedSoln, err = rescheduleEvicted(ctx, algoName, exTimeOneCpu, gaParams, pareto, seed, keptClouds, evictables, evicted)
+			if err != nil {
+				outErr := fmt.Errorf("Reschedule the evicted applications, Error: [%w]", err)
+				beego.Error(outErr)
+				return schedulingOutput{}, outErr, http.StatusInternalServerError
+			}
+			evictionVms = reschedSoln.VmsToCreate
+		}
+		evictedApps = generateEvictedApps(evictables, evicted, preempt.Reschedule, reschedSoln)
+		beego.Info(fmt.Sprintf("The running applications to evict are: %s", models.JsonString(evictedApps)))
+	}
+
+	// the VMs for the rescheduled evicted applications are also paid by this request.
+	cost := CostPlan{
+		NewVmsHourly: algorithms.SolutionCost(cloudsForScheduling, solution) + algorithms.SolutionCost(cloudsForScheduling, asmodel.Solution{VmsToCreate: evictionVms}),
+		AppsHourly:   algorithms.AppsCost(cloudsForScheduling, expandedApps, solution),
+		MaxHourly:    maxHourlyCost,
+	}
+	cost.WithinBudget = maxHourlyCost <= 0 || cost.NewVmsHourly <= maxHourlyCost
+	beego.Info(fmt.Sprintf("The cost of the solution is %s.", models.JsonString(cost)))
 
 	//// This part is for debug ----------------------------
 	//
 	//// draw evolution chart
-	//if mcssgaAlgo, ok := algoToUse.(*algorithms.Mcssga); ok {
-	//	mcssgaAlgo.DrawEvoChart()
-	//}
 	//switch realAlgo := algoToUse.(type) {
 	//case *algorithms.Mcssga:
 	//	realAlgo.DrawEvoChart()
@@ -104,62 +429,85 @@ func CreateAutoScheduleApps(apps []models.K8sApp, algoName string, exTimeOneCpu
 	//	realAlgo.DrawEvoChart()
 	//default:
 	//}
-	//
-	//// return accepted names for experiments
-	//var acceptedApps []models.AppInfo
-	//for appName, app := range appsForScheduling {
-	//	if solution.AppsSolution[appName].Accepted {
-	//		acceptedApps = append(acceptedApps, models.AppInfo{AppName: appName, Priority: app.Priority})
-	//	}
-	//}
-	//return acceptedApps, nil, http.StatusCreated
 	//// This part is for debug ----------------------------
 
-	/**
-	TODO:
-	migration: I set a lock, migration and deployment (or multiple deployments) cannot be done at the same time. When doing migration, we skip the resources occupied by the applications to be migrated, and count them as the VM resources. When the resources are not enough, the rolling update may be blocked, because the new pods cannot be created. Maybe I can make a dependency topo-sort to avoid it.
-	I will put the migration into the next paper.
-	*/
+	return schedulingOutput{
+		apps:           appsForScheduling,
+		solution:       solution,
+		fitness:        fitness,
+		algoName:       algoNameToUse,
+		rejectReasons:  rejectReasons,
+		evictables:     evictables,
+		evictedApps:    evictedApps,
+		evictionVms:    evictionVms,
+		paretoFront:    paretoFront,
+		paretoSelected: paretoSelected,
+		cost:           cost,
+	}, nil, http.StatusOK
+}
 
-	// create the VMs and add them to Kubernetes
-	if _, err := models.AddNewVms(solution.VmsToCreate); err != nil {
-		outErr := fmt.Errorf("Add new auto-scheduling VMs, Error: [%w]", err)
+// Generate the input clouds and applications of the scheduling algorithms, according to the current state of the clouds. runningDeps are the running applications that the input applications depend on.
+// With preemption, the resources of the evictable running applications are released in the output clouds, and the evictable applications are also returned.
+func generateSchedulingInput(apps []models.K8sApp, runningDeps map[string][]asmodel.AppLocation, preempt PreemptOptions) (map[string]asmodel.Cloud, map[string]asmodel.Application, map[string]evictableApp, error, int) {
+	// make the asmodel.Cloud structure as the input of Schedule function
+	cloudsForScheduling, err := asmodel.GenerateClouds(models.Clouds)
+	if err != nil {
+		outErr := fmt.Errorf("Generate input clouds for auto-scheduling, Error: [%w]", err)
 		beego.Error(outErr)
-		return []models.AppInfo{}, outErr, http.StatusInternalServerError
+		return nil, nil, nil, outErr, http.StatusInternalServerError
+	}
+	asmodel.SetRunningApps(cloudsForScheduling, runningDeps)
+	if len(runningDeps) != 0 {
+		beego.Info(fmt.Sprintf("The running applications that the input applications depend on are: %s", models.JsonString(runningDeps)))
 	}
 
-	// add the auto-scheduling information into the applications to deploy.
-	appsToDeploy := addScheInfoToApps(apps, solution)
-
-	// deploy applications, and wait for them running.
-	createdAppsInfo, err := models.CreateAppsWait(appsToDeploy)
+	// make the asmodel.Application structure as the input of Schedule function
+	appsForScheduling, err := asmodel.GenerateApplications(apps)
 	if err != nil {
-		outErr := fmt.Errorf("Create auto-scheduling applications [%s], Error: [%w]", models.JsonString(appsToDeploy), err)
+		outErr := fmt.Errorf("Generate input applications for auto-scheduling, Error: [%w]", err)
 		beego.Error(outErr)
-		return []models.AppInfo{}, outErr, http.StatusInternalServerError
+		return nil, nil, nil, outErr, http.StatusInternalServerError
 	}
-
-	return createdAppsInfo, nil, http.StatusCreated
+	// With preemption, we simulate to remove the evictable running applications from the clouds, so that their resources can be used by the new applications.
+	var evictables map[string]evictableApp
+	if preempt.Enabled() {
+		// the new applications and the running applications that they depend on cannot be evicted.
+		keptAppNames := runningAppNameSet(runningDeps)
+		for _, app := range apps {
+			keptAppNames[app.Name] = struct{}{}
+		}
+		evictables, err = getEvictableApps(preempt.BelowPriority, keptAppNames)
+		if err != nil {
+			outErr := fmt.Errorf("Get the evictable running applications, Error: [%w]", err)
+			beego.Error(outErr)
+			return nil, nil, nil, outErr, http.StatusInternalServerError
+		}
+		for _, evictable := range evictables {
+			asmodel.ReleasePodsRes(cloudsForScheduling, evictable.pods)
+		}
+		beego.Info(fmt.Sprintf("With preemption, %d running applications with priorities lower than %d can be evicted.", len(evictables), preempt.BelowPriority))
+	}
+	return cloudsForScheduling, appsForScheduling, evictables, nil, http.StatusOK
 }
 
-// After scheduling applications, we should use this functions to add the scheduling information to applications.
-func addScheInfoToApps(apps []models.K8sApp, scheSoln asmodel.Solution) []models.K8sApp {
-	var appsWithScheInfo []models.K8sApp
-
-	for _, app := range apps {
-		// Remove the rejected applications from the array.
-		if !scheSoln.AppsSolution[app.Name].Accepted {
-			continue
+// The replicas of an application are rejected together, so we only keep one reason for an application.
+func collapseReplicaReasons(reasons map[string]string) map[string]string {
+	collapsed := make(map[string]string)
+	for _, name := range sortedKeys(reasons) {
+		appName, _, _ := asmodel.SplitReplicaName(name)
+		if _, exist := collapsed[appName]; !exist {
+			collapsed[appName] = reasons[name]
 		}
-
-		// add node name
-		app.NodeName = scheSoln.AppsSolution[app.Name].K8sNodeName
-		// configure allocated CPU
-		app.Containers[0].Resources.Requests.CPU = fmt.Sprintf("%.0f", scheSoln.AppsSolution[app.Name].AllocatedCpuCore)
-		app.Containers[0].Resources.Limits.CPU = fmt.Sprintf("%.0f", scheSoln.AppsSolution[app.Name].AllocatedCpuCore)
-
-		appsWithScheInfo = append(appsWithScheInfo, app)
 	}
+	return collapsed
+}
 
-	return appsWithScheInfo
+// get the keys of a map in order, to make logs and outputs definite.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
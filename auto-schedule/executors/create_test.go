@@ -1,11 +1,13 @@
 package executors
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"emcontroller/auto-schedule/algorithms"
 	asmodel "emcontroller/auto-schedule/model"
 	"emcontroller/models"
 )
@@ -629,3 +631,214 @@ func TestInnerAddScheInfoToApps(t *testing.T) {
 		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
 	}
 }
+
+func TestInnerAddScheInfoToMultiReplicaApps(t *testing.T) {
+	genApp := func(name string, replicas int32) models.K8sApp {
+		return models.K8sApp{
+			Name:          name,
+			Priority:      5,
+			AutoScheduled: true,
+			Replicas:      replicas,
+			MinClouds:     2,
+			Containers: []models.K8sContainer{
+				{
+					Name:  name,
+					Image: "nginx",
+					Resources: models.K8sResReq{
+						Limits:   models.K8sResList{Memory: "512Mi", CPU: "4", Storage: "10Gi"},
+						Requests: models.K8sResList{Memory: "512Mi", CPU: "4", Storage: "10Gi"},
+					},
+				},
+			},
+		}
+	}
+
+	apps := []models.K8sApp{genApp("frontend", 3), genApp("backend", 2), genApp("db", 1)}
+	scheSoln := asmodel.Solution{
+		AppsSolution: map[string]asmodel.SingleAppSolution{
+			"frontend#0": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 4},
+			"frontend#1": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node2", AllocatedCpuCore: 2},
+			"frontend#2": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3", AllocatedCpuCore: 3},
+			"backend#0":  {Accepted: false},
+			"backend#1":  {Accepted: false},
+			"db":         {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3", AllocatedCpuCore: 1},
+		},
+	}
+
+	expectedFrontend := genApp("frontend", 3)
+	expectedFrontend.NodeNames = []string{"node1", "node2", "node3"}
+	expectedFrontend.Containers[0].Resources.Requests.CPU = "2" // the least CPU allocated to the replicas
+	expectedFrontend.Containers[0].Resources.Limits.CPU = "2"
+	expectedDb := genApp("db", 1)
+	expectedDb.NodeName = "node3"
+	expectedDb.Containers[0].Resources.Requests.CPU = "1"
+	expectedDb.Containers[0].Resources.Limits.CPU = "1"
+
+	actualResult := addScheInfoToApps(apps, scheSoln)
+	assert.Equal(t, []models.K8sApp{expectedFrontend, expectedDb}, actualResult, "result is not expected")
+}
+
+func TestInnerSplitCpuToContainers(t *testing.T) {
+	genContainers := func(requestCpus ...string) []models.K8sContainer {
+		var containers []models.K8sContainer
+		for i, cpu := range requestCpus {
+			containers = append(containers, models.K8sContainer{
+				Name: fmt.Sprintf("c%d", i),
+				Resources: models.K8sResReq{
+					Limits:   models.K8sResList{CPU: cpu},
+					Requests: models.K8sResList{CPU: cpu},
+				},
+			})
+		}
+		return containers
+	}
+
+	testCases := []struct {
+		name           string
+		containers     []models.K8sContainer
+		allocatedCpu   float64
+		expectedResult []int
+	}{
+		{
+			name:           "case single container",
+			containers:     genContainers("4"),
+			allocatedCpu:   3,
+			expectedResult: []int{3},
+		},
+		{
+			name:           "case as requests",
+			containers:     genContainers("4", "1", "2"),
+			allocatedCpu:   7,
+			expectedResult: []int{4, 1, 2},
+		},
+		{
+			name:           "case proportional",
+			containers:     genContainers("6", "2"),
+			allocatedCpu:   4,
+			expectedResult: []int{3, 1},
+		},
+		{
+			name:           "case largest remainder",
+			containers:     genContainers("3", "3", "2"),
+			allocatedCpu:   5,
+			expectedResult: []int{2, 2, 1},
+		},
+		{
+			name:           "case sidecar gets at least 1 core",
+			containers:     genContainers("8", "1"),
+			allocatedCpu:   4,
+			expectedResult: []int{3, 1},
+		},
+		{
+			name:           "case sidecar without CPU request",
+			containers:     genContainers("2", "0"),
+			allocatedCpu:   2,
+			expectedResult: []int{2, 0},
+		},
+		{
+			name:           "case not enough cores for every container",
+			containers:     genContainers("2", "2", "2"),
+			allocatedCpu:   1,
+			expectedResult: []int{1, 0, 0},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := splitCpuToContainers(testCase.containers, testCase.allocatedCpu)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerSetContainersCpu(t *testing.T) {
+	containers := []models.K8sContainer{
+		{Name: "main", Resources: models.K8sResReq{Limits: models.K8sResList{CPU: "4", Memory: "512Mi"}, Requests: models.K8sResList{CPU: "4", Memory: "512Mi"}}},
+		{Name: "proxy", Resources: models.K8sResReq{Limits: models.K8sResList{CPU: "1", Memory: "64Mi"}, Requests: models.K8sResList{CPU: "1", Memory: "64Mi"}}},
+	}
+	expectedResult := []models.K8sContainer{
+		{Name: "main", Resources: models.K8sResReq{Limits: models.K8sResList{CPU: "2", Memory: "512Mi"}, Requests: models.K8sResList{CPU: "2", Memory: "512Mi"}}},
+		{Name: "proxy", Resources: models.K8sResReq{Limits: models.K8sResList{CPU: "1", Memory: "64Mi"}, Requests: models.K8sResList{CPU: "1", Memory: "64Mi"}}},
+	}
+
+	actualResult := setContainersCpu(containers, 3)
+	assert.Equal(t, expectedResult, actualResult, "result is not expected")
+	assert.Equal(t, "4", containers[0].Resources.Requests.CPU, "the input containers should not be changed")
+}
+
+func TestInnerCollapseReplicaReasons(t *testing.T) {
+	reasons := map[string]string{
+		"frontend#0": "its dependency [backend] is rejected",
+		"frontend#1": "its dependency [backend] is rejected",
+		"backend":    "no cloud is available",
+	}
+	expectedResult := map[string]string{
+		"frontend": "its dependency [backend] is rejected",
+		"backend":  "no cloud is available",
+	}
+	assert.Equal(t, expectedResult, collapseReplicaReasons(reasons), "result is not expected")
+}
+
+func TestInnerSelectAlgorithm(t *testing.T) {
+	testCases := []struct {
+		name             string
+		algoName         string
+		expectedAlgoName string
+		expectedErr      error
+	}{
+		{
+			name:             "case default",
+			algoName:         "",
+			expectedAlgoName: algorithms.McssgaName,
+		},
+		{
+			name:             "case registered",
+			algoName:         algorithms.DiktyogaName,
+			expectedAlgoName: algorithms.DiktyogaName,
+		},
+		{
+			name:        "case unknown",
+			algoName:    "NotExist",
+			expectedErr: algorithms.ErrUnknownAlgorithm,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		algo, algoName, mcssgaInstance, err := selectAlgorithm(context.Background(), testCase.algoName, algorithms.DefaultExpAppCompuTimeOneCpu, algorithms.DefaultGaParams, algorithms.DefaultParetoSelection, 0, algorithms.NewSeed())
+		if testCase.expectedErr != nil {
+			assert.ErrorIs(t, err, testCase.expectedErr, fmt.Sprintf("%s: error is not expected", testCase.name))
+			continue
+		}
+		assert.Nil(t, err, fmt.Sprintf("%s: error is not expected", testCase.name))
+		assert.NotNil(t, algo, fmt.Sprintf("%s: algorithm is nil", testCase.name))
+		assert.NotNil(t, mcssgaInstance, fmt.Sprintf("%s: Mcssga instance is nil", testCase.name))
+		assert.Equal(t, testCase.expectedAlgoName, algoName, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerSchedulingCancelled(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	expiredCtx, cancelExpired := context.WithTimeout(context.Background(), 0)
+	defer cancelExpired()
+
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		expectedErr error
+	}{
+		{name: "case not done", ctx: context.Background(), expectedErr: nil},
+		{name: "case deadline reached", ctx: expiredCtx, expectedErr: nil},
+		{name: "case cancelled", ctx: cancelledCtx, expectedErr: models.ErrTaskCancelled},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		err := schedulingCancelled(testCase.ctx)
+		if testCase.expectedErr != nil {
+			assert.ErrorIs(t, err, testCase.expectedErr, fmt.Sprintf("%s: error is not expected", testCase.name))
+			continue
+		}
+		assert.Nil(t, err, fmt.Sprintf("%s: error is not expected", testCase.name))
+	}
+}
@@ -0,0 +1,454 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/astaxie/beego"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"emcontroller/auto-schedule/algorithms"
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// The result of migrating one application.
+type MigrationResult struct {
+	AppName      string  `json:"appName"`
+	FromCloud    string  `json:"fromCloud"`
+	FromNode     string  `json:"fromNode"`
+	ToCloud      string  `json:"toCloud"`
+	ToNode       string  `json:"toNode"`
+	AllocatedCpu float64 `json:"allocatedCpu"`
+	Migrated     bool    `json:"migrated"`       // whether this application is moved to another Kubernetes node
+	Note         string  `json:"note,omitempty"` // the reason why this application is not migrated, or the error of migration
+}
+
+// MigrateAutoScheduleApps re-schedules all running auto-scheduled applications with the algorithm algoName, and migrates them to the new Kubernetes nodes.
+// We simulate to remove the running applications from the clouds, so that they can be scheduled in the same way as new applications.
+// reporter is used to report the sub-steps and progress when this function runs as a task.
+// ctx is passed to the scheduling algorithm, the same as CreateAutoScheduleApps.
+func MigrateAutoScheduleApps(ctx context.Context, algoName string, exTimeOneCpu float64, gaParams algorithms.GaParams, pareto algorithms.ParetoSelection, seed int64, reporter models.TaskReporter) ([]MigrationResult, error, int) {
+	if errs := algorithms.ValidateGaParams(gaParams); len(errs) != 0 {
+		outErr := fmt.Errorf("The GA parameters are invalid, Error: [%w]", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		return []MigrationResult{}, outErr, http.StatusBadRequest
+	}
+	algoToUse, algoNameToUse, mcssgaInstance, err := selectAlgorithm(ctx, algoName, exTimeOneCpu, gaParams, pareto, 0, seed)
+	if err != nil {
+		outErr := fmt.Errorf("Select the scheduling algorithm, Error: [%w]", err)
+		beego.Error(outErr)
+		return []MigrationResult{}, outErr, http.StatusBadRequest
+	}
+
+	// get the running auto-scheduled applications
+	deploys, appsForScheduling, err := getAppsToMigrate()
+	if err != nil {
+		outErr := fmt.Errorf("Get the applications to migrate, Error: [%w]", err)
+		beego.Error(outErr)
+		return []MigrationResult{}, outErr, http.StatusInternalServerError
+	}
+	if len(appsForScheduling) == 0 {
+		beego.Info("There are no running auto-scheduled applications to migrate.")
+		return []MigrationResult{}, nil, http.StatusOK
+	}
+
+	reporter.Logf("Re-scheduling %d running auto-scheduled applications with the GA parameters %s.", len(appsForScheduling), models.JsonString(gaParams))
+
+	// make the asmodel.Cloud structure as the input of Schedule function
+	cloudsForScheduling, err := asmodel.GenerateClouds(models.Clouds)
+	if err != nil {
+		outErr := fmt.Errorf("Generate input clouds for migration, Error: [%w]", err)
+		beego.Error(outErr)
+		return []MigrationResult{}, outErr, http.StatusInternalServerError
+	}
+
+	// simulate to remove the running applications from the clouds. The clouds with them are kept to check the solution and the order of migrations.
+	occupiedClouds := asmodel.CloudMapCopy(cloudsForScheduling)
+	appPods := make(map[string][]apiv1.Pod)
+	for appName := range appsForScheduling {
+		pods, err := models.ListPods(models.KubernetesNamespace, metav1.ListOptions{LabelSelector: fmt.Sprintf("app=%s", appName)})
+		if err != nil {
+			outErr := fmt.Errorf("List the pods of application [%s], Error: [%w]", appName, err)
+			beego.Error(outErr)
+			return []MigrationResult{}, outErr, http.StatusInternalServerError
+		}
+		appPods[appName] = pods
+		asmodel.ReleasePodsRes(cloudsForScheduling, pods)
+	}
+
+	// the same as creating applications, we use a fixed order of applications.
+	appsOrder := algorithms.GenerateAppsOrder(appsForScheduling)
+	sort.Strings(appsOrder)
+
+	beego.Info(fmt.Sprintf("Schedule with the seed [%d].", seed))
+	solution, err := algoToUse.Schedule(cloudsForScheduling, appsForScheduling, appsOrder)
+	if err != nil {
+		outErr := fmt.Errorf("Run the Schedule method of %s for migration, Error: [%w]", algoNameToUse, err)
+		beego.Error(outErr)
+		return []MigrationResult{}, outErr, http.StatusInternalServerError
+	}
+	if err := schedulingCancelled(ctx); err != nil {
+		beego.Error(err)
+		return []MigrationResult{}, err, http.StatusConflict
+	}
+
+	mcssgaInstance.SetMaxReaRtt(cloudsForScheduling)
+	mcssgaInstance.SetAvgDepNum(appsForScheduling)
+	beego.Info(fmt.Sprintf("The algorithm works out the migration solution: %s\nIts fitness value is %g.", models.JsonString(solution), mcssgaInstance.Fitness(cloudsForScheduling, appsForScheduling, solution)))
+
+	// The applications not moved still use their resources, so the solution of the moved ones should be refined again without these resources.
+	solution, err = keepUnmovedApps(occupiedClouds, appsForScheduling, appsOrder, appPods, currentNodes(deploys), solution)
+	if err != nil {
+		outErr := fmt.Errorf("Keep the applications not moved in place, Error: [%w]", err)
+		beego.Error(outErr)
+		return []MigrationResult{}, outErr, http.StatusUnprocessableEntity
+	}
+
+	results := planMigrations(deploys, cloudsForScheduling, solution)
+	reporter.SetProgress(30)
+
+	// create the VMs and add them to Kubernetes
+	reporter.Logf("Creating %d VMs and adding them to Kubernetes.", len(solution.VmsToCreate))
+	if _, err := models.AddNewVms(solution.VmsToCreate); err != nil {
+		outErr := fmt.Errorf("Add new auto-scheduling VMs for migration, Error: [%w]", err)
+		beego.Error(outErr)
+		return results, outErr, http.StatusInternalServerError
+	}
+
+	// Migrate applications in groups, so that the applications that others depend on are migrated and running first, and an application is only moved to a node after the applications leaving this node have left.
+	// The applications in one group do not depend on each other and fit on their target nodes together, so we migrate them in parallel.
+	resultIdx := make(map[string]int)
+	for i := range results {
+		resultIdx[results[i].AppName] = i
+	}
+	migrationGroups, blocked := orderMigrations(occupiedClouds, appsForScheduling, appPods, results)
+	for _, appName := range blocked {
+		results[resultIdx[appName]].Migrated = false
+		results[resultIdx[appName]].Note = "its target node does not have enough resources until it leaves its current node, or its dependencies cannot be migrated, so it stays where it is"
+		reporter.Logf("Application [%s] cannot be migrated, %s.", appName, results[resultIdx[appName]].Note)
+	}
+
+	reporter.SetProgress(50)
+
+	var errs []error
+	for groupIdx, group := range migrationGroups {
+		reporter.Logf("Migrating the applications of group %d: %v.", groupIdx, group)
+		var wg sync.WaitGroup
+		var errsMu sync.Mutex
+		for _, appName := range group {
+			idx := resultIdx[appName]
+			if !results[idx].Migrated {
+				continue
+			}
+			wg.Add(1)
+			go func(deploy appsv1.Deployment, result *MigrationResult) {
+				defer wg.Done()
+				if err := migrateOneApp(deploy, *result); err != nil {
+					outErr := fmt.Errorf("Migrate application [%s], Error: [%w]", result.AppName, err)
+					beego.Error(outErr)
+					result.Migrated = false
+					result.Note = outErr.Error()
+					errsMu.Lock()
+					errs = append(errs, outErr)
+					errsMu.Unlock()
+				}
+			}(deploys[appName], &results[idx])
+		}
+		wg.Wait()
+		reporter.SetProgress(50 + 50*(groupIdx+1)/len(migrationGroups))
+		// If some applications in this group failed, the applications depending on them should not be migrated.
+		if len(errs) != 0 {
+			break
+		}
+	}
+
+	if len(errs) != 0 {
+		outErr := fmt.Errorf("Migrate applications, Error: [%w]", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		return results, outErr, http.StatusInternalServerError
+	}
+
+	return results, nil, http.StatusOK
+}
+
+// Get the Deployments of all running auto-scheduled applications and the information for scheduling them.
+func getAppsToMigrate() (map[string]appsv1.Deployment, map[string]asmodel.Application, error) {
+	allDeploys, err := models.ListDeployment(models.KubernetesNamespace)
+	if err != nil {
+		outErr := fmt.Errorf("List deployments, Error: [%w]", err)
+		beego.Error(outErr)
+		return nil, nil, outErr
+	}
+
+	deploys := make(map[string]appsv1.Deployment)
+	apps := make(map[string]asmodel.Application)
+	for _, deploy := range allDeploys {
+		// Only the applications deployed by auto-scheduling have the information needed for migration.
+		if deploy.Annotations[models.AutoScheduledAnno] != "true" {
+			continue
+		}
+		if _, exist := deploy.Annotations[models.AutoScheduleInfoAnno]; !exist {
+			beego.Info(fmt.Sprintf("Deployment [%s] does not have the annotation [%s], so we cannot migrate it.", deploy.Name, models.AutoScheduleInfoAnno))
+			continue
+		}
+		app, err := asmodel.GenerateAppFromDeploy(deploy)
+		if err != nil {
+			outErr := fmt.Errorf("Generate application from deployment [%s], Error: [%w]", deploy.Name, err)
+			beego.Error(outErr)
+			return nil, nil, outErr
+		}
+		// Migration moves the only pod of an application to another node, so the applications with multiple replicas are not supported.
+		if app.IsMultiReplica() {
+			beego.Info(fmt.Sprintf("Application [%s] has %d replicas, but only single-replica applications can be migrated, so we do not migrate it.", app.Name, app.Replicas))
+			continue
+		}
+		deploys[app.Name] = deploy
+		apps[app.Name] = app
+	}
+
+	removeMissingDeps(apps)
+	return deploys, apps, nil
+}
+
+// The applications that others depended on may have been deleted, so we remove the dependencies on the applications that are not in the map.
+func removeMissingDeps(apps map[string]asmodel.Application) {
+	for appName, app := range apps {
+		var deps []models.Dependency
+		for _, dep := range app.Dependencies {
+			if _, exist := apps[dep.AppName]; exist {
+				deps = append(deps, dep)
+			} else {
+				beego.Info(fmt.Sprintf("Application [%s] depends on [%s], which is not running, so we ignore this dependency in migration.", appName, dep.AppName))
+			}
+		}
+		app.Dependencies = deps
+		apps[appName] = app
+	}
+}
+
+// the Kubernetes nodes where the applications are running now
+func currentNodes(deploys map[string]appsv1.Deployment) map[string]string {
+	nodes := make(map[string]string)
+	for appName, deploy := range deploys {
+		nodes[appName] = deploy.Spec.Template.Spec.NodeName
+	}
+	return nodes
+}
+
+// The solution is worked out with the resources of all applications to migrate released, but the applications rejected by the algorithm or already on their target nodes are not moved, so they still use their resources.
+// This function keeps these applications in place, and refines the solution of the other applications again on the clouds with their resources. The applications kept in place are the running ones that the others can depend on.
+// occupiedClouds are the clouds before the resources are released. If the other applications cannot be put on their target clouds any more, it returns an error, and nothing should be migrated.
+func keepUnmovedApps(occupiedClouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appsOrder []string, appPods map[string][]apiv1.Pod, fromNodes map[string]string, solution asmodel.Solution) (asmodel.Solution, error) {
+	clouds := asmodel.CloudMapCopy(occupiedClouds)
+	movedApps := make(map[string]asmodel.Application)
+	keptLocations := make(map[string][]asmodel.AppLocation)
+	for appName, app := range apps {
+		appSoln, exist := solution.AppsSolution[appName]
+		if exist && appSoln.Accepted && appSoln.K8sNodeName != fromNodes[appName] {
+			movedApps[appName] = app
+			asmodel.ReleasePodsRes(clouds, appPods[appName])
+			continue
+		}
+		if cloudName := asmodel.FindCloudOfK8sNode(clouds, fromNodes[appName]); len(cloudName) != 0 {
+			keptLocations[appName] = []asmodel.AppLocation{{CloudName: cloudName, K8sNodeName: fromNodes[appName]}}
+		}
+	}
+	if len(movedApps) == len(apps) {
+		return solution, nil
+	}
+	asmodel.SetRunningApps(clouds, keptLocations)
+
+	var movedOrder []string
+	genes := asmodel.GenEmptySoln()
+	for _, appName := range appsOrder {
+		if _, moved := movedApps[appName]; moved {
+			movedOrder = append(movedOrder, appName)
+			genes.AppsSolution[appName] = asmodel.SingleAppSolution{Accepted: true, TargetCloudName: solution.AppsSolution[appName].TargetCloudName}
+		}
+	}
+	refinedSoln, acceptable := algorithms.RefineSoln(clouds, movedApps, movedOrder, genes)
+	if !acceptable {
+		return asmodel.Solution{}, fmt.Errorf("the applications to move cannot be put on their target clouds with the resources of the %d applications not moved", len(apps)-len(movedApps))
+	}
+
+	// the applications kept in place have the same solutions as before, i.e., rejected, or on their current nodes.
+	for appName, appSoln := range solution.AppsSolution {
+		if _, moved := movedApps[appName]; !moved {
+			refinedSoln.AppsSolution[appName] = appSoln
+		}
+	}
+	beego.Info(fmt.Sprintf("With %d applications kept in place, the solution to migrate the others is refined again: %s", len(apps)-len(movedApps), models.JsonString(refinedSoln)))
+	return refinedSoln, nil
+}
+
+// Decide the order to migrate the applications in the results. A rolling update starts the new pod before the old one is deleted, so an application can only be moved to a node that has enough resources before the applications leaving this node have left.
+// Every group has the applications whose dependencies are already migrated and which fit on their target nodes together. After a group, the resources of its applications on their old nodes are released. New VMs are created before migrations, and always have the resources for their applications.
+// occupiedClouds are the clouds with all applications on their current nodes. The applications that cannot be put in any group, e.g., 2 applications swapping their nodes, are returned sorted as blocked.
+func orderMigrations(occupiedClouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, appPods map[string][]apiv1.Pod, results []MigrationResult) ([][]string, []string) {
+	clouds := asmodel.CloudMapCopy(occupiedClouds)
+	pending := make(map[string]MigrationResult)
+	for _, result := range results {
+		if result.Migrated {
+			pending[result.AppName] = result
+		}
+	}
+
+	var groups [][]string
+	for len(pending) != 0 {
+		var group []string
+		for _, appName := range pendingApps(pending) {
+			// the applications that others depend on should be migrated first
+			depPending := false
+			for _, dep := range apps[appName].Dependencies {
+				if _, exist := pending[dep.AppName]; exist {
+					depPending = true
+					break
+				}
+			}
+			if depPending {
+				continue
+			}
+
+			cloudName, nodeIdx, found := findK8sNode(clouds, pending[appName].ToNode)
+			if found {
+				node := &clouds[cloudName].K8sNodes[nodeIdx]
+				res := apps[appName].Resources
+				if node.ResidualResources.CpuCore < pending[appName].AllocatedCpu || node.ResidualResources.Memory < res.Memory || node.ResidualResources.Storage < res.Storage {
+					continue
+				}
+				node.ResidualResources.CpuCore -= pending[appName].AllocatedCpu
+				node.ResidualResources.Memory -= res.Memory
+				node.ResidualResources.Storage -= res.Storage
+			}
+			group = append(group, appName)
+		}
+		if len(group) == 0 {
+			break
+		}
+		for _, appName := range group {
+			asmodel.ReleasePodsRes(clouds, appPods[appName])
+			delete(pending, appName)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, pendingApps(pending)
+}
+
+// the sorted names of the applications waiting for migration
+func pendingApps(pending map[string]MigrationResult) []string {
+	appNames := make([]string, 0, len(pending))
+	for appName := range pending {
+		appNames = append(appNames, appName)
+	}
+	sort.Strings(appNames)
+	return appNames
+}
+
+// Split the CPU cores allocated to an application to the containers of its Deployment in the same way as creating applications (splitCpu), and set them as the requests and limits of the containers.
+// The CPU requested by every container is saved in the auto-scheduling information. The Deployments created before it was saved only have the split CPU, which we scale to the CPU requested by the application instead.
+func setDeployCpu(deploy appsv1.Deployment, allocatedCpu float64) appsv1.Deployment {
+	containers := make([]apiv1.Container, len(deploy.Spec.Template.Spec.Containers))
+	copy(containers, deploy.Spec.Template.Spec.Containers)
+
+	app, err := asmodel.GenerateAppFromDeploy(deploy)
+	requests := app.ContainersCpu
+	if err != nil || len(requests) != len(containers) {
+		requests = make([]float64, len(containers))
+		var totalRequest float64
+		for i, container := range containers {
+			requests[i] = float64(container.Resources.Requests.Cpu().MilliValue()) / 1000
+			totalRequest += requests[i]
+		}
+		if err == nil && totalRequest > 0 {
+			for i := range requests {
+				requests[i] *= app.Resources.CpuCore / totalRequest
+			}
+		}
+	}
+
+	containersCpu := splitCpu(requests, allocatedCpu)
+	for i := range containers {
+		cpuQuantity := *resource.NewQuantity(int64(containersCpu[i]), resource.DecimalSI)
+		containers[i].Resources.Requests = copyResourceList(containers[i].Resources.Requests)
+		containers[i].Resources.Limits = copyResourceList(containers[i].Resources.Limits)
+		containers[i].Resources.Requests[apiv1.ResourceCPU] = cpuQuantity
+		containers[i].Resources.Limits[apiv1.ResourceCPU] = cpuQuantity
+	}
+	deploy.Spec.Template.Spec.Containers = containers
+	return deploy
+}
+
+// copy a ResourceList, so that changing the copy does not change the Deployment of the caller. The copy of nil is an empty ResourceList.
+func copyResourceList(src apiv1.ResourceList) apiv1.ResourceList {
+	dst := make(apiv1.ResourceList)
+	for name, quantity := range src {
+		dst[name] = quantity.DeepCopy()
+	}
+	return dst
+}
+
+// Compare the current places of the applications with the solution, to decide which applications should be migrated.
+func planMigrations(deploys map[string]appsv1.Deployment, clouds map[string]asmodel.Cloud, solution asmodel.Solution) []MigrationResult {
+	var results []MigrationResult
+	for appName, deploy := range deploys {
+		fromNode := deploy.Spec.Template.Spec.NodeName
+		result := MigrationResult{
+			AppName:   appName,
+			FromCloud: asmodel.FindCloudOfK8sNode(clouds, fromNode),
+			FromNode:  fromNode,
+		}
+
+		appSoln, exist := solution.AppsSolution[appName]
+		switch {
+		case !exist || !appSoln.Accepted:
+			result.Note = "rejected by the algorithm, so it stays where it is"
+		case appSoln.K8sNodeName == fromNode:
+			// The pods of an application have anti-affinity with each other, so a rolling update onto the same node would be blocked.
+			result.ToCloud = appSoln.TargetCloudName
+			result.ToNode = appSoln.K8sNodeName
+			result.Note = "already on the target Kubernetes node"
+		default:
+			result.ToCloud = appSoln.TargetCloudName
+			result.ToNode = appSoln.K8sNodeName
+			result.AllocatedCpu = appSoln.AllocatedCpuCore
+			result.Migrated = true
+		}
+		results = append(results, result)
+	}
+
+	// the iteration order of map is random, so we sort the results to make them definite.
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].AppName < results[j].AppName
+	})
+	return results
+}
+
+// Move one application to the target Kubernetes node with a rolling update, and wait for the update finished.
+func migrateOneApp(deploy appsv1.Deployment, result MigrationResult) error {
+	deploy = setDeployCpu(deploy, result.AllocatedCpu)
+	deploy.Spec.Template.Spec.NodeName = result.ToNode
+
+	beego.Info(fmt.Sprintf("Migrate application [%s] from node [%s] to node [%s] with [%g] CPU cores.", result.AppName, result.FromNode, result.ToNode, result.AllocatedCpu))
+	updated, err := models.UpdateDeployment(&deploy)
+	if err != nil {
+		outErr := fmt.Errorf("Update deployment [%s], Error: [%w]", deploy.Name, err)
+		beego.Error(outErr)
+		return outErr
+	}
+
+	if err := models.WaitForDeployUpdated(models.WaitForTimeOut, 10, updated.Namespace, updated.Name, updated.Generation); err != nil {
+		outErr := fmt.Errorf("Wait for deployment [%s] updated, Error: [%w]", deploy.Name, err)
+		beego.Error(outErr)
+		return outErr
+	}
+	beego.Info(fmt.Sprintf("Application [%s] is migrated to node [%s].", result.AppName, result.ToNode))
+	return nil
+}
@@ -0,0 +1,297 @@
+package executors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func TestPlanMigrations(t *testing.T) {
+	deployOnNode := func(nodeName string) appsv1.Deployment {
+		var d appsv1.Deployment
+		d.Spec.Template.Spec = apiv1.PodSpec{NodeName: nodeName}
+		return d
+	}
+	deploys := map[string]appsv1.Deployment{
+		"app1": deployOnNode("node1"),
+		"app2": deployOnNode("node2"),
+		"app3": deployOnNode("node3"),
+	}
+	clouds := map[string]asmodel.Cloud{
+		"cloud1": asmodel.Cloud{Name: "cloud1", K8sNodes: []asmodel.K8sNode{{Name: "node1"}, {Name: "node2"}}},
+		"cloud2": asmodel.Cloud{Name: "cloud2", K8sNodes: []asmodel.K8sNode{{Name: "node3"}}},
+	}
+	solution := asmodel.Solution{
+		AppsSolution: map[string]asmodel.SingleAppSolution{
+			"app1": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3", AllocatedCpuCore: 2},
+			"app2": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node2", AllocatedCpuCore: 1},
+			"app3": {Accepted: false},
+		},
+	}
+
+	expectedResults := []MigrationResult{
+		{AppName: "app1", FromCloud: "cloud1", FromNode: "node1", ToCloud: "cloud2", ToNode: "node3", AllocatedCpu: 2, Migrated: true},
+		{AppName: "app2", FromCloud: "cloud1", FromNode: "node2", ToCloud: "cloud1", ToNode: "node2", Migrated: false, Note: "already on the target Kubernetes node"},
+		{AppName: "app3", FromCloud: "cloud2", FromNode: "node3", Migrated: false, Note: "rejected by the algorithm, so it stays where it is"},
+	}
+	assert.Equal(t, expectedResults, planMigrations(deploys, clouds, solution))
+}
+
+func TestRemoveMissingDeps(t *testing.T) {
+	apps := map[string]asmodel.Application{
+		"app1": {Name: "app1", Dependencies: []models.Dependency{{AppName: "app2"}, {AppName: "deleted"}}},
+		"app2": {Name: "app2", Dependencies: []models.Dependency{{AppName: "deleted"}}},
+	}
+	removeMissingDeps(apps)
+	assert.Equal(t, []models.Dependency{{AppName: "app2"}}, apps["app1"].Dependencies, fmt.Sprintf("app1 dependencies are not expected"))
+	assert.Equal(t, 0, len(apps["app2"].Dependencies), fmt.Sprintf("app2 dependencies are not expected"))
+}
+
+// 2 clouds with the applications of TestInnerKeepUnmovedApps and TestInnerOrderMigrations on their current nodes.
+// app1 is on node1, app2 on node2, and app3 on node3. Every application uses 2 CPU, 2048 Mi memory, and 20 Gi storage.
+func migrateCloudsForTest() (map[string]asmodel.Cloud, map[string][]apiv1.Pod) {
+	clouds := map[string]asmodel.Cloud{
+		"cloud1": {
+			Name: "cloud1",
+			K8sNodes: []asmodel.K8sNode{
+				{Name: "node1", ResidualResources: asmodel.GenericResources{CpuCore: 1, Memory: 1024, Storage: 10}},
+				{Name: "node2", ResidualResources: asmodel.GenericResources{CpuCore: 1, Memory: 1024, Storage: 10}},
+			},
+		},
+		"cloud2": {
+			Name: "cloud2",
+			K8sNodes: []asmodel.K8sNode{
+				{Name: "node3", ResidualResources: asmodel.GenericResources{CpuCore: 1, Memory: 1024, Storage: 10}},
+				{Name: "node4", ResidualResources: asmodel.GenericResources{CpuCore: 3, Memory: 3072, Storage: 30}},
+			},
+		},
+	}
+	appPods := map[string][]apiv1.Pod{
+		"app1": {podForTest("node1", "2", "2048", "20")},
+		"app2": {podForTest("node2", "2", "2048", "20")},
+		"app3": {podForTest("node3", "2", "2048", "20")},
+	}
+	return clouds, appPods
+}
+
+func migrateAppsForTest() map[string]asmodel.Application {
+	res := asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 2, Memory: 2048, Storage: 20}}
+	return map[string]asmodel.Application{
+		"app1": {Name: "app1", Priority: 5, Resources: res},
+		"app2": {Name: "app2", Priority: 5, Resources: res},
+		"app3": {Name: "app3", Priority: 5, Resources: res, Dependencies: []models.Dependency{{AppName: "app1"}}},
+	}
+}
+
+func TestInnerKeepUnmovedApps(t *testing.T) {
+	fromNodes := map[string]string{"app1": "node1", "app2": "node2", "app3": "node3"}
+	appsOrder := []string{"app1", "app2", "app3"}
+
+	testCases := []struct {
+		name          string
+		solution      asmodel.Solution
+		expectedNodes map[string]string
+		expectedErr   bool
+	}{
+		{
+			name: "case all moved",
+			solution: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"app1": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node2", AllocatedCpuCore: 2},
+				"app2": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 2},
+				"app3": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node4", AllocatedCpuCore: 2},
+			}},
+			expectedNodes: map[string]string{"app1": "node2", "app2": "node1", "app3": "node4"},
+		},
+		{
+			// The algorithm puts app2 on node1 with the released resources of app1, but app1 is rejected and stays on node1, so app2 can only stay on node2. app3 is put on the first node of cloud2 with enough resources.
+			name: "case released resources of rejected application reused",
+			solution: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"app1": {Accepted: false},
+				"app2": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 2},
+				"app3": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node4", AllocatedCpuCore: 2},
+			}},
+			expectedNodes: map[string]string{"app1": "", "app2": "node2", "app3": "node3"},
+		},
+		{
+			// app2 stays on node2, so app1 cannot use its resources.
+			name: "case released resources of unmoved application reused",
+			solution: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"app1": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node2", AllocatedCpuCore: 2},
+				"app2": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node2", AllocatedCpuCore: 2},
+				"app3": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3", AllocatedCpuCore: 2},
+			}},
+			expectedNodes: map[string]string{"app1": "node1", "app2": "node2", "app3": "node3"},
+		},
+		{
+			// app3 is kept on node3, so cloud2 does not have enough resources for app1.
+			name: "case not enough resources",
+			solution: asmodel.Solution{AppsSolution: map[string]asmodel.SingleAppSolution{
+				"app1": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3", AllocatedCpuCore: 2},
+				"app2": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node4", AllocatedCpuCore: 2},
+				"app3": {Accepted: false},
+			}},
+			expectedErr: true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		clouds, appPods := migrateCloudsForTest()
+		apps := migrateAppsForTest()
+		// app3 depends on app1, which would be rejected in the last case, so we remove the dependency there.
+		if testCase.expectedErr {
+			app3 := apps["app3"]
+			app3.Dependencies = nil
+			apps["app3"] = app3
+		}
+		solution, err := keepUnmovedApps(clouds, apps, appsOrder, appPods, fromNodes, testCase.solution)
+		if testCase.expectedErr {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err)
+		nodes := make(map[string]string)
+		for appName, appSoln := range solution.AppsSolution {
+			nodes[appName] = appSoln.K8sNodeName
+		}
+		assert.Equal(t, testCase.expectedNodes, nodes)
+	}
+}
+
+func TestInnerOrderMigrations(t *testing.T) {
+	testCases := []struct {
+		name            string
+		results         []MigrationResult
+		expectedGroups  [][]string
+		expectedBlocked []string
+	}{
+		{
+			name: "case no migrations",
+			results: []MigrationResult{
+				{AppName: "app1", FromNode: "node1", Migrated: false},
+			},
+			expectedGroups:  nil,
+			expectedBlocked: []string{},
+		},
+		{
+			// app1 can only move to node2 after app2 leaves it.
+			name: "case move after the node is left",
+			results: []MigrationResult{
+				{AppName: "app1", FromNode: "node1", ToNode: "node2", AllocatedCpu: 2, Migrated: true},
+				{AppName: "app2", FromNode: "node2", ToNode: "node4", AllocatedCpu: 2, Migrated: true},
+			},
+			expectedGroups:  [][]string{{"app2"}, {"app1"}},
+			expectedBlocked: []string{},
+		},
+		{
+			// app1 takes the memory of node4, so app2 cannot be moved, and app3 depends on app1.
+			name: "case resources and dependencies",
+			results: []MigrationResult{
+				{AppName: "app1", FromNode: "node1", ToNode: "node4", AllocatedCpu: 2, Migrated: true},
+				{AppName: "app2", FromNode: "node2", ToNode: "node4", AllocatedCpu: 1, Migrated: true},
+				{AppName: "app3", FromNode: "node3", ToNode: "node1", AllocatedCpu: 1, Migrated: true},
+			},
+			expectedGroups:  [][]string{{"app1"}, {"app3"}},
+			expectedBlocked: []string{"app2"},
+		},
+		{
+			name: "case swap nodes",
+			results: []MigrationResult{
+				{AppName: "app1", FromNode: "node1", ToNode: "node3", AllocatedCpu: 2, Migrated: true},
+				{AppName: "app3", FromNode: "node3", ToNode: "node1", AllocatedCpu: 2, Migrated: true},
+			},
+			expectedGroups:  nil,
+			expectedBlocked: []string{"app1", "app3"},
+		},
+		{
+			// The new VMs are created before migrations, so they always have enough resources.
+			name: "case new VM",
+			results: []MigrationResult{
+				{AppName: "app1", FromNode: "node1", ToNode: "new-node", AllocatedCpu: 2, Migrated: true},
+				{AppName: "app2", FromNode: "node2", ToNode: "new-node", AllocatedCpu: 2, Migrated: true},
+			},
+			expectedGroups:  [][]string{{"app1", "app2"}},
+			expectedBlocked: []string{},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		clouds, appPods := migrateCloudsForTest()
+		groups, blocked := orderMigrations(clouds, migrateAppsForTest(), appPods, testCase.results)
+		assert.Equal(t, testCase.expectedGroups, groups)
+		assert.Equal(t, testCase.expectedBlocked, blocked)
+	}
+}
+
+func TestInnerSetDeployCpu(t *testing.T) {
+	deployWithContainers := func(info string, containersCpu ...string) appsv1.Deployment {
+		var d appsv1.Deployment
+		d.Annotations = map[string]string{models.AutoScheduledAnno: "true", models.AutoScheduleInfoAnno: info}
+		for i, cpu := range containersCpu {
+			d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, apiv1.Container{
+				Name: fmt.Sprintf("c%d", i),
+				Resources: apiv1.ResourceRequirements{
+					Requests: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse(cpu), apiv1.ResourceMemory: resource.MustParse("64Mi")},
+					Limits:   apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse(cpu)},
+				},
+			})
+		}
+		return d
+	}
+
+	testCases := []struct {
+		name         string
+		deploy       appsv1.Deployment
+		allocatedCpu float64
+		expectedCpu  []int64
+	}{
+		{
+			name:         "case single container",
+			deploy:       deployWithContainers(`{"name":"app1","resources":{"cpuCore":4},"containersCpu":[4]}`, "2"),
+			allocatedCpu: 3,
+			expectedCpu:  []int64{3},
+		},
+		{
+			name:         "case multiple containers split in proportion to requests",
+			deploy:       deployWithContainers(`{"name":"app1","resources":{"cpuCore":6},"containersCpu":[4,2]}`, "2", "1"),
+			allocatedCpu: 6,
+			expectedCpu:  []int64{4, 2},
+		},
+		{
+			name:         "case multiple containers with the largest remainder",
+			deploy:       deployWithContainers(`{"name":"app1","resources":{"cpuCore":6},"containersCpu":[4,2]}`, "4", "2"),
+			allocatedCpu: 2,
+			expectedCpu:  []int64{1, 1},
+		},
+		{
+			name:         "case multiple containers without saved requests",
+			deploy:       deployWithContainers(`{"name":"app1","resources":{"cpuCore":6}}`, "2", "1"),
+			allocatedCpu: 6,
+			expectedCpu:  []int64{4, 2},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		originalCpu := testCase.deploy.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().Value()
+		updated := setDeployCpu(testCase.deploy, testCase.allocatedCpu)
+		var actualRequests, actualLimits []int64
+		for _, container := range updated.Spec.Template.Spec.Containers {
+			actualRequests = append(actualRequests, container.Resources.Requests.Cpu().Value())
+			actualLimits = append(actualLimits, container.Resources.Limits.Cpu().Value())
+			assert.Equal(t, int64(64*1024*1024), container.Resources.Requests.Memory().Value(), fmt.Sprintf("%s: memory should not be changed", testCase.name))
+		}
+		assert.Equal(t, testCase.expectedCpu, actualRequests, fmt.Sprintf("%s: requests are not expected", testCase.name))
+		assert.Equal(t, testCase.expectedCpu, actualLimits, fmt.Sprintf("%s: limits are not expected", testCase.name))
+		// the input Deployment should not be changed
+		assert.Equal(t, originalCpu, testCase.deploy.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().Value(), fmt.Sprintf("%s: input is changed", testCase.name))
+	}
+}
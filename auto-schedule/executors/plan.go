@@ -0,0 +1,138 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/astaxie/beego"
+
+	"emcontroller/auto-schedule/algorithms"
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// The scheduling plan of a group of applications, which is worked out by the algorithm but not executed.
+type SchedulingPlan struct {
+	Algorithm    string              `json:"algorithm"`        // the name of the algorithm actually used
+	Fitness      float64             `json:"fitness"`          // the fitness value of the solution calculated by Mcssga
+	GaParams     algorithms.GaParams `json:"gaParams"`         // the parameters used if the algorithm is a genetic algorithm
+	Seed         int64               `json:"seed"`             // the seed of the random generator, with which the same request on the same clouds gives the same plan
+	AcceptedApps []AppPlan           `json:"acceptedApps"`     // where the accepted applications will be deployed
+	RejectedApps []RejectedApp       `json:"rejectedApps"`     // the rejected applications and the reasons
+	VmsToCreate  []models.IaasVm     `json:"vmsToCreate"`      // the VMs that will be created for the accepted applications
+	EvictedApps  []EvictedApp        `json:"evictedApps"`      // the running applications that will be evicted, only with preemption
+	Solution     asmodel.Solution    `json:"solution"`         // the original solution worked out by the algorithm
+	Cost         CostPlan            `json:"cost"`             // the cost per hour, only counting the clouds with pricing
+	ParetoPlan   *ParetoPlan         `json:"pareto,omitempty"` // only with multi-objective algorithms, e.g., Nsga2
+}
+
+// The Pareto front worked out by a multi-objective algorithm, and the solution picked from it.
+type ParetoPlan struct {
+	Selection algorithms.ParetoSelection `json:"selection"` // the policy to pick the solution
+	Selected  int                        `json:"selected"`  // the index of the picked solution in Front, which is the same as Solution of the plan
+	Front     []algorithms.ParetoPoint   `json:"front"`
+}
+
+// The cost per hour of a scheduling plan. The prices are set by "pricing" of the clouds in iaas.json.
+type CostPlan struct {
+	NewVmsHourly float64 `json:"newVmsHourly"` // the VMs to create, which is what this request adds to the spend, and is limited by the budget
+	AppsHourly   float64 `json:"appsHourly"`   // the resources requested by the accepted applications on existing and new VMs, which is what the applications cost
+	MaxHourly    float64 `json:"maxHourly"`    // the budget of the request for the new VMs, 0 means no limit
+	WithinBudget bool    `json:"withinBudget"`
+}
+
+// The scheduling plan of one accepted application.
+type AppPlan struct {
+	AppName      string  `json:"appName"`
+	Replica      int     `json:"replica"` // the index of the replica, for multi-replica applications, every replica has its own plan
+	TargetCloud  string  `json:"targetCloud"`
+	K8sNodeName  string  `json:"k8sNodeName"`
+	AllocatedCpu float64 `json:"allocatedCpu"`
+}
+
+// A rejected application and the reason, e.g., which dependency requirement cannot be met.
+type RejectedApp struct {
+	AppName string `json:"appName"`
+	Reason  string `json:"reason"`
+}
+
+// PlanAutoScheduleApps is a dry run of CreateAutoScheduleApps. It schedules the applications and returns the plan, but it does not create any VMs or applications.
+func PlanAutoScheduleApps(ctx context.Context, apps []models.K8sApp, algoName string, exTimeOneCpu float64, gaParams algorithms.GaParams, pareto algorithms.ParetoSelection, maxHourlyCost float64, seed int64, preempt PreemptOptions) (SchedulingPlan, error, int) {
+	scheOut, err, statusCode := scheduleApps(ctx, apps, algoName, exTimeOneCpu, gaParams, pareto, maxHourlyCost, seed, preempt)
+	if err != nil {
+		outErr := fmt.Errorf("Schedule applications, Error: [%w]", err)
+		beego.Error(outErr)
+		return SchedulingPlan{}, outErr, statusCode
+	}
+
+	plan := generatePlan(scheOut.solution, scheOut.fitness, scheOut.algoName, scheOut.rejectReasons)
+	plan.GaParams = gaParams
+	plan.Seed = seed
+	plan.EvictedApps = scheOut.evictedApps
+	plan.Cost = scheOut.cost
+	if len(scheOut.paretoFront) != 0 {
+		plan.ParetoPlan = &ParetoPlan{
+			Selection: pareto,
+			Selected:  scheOut.paretoSelected,
+			Front:     scheOut.paretoFront,
+		}
+	}
+	if len(scheOut.evictionVms) != 0 {
+		plan.VmsToCreate = append(append([]models.IaasVm{}, plan.VmsToCreate...), scheOut.evictionVms...)
+	}
+	return plan, nil, http.StatusOK
+}
+
+// convert a solution to the plan to show to users. rejectReasons is the output of algorithms.ExplainRejections.
+func generatePlan(solution asmodel.Solution, fitness float64, algoName string, rejectReasons map[string]string) SchedulingPlan {
+	plan := SchedulingPlan{
+		Algorithm:    algoName,
+		Fitness:      fitness,
+		AcceptedApps: []AppPlan{},
+		RejectedApps: []RejectedApp{},
+		VmsToCreate:  solution.VmsToCreate,
+		EvictedApps:  []EvictedApp{},
+		Solution:     solution,
+	}
+	if plan.VmsToCreate == nil {
+		plan.VmsToCreate = []models.IaasVm{}
+	}
+
+	rejectedAppNames := make(map[string]struct{})
+	for name, appSoln := range solution.AppsSolution {
+		// the names in the solution can be the names of replicas
+		appName, replicaIdx, _ := asmodel.SplitReplicaName(name)
+		if !appSoln.Accepted {
+			// the replicas of an application are rejected together, so we only show the application once.
+			if _, exist := rejectedAppNames[appName]; !exist {
+				rejectedAppNames[appName] = struct{}{}
+				plan.RejectedApps = append(plan.RejectedApps, RejectedApp{
+					AppName: appName,
+					Reason:  rejectReasons[appName],
+				})
+			}
+			continue
+		}
+		plan.AcceptedApps = append(plan.AcceptedApps, AppPlan{
+			AppName:      appName,
+			Replica:      replicaIdx,
+			TargetCloud:  appSoln.TargetCloudName,
+			K8sNodeName:  appSoln.K8sNodeName,
+			AllocatedCpu: appSoln.AllocatedCpuCore,
+		})
+	}
+
+	// the iteration order of map is random, so we sort the applications to make the plan definite.
+	sort.Slice(plan.AcceptedApps, func(i, j int) bool {
+		if plan.AcceptedApps[i].AppName != plan.AcceptedApps[j].AppName {
+			return plan.AcceptedApps[i].AppName < plan.AcceptedApps[j].AppName
+		}
+		return plan.AcceptedApps[i].Replica < plan.AcceptedApps[j].Replica
+	})
+	sort.Slice(plan.RejectedApps, func(i, j int) bool {
+		return plan.RejectedApps[i].AppName < plan.RejectedApps[j].AppName
+	})
+	return plan
+}
@@ -0,0 +1,114 @@
+package executors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func TestGeneratePlan(t *testing.T) {
+	testCases := []struct {
+		name           string
+		solution       asmodel.Solution
+		fitness        float64
+		algoName       string
+		rejectReasons  map[string]string
+		expectedResult SchedulingPlan
+	}{
+		{
+			name: "case accepted and rejected",
+			solution: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app2": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "auto-sched-cloud1-0", AllocatedCpuCore: 2},
+					"app1": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node1", AllocatedCpuCore: 1},
+					"app3": {Accepted: false},
+				},
+				VmsToCreate: []models.IaasVm{
+					{Name: "auto-sched-cloud1-0", Cloud: "cloud1", VCpu: 4, Ram: 8192, Storage: 100},
+				},
+			},
+			fitness:  0.5,
+			algoName: "Mcssga",
+			rejectReasons: map[string]string{
+				"app3": "dependency requirements cannot be met",
+			},
+			expectedResult: SchedulingPlan{
+				Algorithm: "Mcssga",
+				Fitness:   0.5,
+				AcceptedApps: []AppPlan{
+					{AppName: "app1", TargetCloud: "cloud2", K8sNodeName: "node1", AllocatedCpu: 1},
+					{AppName: "app2", TargetCloud: "cloud1", K8sNodeName: "auto-sched-cloud1-0", AllocatedCpu: 2},
+				},
+				RejectedApps: []RejectedApp{
+					{AppName: "app3", Reason: "dependency requirements cannot be met"},
+				},
+				VmsToCreate: []models.IaasVm{
+					{Name: "auto-sched-cloud1-0", Cloud: "cloud1", VCpu: 4, Ram: 8192, Storage: 100},
+				},
+				EvictedApps: []EvictedApp{},
+			},
+		},
+		{
+			name: "case multi-replica applications",
+			solution: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"frontend#1": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node2", AllocatedCpuCore: 1},
+					"frontend#0": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 2},
+					"backend#0":  {Accepted: false},
+					"backend#1":  {Accepted: false},
+				},
+			},
+			fitness:  0.3,
+			algoName: "Mcssga",
+			rejectReasons: map[string]string{
+				"backend": "its replicas need at least 3 clouds, but only 2 clouds are available",
+			},
+			expectedResult: SchedulingPlan{
+				Algorithm: "Mcssga",
+				Fitness:   0.3,
+				AcceptedApps: []AppPlan{
+					{AppName: "frontend", Replica: 0, TargetCloud: "cloud1", K8sNodeName: "node1", AllocatedCpu: 2},
+					{AppName: "frontend", Replica: 1, TargetCloud: "cloud2", K8sNodeName: "node2", AllocatedCpu: 1},
+				},
+				RejectedApps: []RejectedApp{
+					{AppName: "backend", Reason: "its replicas need at least 3 clouds, but only 2 clouds are available"},
+				},
+				VmsToCreate: []models.IaasVm{},
+				EvictedApps: []EvictedApp{},
+			},
+		},
+		{
+			name: "case all rejected",
+			solution: asmodel.Solution{
+				AppsSolution: map[string]asmodel.SingleAppSolution{
+					"app1": {Accepted: false},
+				},
+			},
+			fitness:  0,
+			algoName: "BERand",
+			rejectReasons: map[string]string{
+				"app1": "no cloud is available",
+			},
+			expectedResult: SchedulingPlan{
+				Algorithm:    "BERand",
+				Fitness:      0,
+				AcceptedApps: []AppPlan{},
+				RejectedApps: []RejectedApp{
+					{AppName: "app1", Reason: "no cloud is available"},
+				},
+				VmsToCreate: []models.IaasVm{},
+				EvictedApps: []EvictedApp{},
+			},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		testCase.expectedResult.Solution = testCase.solution
+		actualResult := generatePlan(testCase.solution, testCase.fitness, testCase.algoName, testCase.rejectReasons)
+		assert.Equal(t, testCase.expectedResult, actualResult, testCase.name)
+	}
+}
@@ -0,0 +1,341 @@
+package executors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"emcontroller/auto-schedule/algorithms"
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// PreemptOptions is the opt-in preemption mode of auto-scheduling.
+// With preemption, the running auto-scheduled applications with priorities lower than BelowPriority are considered as evictable capacity when scheduling new applications. After scheduling, only the running applications that are in the way of the new applications are evicted.
+type PreemptOptions struct {
+	BelowPriority int  // 0 means that preemption is disabled
+	Reschedule    bool // whether to try to move the evicted applications to other places instead of only deleting them
+}
+
+func (o PreemptOptions) Enabled() bool {
+	return o.BelowPriority > 0
+}
+
+// ValidatePreemptOptions checks whether the preemption options can be used. The applications with the max priority can never be evicted.
+func ValidatePreemptOptions(opts PreemptOptions) []error {
+	var allErrs []error
+	if opts.Enabled() && (opts.BelowPriority <= asmodel.MinPriority || opts.BelowPriority > asmodel.MaxPriority) {
+		allErrs = append(allErrs, fmt.Errorf("the priority below which applications can be evicted should be in [%d, %d], but it is [%d]", asmodel.MinPriority+1, asmodel.MaxPriority, opts.BelowPriority))
+	}
+	if !opts.Enabled() && opts.Reschedule {
+		allErrs = append(allErrs, fmt.Errorf("rescheduling evicted applications needs preemption to be enabled"))
+	}
+	return allErrs
+}
+
+// A running application that is evicted to make room for new applications.
+type EvictedApp struct {
+	AppName      string   `json:"appName"`
+	Priority     int      `json:"priority"`
+	FromNodes    []string `json:"fromNodes"`              // the Kubernetes nodes where its pods are running
+	Rescheduled  bool     `json:"rescheduled"`            // whether it is moved to another place rather than deleted
+	ToCloud      string   `json:"toCloud,omitempty"`      // only for rescheduled applications
+	ToNode       string   `json:"toNode,omitempty"`       // only for rescheduled applications
+	AllocatedCpu float64  `json:"allocatedCpu,omitempty"` // only for rescheduled applications
+	Note         string   `json:"note,omitempty"`         // the reason why it is not rescheduled
+}
+
+// A running auto-scheduled application that can be evicted.
+type evictableApp struct {
+	app     asmodel.Application
+	hasInfo bool // whether the information needed for scheduling (models.AutoScheduleInfoAnno) is in its Deployment, without which it cannot be rescheduled
+	deploy  appsv1.Deployment
+	pods    []apiv1.Pod
+}
+
+// Get the running auto-scheduled applications with priorities lower than belowPriority. The applications in keptAppNames, e.g., the ones in the request, are not included.
+func getEvictableApps(belowPriority int, keptAppNames map[string]struct{}) (map[string]evictableApp, error) {
+	allDeploys, err := models.ListDeployment(models.KubernetesNamespace)
+	if err != nil {
+		outErr := fmt.Errorf("List deployments, Error: [%w]", err)
+		beego.Error(outErr)
+		return nil, outErr
+	}
+
+	evictables := make(map[string]evictableApp)
+	for _, deploy := range allDeploys {
+		if deploy.Annotations[models.AutoScheduledAnno] != "true" {
+			continue
+		}
+		priority, err := strconv.Atoi(deploy.Annotations[models.PriorityAnno])
+		if err != nil || priority >= belowPriority {
+			continue
+		}
+		appName := strings.TrimSuffix(deploy.Name, models.DeploymentSuffix)
+		if _, exist := keptAppNames[appName]; exist {
+			continue
+		}
+
+		thisEvictable := evictableApp{
+			app:    asmodel.Application{Name: appName, Priority: priority},
+			deploy: deploy,
+		}
+		if _, exist := deploy.Annotations[models.AutoScheduleInfoAnno]; exist {
+			if app, err := asmodel.GenerateAppFromDeploy(deploy); err == nil {
+				thisEvictable.app = app
+				thisEvictable.hasInfo = true
+			}
+		}
+
+		pods, err := models.ListPods(models.KubernetesNamespace, metav1.ListOptions{LabelSelector: fmt.Sprintf("app=%s", appName)})
+		if err != nil {
+			outErr := fmt.Errorf("List the pods of application [%s], Error: [%w]", appName, err)
+			beego.Error(outErr)
+			return nil, outErr
+		}
+		thisEvictable.pods = pods
+
+		evictables[appName] = thisEvictable
+	}
+	return evictables, nil
+}
+
+// Simulate to put the accepted applications of a solution on the clouds, i.e., subtract their resources from the Kubernetes nodes, and add the VMs to create to the clouds. It returns new clouds and does not change the input ones.
+func occupyBySolution(clouds map[string]asmodel.Cloud, apps map[string]asmodel.Application, solution asmodel.Solution) map[string]asmodel.Cloud {
+	occupied := asmodel.CloudMapCopy(clouds)
+
+	appsOnNewVms := make(map[string][]string)
+	for appName, appSoln := range solution.AppsSolution {
+		if !appSoln.Accepted {
+			continue
+		}
+		cloudName, nodeIdx, found := findK8sNode(occupied, appSoln.K8sNodeName)
+		if !found {
+			appsOnNewVms[appSoln.K8sNodeName] = append(appsOnNewVms[appSoln.K8sNodeName], appName)
+			continue
+		}
+		node := &occupied[cloudName].K8sNodes[nodeIdx]
+		node.ResidualResources.CpuCore -= appSoln.AllocatedCpuCore
+		node.ResidualResources.Memory -= apps[appName].Resources.Memory
+		node.ResidualResources.Storage -= apps[appName].Resources.Storage
+	}
+
+	for _, vm := range solution.VmsToCreate {
+		cloud := occupied[vm.Cloud]
+		cloud.Resources.InUse.VCpu += vm.VCpu
+		cloud.Resources.InUse.Ram += vm.Ram
+		cloud.Resources.InUse.Storage += vm.Storage
+		sort.Strings(appsOnNewVms[vm.Name])
+		cloud.K8sNodes = append(cloud.K8sNodes, asmodel.GenK8sNodeFromApps(vm, apps, appsOnNewVms[vm.Name]))
+		occupied[vm.Cloud] = cloud
+	}
+
+	return occupied
+}
+
+// Decide which evictable applications should be evicted, after the new applications are put on the clouds (see occupyBySolution).
+// We put the evictable applications back to their nodes in the order of priority from high to low, and the ones that cannot be put back are evicted. All pods of an application are put back or evicted together.
+// It returns the sorted names of the evicted applications, and the clouds with the applications not evicted.
+func decideEvictions(occupiedClouds map[string]asmodel.Cloud, evictables map[string]evictableApp) ([]string, map[string]asmodel.Cloud) {
+	clouds := asmodel.CloudMapCopy(occupiedClouds)
+
+	names := make([]string, 0, len(evictables))
+	for name := range evictables {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := evictables[names[i]].app.Priority, evictables[names[j]].app.Priority
+		if pi != pj {
+			return pi > pj
+		}
+		return names[i] < names[j]
+	})
+
+	var evicted []string
+	for _, name := range names {
+		// the resources needed by the pods of this application on every node
+		needed := make(map[string]asmodel.GenericResources)
+		for _, pod := range evictables[name].pods {
+			if len(pod.Spec.NodeName) == 0 {
+				continue
+			}
+			occupied := asmodel.GetResOccupiedByPod(pod)
+			res := needed[pod.Spec.NodeName]
+			res.CpuCore += occupied.CpuCore
+			res.Memory += occupied.Memory
+			res.Storage += occupied.Storage
+			needed[pod.Spec.NodeName] = res
+		}
+
+		fits := true
+		for nodeName, res := range needed {
+			cloudName, nodeIdx, found := findK8sNode(clouds, nodeName)
+			if !found {
+				continue // the node is not used by auto-scheduling, so the new applications are not there.
+			}
+			residual := clouds[cloudName].K8sNodes[nodeIdx].ResidualResources
+			if residual.CpuCore < res.CpuCore || residual.Memory < res.Memory || residual.Storage < res.Storage {
+				fits = false
+				break
+			}
+		}
+		if !fits {
+			evicted = append(evicted, name)
+			continue
+		}
+		for nodeName, res := range needed {
+			cloudName, nodeIdx, found := findK8sNode(clouds, nodeName)
+			if !found {
+				continue
+			}
+			node := &clouds[cloudName].K8sNodes[nodeIdx]
+			node.ResidualResources.CpuCore -= res.CpuCore
+			node.ResidualResources.Memory -= res.Memory
+			node.ResidualResources.Storage -= res.Storage
+		}
+	}
+
+	sort.Strings(evicted)
+	return evicted, clouds
+}
+
+// Try to schedule the evicted applications on the clouds where the new applications and the not evicted applications are already put.
+// The same as migration, only the single-replica applications with the information for scheduling can be rescheduled, and the dependencies on the applications not rescheduled are ignored.
+func rescheduleEvicted(ctx context.Context, algoName string, exTimeOneCpu float64, gaParams algorithms.GaParams, pareto algorithms.ParetoSelection, seed int64, clouds map[string]asmodel.Cloud, evictables map[string]evictableApp, evicted []string) (asmodel.Solution, error) {
+	apps := make(map[string]asmodel.Application)
+	for _, name := range evicted {
+		evictable := evictables[name]
+		if !evictable.hasInfo || evictable.app.IsMultiReplica() {
+			continue
+		}
+		apps[name] = asmodel.AppCopy(evictable.app)
+	}
+	if len(apps) == 0 {
+		return asmodel.GenEmptySoln(), nil
+	}
+	removeMissingDeps(apps)
+
+	// Algorithms like genetic algorithms have states, so we use a new instance.
+	algoToUse, algoNameToUse, _, err := selectAlgorithm(ctx, algoName, exTimeOneCpu, gaParams, pareto, 0, seed)
+	if err != nil {
+		outErr := fmt.Errorf("Select the scheduling algorithm to reschedule the evicted applications, Error: [%w]", err)
+		beego.Error(outErr)
+		return asmodel.Solution{}, outErr
+	}
+
+	appsOrder := algorithms.GenerateAppsOrder(apps)
+	sort.Strings(appsOrder)
+
+	solution, err := algoToUse.Schedule(clouds, apps, appsOrder)
+	if err != nil {
+		outErr := fmt.Errorf("Run the Schedule method of %s to reschedule the evicted applications, Error: [%w]", algoNameToUse, err)
+		beego.Error(outErr)
+		return asmodel.Solution{}, outErr
+	}
+	beego.Info(fmt.Sprintf("The algorithm works out the solution to reschedule the evicted applications: %s", models.JsonString(solution)))
+	return solution, nil
+}
+
+// generate the information of the evicted applications to show to users. reschedSoln is the output of rescheduleEvicted.
+func generateEvictedApps(evictables map[string]evictableApp, evicted []string, reschedule bool, reschedSoln asmodel.Solution) []EvictedApp {
+	evictedApps := []EvictedApp{}
+	for _, name := range evicted {
+		evictable := evictables[name]
+		evictedApp := EvictedApp{
+			AppName:   name,
+			Priority:  evictable.app.Priority,
+			FromNodes: podNodeNames(evictable.pods),
+		}
+
+		appSoln, scheduled := reschedSoln.AppsSolution[name]
+		switch {
+		case !reschedule:
+		case !evictable.hasInfo:
+			evictedApp.Note = fmt.Sprintf("it cannot be rescheduled without the annotation [%s], so it will be deleted", models.AutoScheduleInfoAnno)
+		case evictable.app.IsMultiReplica():
+			evictedApp.Note = "only single-replica applications can be rescheduled, so it will be deleted"
+		case !scheduled || !appSoln.Accepted:
+			evictedApp.Note = "rejected by the algorithm when rescheduling, so it will be deleted"
+		default:
+			evictedApp.Rescheduled = true
+			evictedApp.ToCloud = appSoln.TargetCloudName
+			evictedApp.ToNode = appSoln.K8sNodeName
+			evictedApp.AllocatedCpu = appSoln.AllocatedCpuCore
+		}
+		evictedApps = append(evictedApps, evictedApp)
+	}
+	return evictedApps
+}
+
+// Evict the applications to make room for new applications. The rescheduled ones are moved in the same way as migration, and the others are deleted.
+// If moving an application fails, we delete it, because the new applications need its resources.
+func evictApps(evictedApps []EvictedApp, evictables map[string]evictableApp, reporter models.TaskReporter) error {
+	var errs []error
+	for _, evictedApp := range evictedApps {
+		if evictedApp.Rescheduled {
+			reporter.Logf("Evicting application [%s] by moving it to node [%s].", evictedApp.AppName, evictedApp.ToNode)
+			fromNode := ""
+			if len(evictedApp.FromNodes) > 0 {
+				fromNode = evictedApp.FromNodes[0]
+			}
+			err := migrateOneApp(evictables[evictedApp.AppName].deploy, MigrationResult{
+				AppName:      evictedApp.AppName,
+				FromNode:     fromNode,
+				ToCloud:      evictedApp.ToCloud,
+				ToNode:       evictedApp.ToNode,
+				AllocatedCpu: evictedApp.AllocatedCpu,
+			})
+			if err == nil {
+				continue
+			}
+			beego.Error(fmt.Sprintf("Move the evicted application [%s], Error: [%s]. We delete it instead.", evictedApp.AppName, err.Error()))
+		}
+
+		reporter.Logf("Evicting application [%s] by deleting it.", evictedApp.AppName)
+		if err, _ := models.DeleteApplication(evictedApp.AppName); err != nil {
+			outErr := fmt.Errorf("Delete the evicted application [%s], Error: [%w]", evictedApp.AppName, err)
+			beego.Error(outErr)
+			errs = append(errs, outErr)
+		}
+	}
+
+	if len(errs) != 0 {
+		return models.HandleErrSlice(errs)
+	}
+	return nil
+}
+
+// find a Kubernetes node in the clouds by its name.
+func findK8sNode(clouds map[string]asmodel.Cloud, nodeName string) (string, int, bool) {
+	for cloudName, cloud := range clouds {
+		for i, node := range cloud.K8sNodes {
+			if node.Name == nodeName {
+				return cloudName, i, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// the sorted names of the nodes where the pods are.
+func podNodeNames(pods []apiv1.Pod) []string {
+	nodeSet := make(map[string]struct{})
+	for _, pod := range pods {
+		if len(pod.Spec.NodeName) != 0 {
+			nodeSet[pod.Spec.NodeName] = struct{}{}
+		}
+	}
+	nodeNames := make([]string, 0, len(nodeSet))
+	for nodeName := range nodeSet {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	return nodeNames
+}
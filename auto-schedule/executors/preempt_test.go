@@ -0,0 +1,248 @@
+package executors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// a pod on a node requesting cpu cores, memory (MiB), and storage (GiB)
+func podForTest(nodeName string, cpu, memory, storage string) apiv1.Pod {
+	return apiv1.Pod{
+		Spec: apiv1.PodSpec{
+			NodeName: nodeName,
+			Containers: []apiv1.Container{{
+				Resources: apiv1.ResourceRequirements{
+					Requests: apiv1.ResourceList{
+						apiv1.ResourceCPU:              resource.MustParse(cpu),
+						apiv1.ResourceMemory:           resource.MustParse(memory + "Mi"),
+						apiv1.ResourceEphemeralStorage: resource.MustParse(storage + "Gi"),
+					},
+				},
+			}},
+		},
+	}
+}
+
+// 2 clouds, on which the resources of the evictable applications are already released.
+func preemptCloudsForTest() map[string]asmodel.Cloud {
+	return map[string]asmodel.Cloud{
+		"cloud1": {
+			Name: "cloud1",
+			K8sNodes: []asmodel.K8sNode{
+				{Name: "node1", ResidualResources: asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 40}},
+				{Name: "node2", ResidualResources: asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 40}},
+			},
+		},
+		"cloud2": {
+			Name: "cloud2",
+			K8sNodes: []asmodel.K8sNode{
+				{Name: "node3", ResidualResources: asmodel.GenericResources{CpuCore: 2, Memory: 2048, Storage: 20}},
+			},
+		},
+	}
+}
+
+func TestValidatePreemptOptions(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           PreemptOptions
+		expectedErrNum int
+	}{
+		{
+			name:           "case disabled",
+			opts:           PreemptOptions{},
+			expectedErrNum: 0,
+		},
+		{
+			name:           "case valid",
+			opts:           PreemptOptions{BelowPriority: 5, Reschedule: true},
+			expectedErrNum: 0,
+		},
+		{
+			name:           "case max priority",
+			opts:           PreemptOptions{BelowPriority: asmodel.MaxPriority},
+			expectedErrNum: 0,
+		},
+		{
+			name:           "case nothing can be evicted",
+			opts:           PreemptOptions{BelowPriority: asmodel.MinPriority},
+			expectedErrNum: 1,
+		},
+		{
+			name:           "case max priority evictable",
+			opts:           PreemptOptions{BelowPriority: asmodel.MaxPriority + 1},
+			expectedErrNum: 1,
+		},
+		{
+			name:           "case negative",
+			opts:           PreemptOptions{BelowPriority: -1},
+			expectedErrNum: 0,
+		},
+		{
+			name:           "case reschedule without preemption",
+			opts:           PreemptOptions{Reschedule: true},
+			expectedErrNum: 1,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		errs := ValidatePreemptOptions(testCase.opts)
+		t.Log(models.HandleErrSlice(errs))
+		assert.Equal(t, testCase.expectedErrNum, len(errs), fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerOccupyBySolution(t *testing.T) {
+	clouds := preemptCloudsForTest()
+	apps := map[string]asmodel.Application{
+		"app1": {Name: "app1", Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 3, Memory: 1024, Storage: 10}}},
+		"app2": {Name: "app2", Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 1, Memory: 512, Storage: 5}}},
+		"app3": {Name: "app3", Resources: asmodel.AppResources{GenericResources: asmodel.GenericResources{CpuCore: 1, Memory: 512, Storage: 5}}},
+	}
+	vm := models.IaasVm{Name: "auto-sched-cloud2-0", Cloud: "cloud2", VCpu: 4, Ram: 4096, Storage: 50}
+	solution := asmodel.Solution{
+		AppsSolution: map[string]asmodel.SingleAppSolution{
+			"app1": {Accepted: true, TargetCloudName: "cloud1", K8sNodeName: "node1", AllocatedCpuCore: 2},
+			"app2": {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "auto-sched-cloud2-0", AllocatedCpuCore: 1},
+			"app3": {Accepted: false},
+		},
+		VmsToCreate: []models.IaasVm{vm},
+	}
+
+	occupied := occupyBySolution(clouds, apps, solution)
+
+	assert.Equal(t, asmodel.GenericResources{CpuCore: 2, Memory: 3072, Storage: 30}, occupied["cloud1"].K8sNodes[0].ResidualResources, "the allocated CPU of app1 should be subtracted from node1")
+	assert.Equal(t, clouds["cloud1"].K8sNodes[1], occupied["cloud1"].K8sNodes[1], "node2 should not be changed")
+	assert.Equal(t, 2, len(occupied["cloud2"].K8sNodes), "the new VM should be added to cloud2")
+	assert.Equal(t, asmodel.GenK8sNodeFromApps(vm, apps, []string{"app2"}), occupied["cloud2"].K8sNodes[1], "app2 should be on the new VM")
+	assert.Equal(t, vm.VCpu, occupied["cloud2"].Resources.InUse.VCpu, "the new VM should use the resources of cloud2")
+	assert.Equal(t, asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 40}, clouds["cloud1"].K8sNodes[0].ResidualResources, "the input clouds should not be changed")
+}
+
+func TestInnerDecideEvictions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		evictables      map[string]evictableApp
+		expectedEvicted []string
+		expectedNode1   asmodel.GenericResources // the residual resources of node1 after putting back the applications not evicted
+	}{
+		{
+			name: "case all fit",
+			evictables: map[string]evictableApp{
+				"low1": {app: asmodel.Application{Name: "low1", Priority: 1}, pods: []apiv1.Pod{podForTest("node1", "1", "1024", "10")}},
+				"low2": {app: asmodel.Application{Name: "low2", Priority: 2}, pods: []apiv1.Pod{podForTest("node1", "3", "1024", "10")}},
+			},
+			expectedEvicted: nil,
+			expectedNode1:   asmodel.GenericResources{CpuCore: 0, Memory: 2048, Storage: 20},
+		},
+		{
+			name: "case lower priority evicted first",
+			evictables: map[string]evictableApp{
+				"low1": {app: asmodel.Application{Name: "low1", Priority: 1}, pods: []apiv1.Pod{podForTest("node1", "2", "1024", "10")}},
+				"low2": {app: asmodel.Application{Name: "low2", Priority: 2}, pods: []apiv1.Pod{podForTest("node1", "3", "1024", "10")}},
+			},
+			expectedEvicted: []string{"low1"},
+			expectedNode1:   asmodel.GenericResources{CpuCore: 1, Memory: 3072, Storage: 30},
+		},
+		{
+			name: "case memory not enough",
+			evictables: map[string]evictableApp{
+				"low1": {app: asmodel.Application{Name: "low1", Priority: 3}, pods: []apiv1.Pod{podForTest("node1", "1", "5000", "10")}},
+			},
+			expectedEvicted: []string{"low1"},
+			expectedNode1:   asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 40},
+		},
+		{
+			name: "case one replica does not fit",
+			evictables: map[string]evictableApp{
+				"multi": {app: asmodel.Application{Name: "multi", Priority: 3, Replicas: 2}, pods: []apiv1.Pod{
+					podForTest("node1", "1", "512", "5"),
+					podForTest("node3", "1", "512", "5"),
+				}},
+			},
+			expectedEvicted: []string{"multi"},
+			expectedNode1:   asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 40},
+		},
+		{
+			name: "case pod on a node not used by auto-scheduling",
+			evictables: map[string]evictableApp{
+				"low1": {app: asmodel.Application{Name: "low1", Priority: 3}, pods: []apiv1.Pod{podForTest("other-node", "8", "512", "5")}},
+			},
+			expectedEvicted: nil,
+			expectedNode1:   asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 40},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		// node3 is fully used by the new applications.
+		clouds := preemptCloudsForTest()
+		clouds["cloud2"].K8sNodes[0].ResidualResources = asmodel.GenericResources{}
+
+		evicted, keptClouds := decideEvictions(clouds, testCase.evictables)
+		assert.Equal(t, testCase.expectedEvicted, evicted, fmt.Sprintf("%s: evicted is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedNode1, keptClouds["cloud1"].K8sNodes[0].ResidualResources, fmt.Sprintf("%s: clouds are not expected", testCase.name))
+		assert.Equal(t, asmodel.GenericResources{CpuCore: 4, Memory: 4096, Storage: 40}, clouds["cloud1"].K8sNodes[0].ResidualResources, fmt.Sprintf("%s: the input clouds should not be changed", testCase.name))
+	}
+}
+
+func TestInnerGenerateEvictedApps(t *testing.T) {
+	evictables := map[string]evictableApp{
+		"moved":   {app: asmodel.Application{Name: "moved", Priority: 2}, hasInfo: true, pods: []apiv1.Pod{podForTest("node1", "1", "512", "5")}},
+		"noInfo":  {app: asmodel.Application{Name: "noInfo", Priority: 3}, pods: []apiv1.Pod{podForTest("node1", "1", "512", "5")}},
+		"multi":   {app: asmodel.Application{Name: "multi", Priority: 1, Replicas: 2}, hasInfo: true, pods: []apiv1.Pod{podForTest("node2", "1", "512", "5"), podForTest("node1", "1", "512", "5")}},
+		"noPlace": {app: asmodel.Application{Name: "noPlace", Priority: 1}, hasInfo: true, pods: []apiv1.Pod{podForTest("node1", "1", "512", "5")}},
+	}
+	evicted := []string{"moved", "multi", "noInfo", "noPlace"}
+	reschedSoln := asmodel.Solution{
+		AppsSolution: map[string]asmodel.SingleAppSolution{
+			"moved":   {Accepted: true, TargetCloudName: "cloud2", K8sNodeName: "node3", AllocatedCpuCore: 1},
+			"noPlace": {Accepted: false},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		reschedule     bool
+		expectedResult []EvictedApp
+	}{
+		{
+			name:       "case not reschedule",
+			reschedule: false,
+			expectedResult: []EvictedApp{
+				{AppName: "moved", Priority: 2, FromNodes: []string{"node1"}},
+				{AppName: "multi", Priority: 1, FromNodes: []string{"node1", "node2"}},
+				{AppName: "noInfo", Priority: 3, FromNodes: []string{"node1"}},
+				{AppName: "noPlace", Priority: 1, FromNodes: []string{"node1"}},
+			},
+		},
+		{
+			name:       "case reschedule",
+			reschedule: true,
+			expectedResult: []EvictedApp{
+				{AppName: "moved", Priority: 2, FromNodes: []string{"node1"}, Rescheduled: true, ToCloud: "cloud2", ToNode: "node3", AllocatedCpu: 1},
+				{AppName: "multi", Priority: 1, FromNodes: []string{"node1", "node2"}, Note: "only single-replica applications can be rescheduled, so it will be deleted"},
+				{AppName: "noInfo", Priority: 3, FromNodes: []string{"node1"}, Note: fmt.Sprintf("it cannot be rescheduled without the annotation [%s], so it will be deleted", models.AutoScheduleInfoAnno)},
+				{AppName: "noPlace", Priority: 1, FromNodes: []string{"node1"}, Note: "rejected by the algorithm when rescheduling, so it will be deleted"},
+			},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		soln := asmodel.GenEmptySoln()
+		if testCase.reschedule {
+			soln = reschedSoln
+		}
+		actualResult := generateEvictedApps(evictables, evicted, testCase.reschedule, soln)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
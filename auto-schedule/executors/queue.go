@@ -0,0 +1,227 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego"
+
+	"emcontroller/auto-schedule/algorithms"
+	"emcontroller/models"
+)
+
+// the orders of the scheduling queue
+const (
+	QueueOrderFifo     string = "fifo"     // first come, first served
+	QueueOrderPriority string = "priority" // the requests with higher priorities first, and the same priorities are first come, first served
+)
+
+// the phases of the requests in the scheduling queue
+const (
+	QueueWaiting string = "waiting"
+	QueueRunning string = "running"
+)
+
+// the key of the queue order in app.conf
+const SchedulingQueueOrderKey string = "SchedulingQueueOrder"
+
+var (
+	// ErrQueueItemNotFound is returned when cancelling a request that is not in the queue, e.g., it is already finished.
+	ErrQueueItemNotFound = errors.New("scheduling request not found in the queue")
+	// ErrQueueItemRunning is returned when cancelling a request that is already running.
+	ErrQueueItemRunning = errors.New("scheduling request is already running")
+)
+
+// QueueEntry is the information of a request in the scheduling queue shown to users.
+type QueueEntry struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"` // the same as the task types, e.g., models.TaskTypeCreateAppGroup
+	Priority   int       `json:"priority"`
+	Phase      string    `json:"phase"`
+	Position   int       `json:"position"` // 0 means running, and 1 means the next one to run
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+}
+
+// QueueTicket is what a submitted request holds to wait for its turn.
+type QueueTicket struct {
+	entry     QueueEntry
+	seq       int64
+	turn      chan struct{} // closed when it is the turn of this request
+	cancelled chan struct{} // closed when this request is cancelled
+}
+
+func (t *QueueTicket) ID() string {
+	return t.entry.ID
+}
+
+// SchedulingQueue lets scheduling and migration requests run one at a time instead of being refused when another one is running.
+// The requests that are running hold algorithms.ScheMu, so they also do not run at the same time as the cleanup.
+type SchedulingQueue struct {
+	order   string
+	waiting []*QueueTicket
+	running *QueueTicket
+	nextSeq int64
+	mu      sync.Mutex
+}
+
+// the global scheduling queue
+var ScheQueue *SchedulingQueue = NewSchedulingQueue(QueueOrderFifo)
+
+func NewSchedulingQueue(order string) *SchedulingQueue {
+	return &SchedulingQueue{order: order}
+}
+
+// InitSchedulingQueue reads the order of the scheduling queue from app.conf. If it is not set or invalid, we use QueueOrderFifo.
+func InitSchedulingQueue() {
+	order := strings.ToLower(strings.TrimSpace(beego.AppConfig.String(SchedulingQueueOrderKey)))
+	switch order {
+	case QueueOrderFifo, QueueOrderPriority:
+	case "":
+		order = QueueOrderFifo
+	default:
+		beego.Error(fmt.Sprintf("The %s [%s] in app.conf is invalid, it should be [%s] or [%s]. We use [%s].", SchedulingQueueOrderKey, order, QueueOrderFifo, QueueOrderPriority, QueueOrderFifo))
+		order = QueueOrderFifo
+	}
+	ScheQueue = NewSchedulingQueue(order)
+	beego.Info(fmt.Sprintf("The order of the scheduling queue is [%s].", order))
+}
+
+// Submit puts a request into the queue and returns its ticket, with which the request waits for its turn by Run.
+func (q *SchedulingQueue) Submit(reqType string, priority int) *QueueTicket {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextSeq++
+	ticket := &QueueTicket{
+		entry: QueueEntry{
+			ID:         fmt.Sprintf("%s-%d-%d", reqType, time.Now().UnixNano(), q.nextSeq),
+			Type:       reqType,
+			Priority:   priority,
+			Phase:      QueueWaiting,
+			EnqueuedAt: time.Now(),
+		},
+		seq:       q.nextSeq,
+		turn:      make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	q.waiting = append(q.waiting, ticket)
+	if q.order == QueueOrderPriority {
+		sort.SliceStable(q.waiting, func(i, j int) bool {
+			if q.waiting[i].entry.Priority != q.waiting[j].entry.Priority {
+				return q.waiting[i].entry.Priority > q.waiting[j].entry.Priority
+			}
+			return q.waiting[i].seq < q.waiting[j].seq
+		})
+	}
+	q.dispatchLocked()
+
+	beego.Info(fmt.Sprintf("Scheduling request [%s] with priority %d is submitted to the queue.", ticket.entry.ID, priority))
+	return ticket
+}
+
+// Position returns the current position of a request. 0 means running, 1 means the next one to run, and -1 means that it is not in the queue.
+func (q *SchedulingQueue) Position(ticket *QueueTicket) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running == ticket {
+		return 0
+	}
+	for i, waiting := range q.waiting {
+		if waiting == ticket {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// Run waits for the turn of the request, and then runs the work while holding algorithms.ScheMu. When the work returns, the next request gets its turn.
+// If the request is cancelled while waiting, the work is not run, and the returned error wraps models.ErrTaskCancelled.
+func (q *SchedulingQueue) Run(ticket *QueueTicket, work func()) error {
+	select {
+	case <-ticket.turn:
+	case <-ticket.cancelled:
+		return fmt.Errorf("scheduling request [%s] is %w", ticket.entry.ID, models.ErrTaskCancelled)
+	}
+
+	// the deferred functions run even if the work panics, so the queue will not be stuck.
+	defer func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.running = nil
+		q.dispatchLocked()
+		beego.Info(fmt.Sprintf("Scheduling request [%s] is finished.", ticket.entry.ID))
+	}()
+
+	algorithms.ScheMu.Lock()
+	defer algorithms.ScheMu.Unlock()
+	beego.Info(fmt.Sprintf("Scheduling request [%s] starts running.", ticket.entry.ID))
+	work()
+	return nil
+}
+
+// Cancel removes a waiting request from the queue. A running request cannot be cancelled.
+func (q *SchedulingQueue) Cancel(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running != nil && q.running.entry.ID == id {
+		return fmt.Errorf("%w: [%s]", ErrQueueItemRunning, id)
+	}
+	for i, waiting := range q.waiting {
+		if waiting.entry.ID == id {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			close(waiting.cancelled)
+			beego.Info(fmt.Sprintf("Scheduling request [%s] is cancelled.", id))
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: [%s]", ErrQueueItemNotFound, id)
+}
+
+// CancelOnDone cancels a waiting request when ctx is done, e.g., when the client of a synchronous request disconnects, so that it does not wait in the queue with nobody waiting for its response.
+// It does nothing after the request gets its turn, because a running request is stopped by its own context instead.
+func (q *SchedulingQueue) CancelOnDone(ctx context.Context, ticket *QueueTicket) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			if err := q.Cancel(ticket.ID()); err != nil {
+				beego.Info(fmt.Sprintf("The context of scheduling request [%s] is done, but it cannot be cancelled: %s", ticket.ID(), err.Error()))
+			}
+		case <-ticket.turn:
+		case <-ticket.cancelled:
+		}
+	}()
+}
+
+// List returns the running request and the waiting requests in the order that they will run.
+func (q *SchedulingQueue) List() []QueueEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := make([]QueueEntry, 0, len(q.waiting)+1)
+	if q.running != nil {
+		entry := q.running.entry
+		entry.Phase = QueueRunning
+		entry.Position = 0
+		entries = append(entries, entry)
+	}
+	for i, waiting := range q.waiting {
+		entry := waiting.entry
+		entry.Position = i + 1
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// If no request is running, give the turn to the first waiting one. The caller should hold q.mu.
+func (q *SchedulingQueue) dispatchLocked() {
+	if q.running != nil || len(q.waiting) == 0 {
+		return
+	}
+	q.running = q.waiting[0]
+	q.waiting = q.waiting[1:]
+	close(q.running.turn)
+}
@@ -0,0 +1,174 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/models"
+)
+
+func TestSchedulingQueueOrder(t *testing.T) {
+	testCases := []struct {
+		name          string
+		order         string
+		priorities    []int
+		expectedOrder []int // the indexes of the requests in the order that they run
+	}{
+		{
+			name:          "case fifo",
+			order:         QueueOrderFifo,
+			priorities:    []int{2, 5, 3, 5},
+			expectedOrder: []int{0, 1, 2, 3},
+		},
+		{
+			name:          "case priority",
+			order:         QueueOrderPriority,
+			priorities:    []int{2, 5, 3, 5},
+			expectedOrder: []int{1, 3, 2, 0},
+		},
+		{
+			name:          "case priority all the same",
+			order:         QueueOrderPriority,
+			priorities:    []int{4, 4, 4},
+			expectedOrder: []int{0, 1, 2},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		q := NewSchedulingQueue(testCase.order)
+
+		// the first request keeps running until released, so that the others wait in the queue.
+		release := make(chan struct{})
+		blocker := q.Submit(models.TaskTypeCreateAppGroup, 1)
+		blockerDone := make(chan struct{})
+		go func() {
+			defer close(blockerDone)
+			q.Run(blocker, func() { <-release })
+		}()
+
+		var mu sync.Mutex
+		var runOrder []int
+		var wg sync.WaitGroup
+		for j, priority := range testCase.priorities {
+			ticket := q.Submit(models.TaskTypeCreateAppGroup, priority)
+			wg.Add(1)
+			go func(j int, ticket *QueueTicket) {
+				defer wg.Done()
+				q.Run(ticket, func() {
+					mu.Lock()
+					defer mu.Unlock()
+					runOrder = append(runOrder, j)
+				})
+			}(j, ticket)
+		}
+
+		close(release)
+		<-blockerDone
+		wg.Wait()
+		assert.Equal(t, testCase.expectedOrder, runOrder, fmt.Sprintf("%s: result is not expected", testCase.name))
+		assert.Empty(t, q.List(), fmt.Sprintf("%s: queue should be empty", testCase.name))
+	}
+}
+
+func TestSchedulingQueueCancel(t *testing.T) {
+	q := NewSchedulingQueue(QueueOrderFifo)
+
+	release := make(chan struct{})
+	blocker := q.Submit(models.TaskTypeMigrateAppGroup, 1)
+	blockerDone := make(chan struct{})
+	go func() {
+		defer close(blockerDone)
+		q.Run(blocker, func() { <-release })
+	}()
+	ticketA := q.Submit(models.TaskTypeCreateAppGroup, 3)
+	ticketB := q.Submit(models.TaskTypeCreateAppGroup, 3)
+	assert.Equal(t, 0, q.Position(blocker))
+	assert.Equal(t, 1, q.Position(ticketA))
+	assert.Equal(t, 2, q.Position(ticketB))
+
+	// a waiting request can be cancelled, and then its work is not run.
+	assert.Nil(t, q.Cancel(ticketA.ID()))
+	ranA := false
+	err := q.Run(ticketA, func() { ranA = true })
+	assert.True(t, errors.Is(err, models.ErrTaskCancelled), fmt.Sprintf("error [%v] should wrap ErrTaskCancelled", err))
+	assert.False(t, ranA)
+	assert.Equal(t, -1, q.Position(ticketA))
+
+	// a running request or an unknown request cannot be cancelled.
+	assert.True(t, errors.Is(q.Cancel(blocker.ID()), ErrQueueItemRunning))
+	assert.True(t, errors.Is(q.Cancel(ticketA.ID()), ErrQueueItemNotFound))
+	assert.True(t, errors.Is(q.Cancel("not-exist"), ErrQueueItemNotFound))
+
+	entries := q.List()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, blocker.ID(), entries[0].ID)
+		assert.Equal(t, QueueRunning, entries[0].Phase)
+		assert.Equal(t, 0, entries[0].Position)
+		assert.Equal(t, models.TaskTypeMigrateAppGroup, entries[0].Type)
+		assert.Equal(t, ticketB.ID(), entries[1].ID)
+		assert.Equal(t, QueueWaiting, entries[1].Phase)
+		assert.Equal(t, 1, entries[1].Position)
+		assert.Equal(t, 3, entries[1].Priority)
+	}
+
+	close(release)
+	<-blockerDone
+	ranB := false
+	assert.Nil(t, q.Run(ticketB, func() { ranB = true }))
+	assert.True(t, ranB)
+	assert.Empty(t, q.List())
+}
+
+// a waiting request whose client disconnects should leave the queue, but a running one should not be affected.
+func TestSchedulingQueueCancelOnDone(t *testing.T) {
+	q := NewSchedulingQueue(QueueOrderFifo)
+
+	release := make(chan struct{})
+	blocker := q.Submit(models.TaskTypeMigrateAppGroup, 1)
+	blockerCtx, blockerCancel := context.WithCancel(context.Background())
+	q.CancelOnDone(blockerCtx, blocker)
+	blockerDone := make(chan struct{})
+	go func() {
+		defer close(blockerDone)
+		q.Run(blocker, func() { <-release })
+	}()
+
+	ticket := q.Submit(models.TaskTypeCreateAppGroup, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	q.CancelOnDone(ctx, ticket)
+	cancel()
+	ran := false
+	err := q.Run(ticket, func() { ran = true })
+	assert.True(t, errors.Is(err, models.ErrTaskCancelled), fmt.Sprintf("error [%v] should wrap ErrTaskCancelled", err))
+	assert.False(t, ran)
+	assert.Equal(t, -1, q.Position(ticket))
+
+	blockerCancel()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 0, q.Position(blocker))
+
+	close(release)
+	<-blockerDone
+	assert.Empty(t, q.List())
+}
+
+// the queue should not be stuck if the work of a request panics.
+func TestSchedulingQueuePanic(t *testing.T) {
+	q := NewSchedulingQueue(QueueOrderFifo)
+	ticket := q.Submit(models.TaskTypeCreateAppGroup, 1)
+	assert.Panics(t, func() {
+		q.Run(ticket, func() { panic("test panic") })
+	})
+
+	next := q.Submit(models.TaskTypeCreateAppGroup, 1)
+	ran := false
+	assert.Nil(t, q.Run(next, func() { ran = true }))
+	assert.True(t, ran)
+}
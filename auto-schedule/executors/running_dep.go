@@ -0,0 +1,99 @@
+package executors
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/astaxie/beego"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// The names of the applications that the input applications depend on but are not in the input. They should be running already. The output is sorted.
+func externalDepNames(apps []models.K8sApp) []string {
+	appMap := generateAppMap(apps)
+	nameSet := make(map[string]struct{})
+	for _, app := range apps {
+		for _, dep := range app.Dependencies {
+			if _, exist := appMap[dep.AppName]; !exist {
+				nameSet[dep.AppName] = struct{}{}
+			}
+		}
+	}
+	names := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find where the running applications are, i.e., the clouds and Kubernetes nodes of their pods. The key of the output is the application name. The applications that are not running or whose pods are not on any known cloud are not in the output.
+func getRunningAppLocations(appNames []string) (map[string][]asmodel.AppLocation, error) {
+	runningApps := make(map[string][]asmodel.AppLocation)
+	if len(appNames) == 0 {
+		return runningApps, nil
+	}
+
+	// In our design, the name of a VM is the same as that of its Kubernetes node, so we can find the cloud of a node by the VMs.
+	// If some clouds cannot be accessed, we still use the VMs on other clouds.
+	vms, errs := models.ListVMsAllClouds()
+	if len(errs) != 0 {
+		beego.Error(fmt.Sprintf("List VMs in all clouds to find the running applications, Error: %s", models.HandleErrSlice(errs).Error()))
+	}
+
+	for _, appName := range appNames {
+		deploy, err := models.GetDeployment(models.KubernetesNamespace, appName+models.DeploymentSuffix)
+		if err != nil {
+			outErr := fmt.Errorf("Get the deployment of application [%s], Error: [%w]", appName, err)
+			beego.Error(outErr)
+			return nil, outErr
+		}
+		if deploy == nil {
+			beego.Info(fmt.Sprintf("Application [%s] is not running.", appName))
+			continue
+		}
+
+		pods, err := models.ListPods(models.KubernetesNamespace, metav1.ListOptions{LabelSelector: fmt.Sprintf("app=%s", appName)})
+		if err != nil {
+			outErr := fmt.Errorf("List the pods of application [%s], Error: [%w]", appName, err)
+			beego.Error(outErr)
+			return nil, outErr
+		}
+		if locations := locatePods(pods, vms); len(locations) != 0 {
+			runningApps[appName] = locations
+		} else {
+			beego.Info(fmt.Sprintf("The pods of application [%s] are not on any known cloud.", appName))
+		}
+	}
+
+	return runningApps, nil
+}
+
+// Find the clouds and Kubernetes nodes of the pods. The pods not scheduled yet or on unknown VMs are ignored. The output is sorted by the names of nodes.
+func locatePods(pods []apiv1.Pod, vms []models.IaasVm) []asmodel.AppLocation {
+	cloudOfVm := make(map[string]string)
+	for _, vm := range vms {
+		cloudOfVm[vm.Name] = vm.Cloud
+	}
+
+	var locations []asmodel.AppLocation
+	for _, nodeName := range podNodeNames(pods) {
+		if cloudName, exist := cloudOfVm[nodeName]; exist {
+			locations = append(locations, asmodel.AppLocation{CloudName: cloudName, K8sNodeName: nodeName})
+		}
+	}
+	return locations
+}
+
+// the names of the running applications as a set.
+func runningAppNameSet(runningApps map[string][]asmodel.AppLocation) map[string]struct{} {
+	names := make(map[string]struct{})
+	for name := range runningApps {
+		names[name] = struct{}{}
+	}
+	return names
+}
@@ -0,0 +1,84 @@
+package executors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+func TestExternalDepNames(t *testing.T) {
+	testCases := []struct {
+		name           string
+		apps           []models.K8sApp
+		expectedResult []string
+	}{
+		{
+			name: "case no external dependencies",
+			apps: []models.K8sApp{
+				{Name: "app1", Dependencies: []models.Dependency{{AppName: "app2"}}},
+				{Name: "app2"},
+			},
+			expectedResult: []string{},
+		},
+		{
+			name: "case external dependencies",
+			apps: []models.K8sApp{
+				{Name: "app1", Dependencies: []models.Dependency{{AppName: "shared-db"}, {AppName: "app2"}}},
+				{Name: "app2", Dependencies: []models.Dependency{{AppName: "shared-db"}, {AppName: "cache"}}},
+			},
+			expectedResult: []string{"cache", "shared-db"},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := externalDepNames(testCase.apps)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestInnerLocatePods(t *testing.T) {
+	vms := []models.IaasVm{
+		{Name: "node1", Cloud: "cloud1"},
+		{Name: "node2", Cloud: "cloud2"},
+		{Name: "node3", Cloud: "cloud2"},
+	}
+
+	testCases := []struct {
+		name           string
+		pods           []apiv1.Pod
+		expectedResult []asmodel.AppLocation
+	}{
+		{
+			name: "case several nodes",
+			pods: []apiv1.Pod{
+				podForTest("node3", "1", "100", "1"),
+				podForTest("node1", "1", "100", "1"),
+				podForTest("node3", "1", "100", "1"),
+			},
+			expectedResult: []asmodel.AppLocation{
+				{CloudName: "cloud1", K8sNodeName: "node1"},
+				{CloudName: "cloud2", K8sNodeName: "node3"},
+			},
+		},
+		{
+			name: "case pending pod and unknown node",
+			pods: []apiv1.Pod{
+				podForTest("", "1", "100", "1"),
+				podForTest("node-not-vm", "1", "100", "1"),
+			},
+			expectedResult: nil,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := locatePods(testCase.pods, vms)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
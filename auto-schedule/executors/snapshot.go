@@ -0,0 +1,50 @@
+package executors
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/astaxie/beego"
+
+	"emcontroller/auto-schedule/algorithms"
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// SnapshotAutoScheduleApps returns the input that the scheduling algorithms would get for the applications on the current clouds, without scheduling them. With the snapshot, a scheduling can be replayed offline, e.g., by "auto-schedule/asched" with the seed of the scheduling.
+func SnapshotAutoScheduleApps(apps []models.K8sApp, preempt PreemptOptions) (asmodel.Snapshot, error, int) {
+	runningDeps, err := getRunningAppLocations(externalDepNames(apps))
+	if err != nil {
+		outErr := fmt.Errorf("Get the running applications that the input applications depend on, Error: [%w]", err)
+		beego.Error(outErr)
+		return asmodel.Snapshot{}, outErr, http.StatusInternalServerError
+	}
+
+	if errs := ValidateAutoScheduleApps(apps, runningAppNameSet(runningDeps)); len(errs) != 0 {
+		outErr := fmt.Errorf("The input applicatios are invalid, Error: [%w]", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		return asmodel.Snapshot{}, outErr, http.StatusBadRequest
+	}
+	if errs := ValidatePreemptOptions(preempt); len(errs) != 0 {
+		outErr := fmt.Errorf("The preemption options are invalid, Error: [%w]", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		return asmodel.Snapshot{}, outErr, http.StatusBadRequest
+	}
+
+	cloudsForScheduling, appsForScheduling, _, err, statusCode := generateSchedulingInput(apps, runningDeps, preempt)
+	if err != nil {
+		return asmodel.Snapshot{}, err, statusCode
+	}
+
+	// the same as scheduleApps
+	expandedApps := asmodel.ExpandReplicas(appsForScheduling)
+	appsOrder := algorithms.GenerateAppsOrder(expandedApps)
+	sort.Strings(appsOrder)
+
+	return asmodel.Snapshot{
+		Clouds:    cloudsForScheduling,
+		Apps:      expandedApps,
+		AppsOrder: appsOrder,
+	}, nil, http.StatusOK
+}
@@ -9,7 +9,8 @@ import (
 	"emcontroller/models"
 )
 
-func ValidateAutoScheduleApps(apps []models.K8sApp) []error {
+// runningAppNames are the running applications that the input applications can depend on.
+func ValidateAutoScheduleApps(apps []models.K8sApp, runningAppNames map[string]struct{}) []error {
 	var allErrs []error
 
 	// validate every single applications
@@ -18,7 +19,10 @@ func ValidateAutoScheduleApps(apps []models.K8sApp) []error {
 	}
 
 	// validate the dependencies among these applications
-	allErrs = append(allErrs, ValidateAutoScheduleDep(apps)...)
+	allErrs = append(allErrs, ValidateAutoScheduleDep(apps, runningAppNames)...)
+
+	// validate the placement constraints, some of which are about other applications
+	allErrs = append(allErrs, ValidateAutoSchedulePlacement(apps)...)
 
 	return allErrs
 }
@@ -39,24 +43,43 @@ func ValidateAutoScheduleApp(app models.K8sApp) []error {
 		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], AutoScheduled should be [%t], but it is [%t].", app.Name, true, app.AutoScheduled))
 	}
 
-	var allowedReplicas int32 = 1
-	if app.Replicas != allowedReplicas {
-		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], Replicas should be [%d], but it is [%d].", app.Name, allowedReplicas, app.Replicas))
+	if app.ExpCompuTimeOneCpu < 0 {
+		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], ExpCompuTimeOneCpu should not be negative, but it is [%g].", app.Name, app.ExpCompuTimeOneCpu))
+	}
+
+	var minReplicas int32 = 1
+	if app.Replicas < minReplicas {
+		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], Replicas should be at least [%d], but it is [%d].", app.Name, minReplicas, app.Replicas))
+	}
+
+	// the replicas are spread across clouds, so one cloud has at least one replica. If Replicas is invalid, the error above is enough.
+	if app.MinClouds < 0 || (app.Replicas >= minReplicas && app.MinClouds > int(app.Replicas)) {
+		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], MinClouds should be in [0, %d] (Replicas), but it is [%d].", app.Name, app.Replicas, app.MinClouds))
 	}
 
 	if len(app.NodeName) != 0 {
 		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] should not be set NodeName, but it is set as [%s].", app.Name, app.NodeName))
 	}
 
+	if len(app.NodeNames) != 0 {
+		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] should not be set NodeNames, but it is set as %v.", app.Name, app.NodeNames))
+	}
+
 	if len(app.NodeSelector) != 0 {
 		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] should not have NodeSelector, but it has [%s].", app.Name, app.NodeSelector))
 	}
 
-	if len(app.Containers) != 1 {
-		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] should only have 1 container, but it has [%d].", app.Name, len(app.Containers)))
+	if len(app.Containers) < 1 {
+		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] should have at least 1 container, but it has [%d].", app.Name, len(app.Containers)))
 	}
 
+	// the names of containers in a pod should be unique
+	containerNames := make(map[string]struct{})
 	for _, container := range app.Containers {
+		if _, exist := containerNames[container.Name]; exist {
+			allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] has more than 1 container named [%s].", app.Name, container.Name))
+		}
+		containerNames[container.Name] = struct{}{}
 		allErrs = append(allErrs, validateContainer(container)...)
 	}
 
@@ -17,21 +17,29 @@ func generateAppMap(apps []models.K8sApp) map[string]models.K8sApp {
 }
 
 // validate the dependencies among the Auto-Schedule applications
-func ValidateAutoScheduleDep(apps []models.K8sApp) []error {
+// runningAppNames are the running applications that can be depended on, whose places are fixed in scheduling.
+func ValidateAutoScheduleDep(apps []models.K8sApp, runningAppNames map[string]struct{}) []error {
 	var allErrs []error
 
 	appMap := generateAppMap(apps)
 
 	// The priority of a dependent application should be greater than or equal to that of the one that depends on it.
-	// The dependent application should exist in this group of applications.
+	// The dependent application should exist in this group of applications or be running already. A running one is not rejected in scheduling, so its priority does not matter.
 	for _, app := range appMap {
 		for _, dependency := range app.Dependencies {
 			if dependentApp, exist := appMap[dependency.AppName]; exist {
 				if dependentApp.Priority < app.Priority {
 					allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] with priority [%d] depends on application [%s] with priority [%d], but the priority of a dependent application should be greater than or equal to that of the one that depends on it.", app.Name, app.Priority, dependentApp.Name, dependentApp.Priority))
 				}
-			} else {
-				allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] depends on application [%s], but the dependent application does not exist in this group of applications.", app.Name, dependency.AppName))
+			} else if _, running := runningAppNames[dependency.AppName]; !running {
+				allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] depends on application [%s], but the dependent application does not exist in this group of applications, and it is not running on any known cloud.", app.Name, dependency.AppName))
+			}
+			// The network requirements are optional, and 0 means no requirement.
+			if dependency.MaxRttMs < 0 {
+				allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] depends on application [%s] with maxRttMs [%g], but maxRttMs should not be negative.", app.Name, dependency.AppName, dependency.MaxRttMs))
+			}
+			if dependency.MinBandwidthMbps < 0 {
+				allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] depends on application [%s] with minBandwidthMbps [%g], but minBandwidthMbps should not be negative.", app.Name, dependency.AppName, dependency.MinBandwidthMbps))
 			}
 		}
 	}
@@ -50,7 +58,20 @@ func ValidateAutoScheduleDep(apps []models.K8sApp) []error {
 func validateCircularDep(appMap map[string]models.K8sApp) []error {
 	var allErrs []error
 
-	if topoOrder, hasCycle := TopoSort(appMap); hasCycle {
+	// The running applications do not depend on the new ones, so the dependencies on them cannot make cycles, and TopoSort only needs the dependencies among these applications.
+	appMapForTopoSort := make(map[string]models.K8sApp)
+	for name, app := range appMap {
+		var deps []models.Dependency
+		for _, dep := range app.Dependencies {
+			if _, exist := appMap[dep.AppName]; exist {
+				deps = append(deps, dep)
+			}
+		}
+		app.Dependencies = deps
+		appMapForTopoSort[name] = app
+	}
+
+	if topoOrder, hasCycle := TopoSort(appMapForTopoSort); hasCycle {
 		allErrs = append(allErrs, fmt.Errorf("Auto-schedule applications have circular dependencies, the cycles are not in the following applications %+v.", topoOrder))
 	}
 
@@ -11,9 +11,10 @@ import (
 
 func TestValidateAutoScheduleDep(t *testing.T) {
 	testCases := []struct {
-		name           string
-		apps           []models.K8sApp
-		expectedErrNum int
+		name            string
+		apps            []models.K8sApp
+		runningAppNames map[string]struct{}
+		expectedErrNum  int
 	}{
 		{
 			name: "noErr",
@@ -284,10 +285,130 @@ func TestValidateAutoScheduleDep(t *testing.T) {
 			},
 			expectedErrNum: 1,
 		},
+		{
+			name: "noErr network requirements",
+			apps: []models.K8sApp{
+				models.K8sApp{
+					Name:     "app1",
+					Priority: 2,
+					Dependencies: []models.Dependency{
+						{
+							AppName:          "app2",
+							MaxRttMs:         50,
+							MinBandwidthMbps: 100,
+						},
+					},
+				},
+				models.K8sApp{
+					Name:         "app2",
+					Priority:     10,
+					Dependencies: []models.Dependency{},
+				},
+			},
+			expectedErrNum: 0,
+		},
+		{
+			name: "NetworkRequirementErr",
+			apps: []models.K8sApp{
+				models.K8sApp{
+					Name:     "app1",
+					Priority: 2,
+					Dependencies: []models.Dependency{
+						{
+							AppName:          "app2",
+							MaxRttMs:         -1,
+							MinBandwidthMbps: -100,
+						},
+					},
+				},
+				models.K8sApp{
+					Name:         "app2",
+					Priority:     10,
+					Dependencies: []models.Dependency{},
+				},
+			},
+			expectedErrNum: 2,
+		},
+		{
+			name: "noErr running dependency",
+			apps: []models.K8sApp{
+				models.K8sApp{
+					Name:     "app1",
+					Priority: 5,
+					Dependencies: []models.Dependency{
+						{
+							AppName:  "shared-db",
+							MaxRttMs: 10,
+						},
+						{
+							AppName: "app2",
+						},
+					},
+				},
+				models.K8sApp{
+					Name:     "app2",
+					Priority: 5,
+					Dependencies: []models.Dependency{
+						{
+							AppName: "shared-db",
+						},
+					},
+				},
+			},
+			runningAppNames: map[string]struct{}{"shared-db": struct{}{}},
+			expectedErrNum:  0,
+		},
+		{
+			name: "ExistErr dependency not running",
+			apps: []models.K8sApp{
+				models.K8sApp{
+					Name:     "app1",
+					Priority: 5,
+					Dependencies: []models.Dependency{
+						{
+							AppName: "shared-db",
+						},
+						{
+							AppName: "shared-cache",
+						},
+					},
+				},
+			},
+			runningAppNames: map[string]struct{}{"shared-db": struct{}{}},
+			expectedErrNum:  1,
+		},
+		{
+			name: "CircularDepErr with running dependency",
+			apps: []models.K8sApp{
+				models.K8sApp{
+					Name:     "app1",
+					Priority: 5,
+					Dependencies: []models.Dependency{
+						{
+							AppName: "shared-db",
+						},
+						{
+							AppName: "app2",
+						},
+					},
+				},
+				models.K8sApp{
+					Name:     "app2",
+					Priority: 5,
+					Dependencies: []models.Dependency{
+						{
+							AppName: "app1",
+						},
+					},
+				},
+			},
+			runningAppNames: map[string]struct{}{"shared-db": struct{}{}},
+			expectedErrNum:  1,
+		},
 	}
 	for i, testCase := range testCases {
 		t.Logf("test: %d, %s", i, testCase.name)
-		errs := ValidateAutoScheduleDep(testCase.apps)
+		errs := ValidateAutoScheduleDep(testCase.apps, testCase.runningAppNames)
 		fmt.Println("errors:", models.HandleErrSlice(errs))
 		assert.Equal(t, testCase.expectedErrNum, len(errs), fmt.Sprintf("%s: result is not expected", testCase.name))
 	}
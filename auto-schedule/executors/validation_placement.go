@@ -0,0 +1,66 @@
+package executors
+
+import (
+	"fmt"
+
+	"emcontroller/models"
+)
+
+// the cloud types that can be used in the placement constraints
+var placementCloudTypes map[string]struct{} = map[string]struct{}{
+	models.OpenstackIaas: struct{}{},
+	models.ProxmoxIaas:   struct{}{},
+	models.SimulatedIaas: struct{}{},
+}
+
+// validate the placement constraints of the Auto-Schedule applications
+func ValidateAutoSchedulePlacement(apps []models.K8sApp) []error {
+	var allErrs []error
+
+	appMap := generateAppMap(apps)
+
+	for _, app := range apps {
+		placement := app.Placement
+
+		// a cloud cannot be both allowed and forbidden
+		for _, cloudName := range placement.ForbiddenClouds {
+			for _, allowed := range placement.AllowedClouds {
+				if cloudName == allowed {
+					allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], cloud [%s] is in both allowedClouds and forbiddenClouds.", app.Name, cloudName))
+				}
+			}
+		}
+
+		for _, cloudType := range placement.CloudTypes {
+			if _, exist := placementCloudTypes[cloudType]; !exist {
+				allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], cloud type [%s] in cloudTypes is not supported. The supported types are [%s], [%s], and [%s].", app.Name, cloudType, models.OpenstackIaas, models.ProxmoxIaas, models.SimulatedIaas))
+			}
+		}
+
+		// The applications in the co-location constraints should exist in this group of applications, and should not be this application itself.
+		coLocated := make(map[string]struct{})
+		for _, otherName := range placement.CoLocateWith {
+			allErrs = append(allErrs, validatePlacementApp(app.Name, otherName, "coLocateWith", appMap)...)
+			coLocated[otherName] = struct{}{}
+		}
+		for _, otherName := range placement.AntiCoLocateWith {
+			allErrs = append(allErrs, validatePlacementApp(app.Name, otherName, "antiCoLocateWith", appMap)...)
+			if _, exist := coLocated[otherName]; exist {
+				allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s], application [%s] is in both coLocateWith and antiCoLocateWith.", app.Name, otherName))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validate an application in the co-location constraints of another application. field is the name of the constraint, used in the error message.
+func validatePlacementApp(appName, otherName, field string, appMap map[string]models.K8sApp) []error {
+	var allErrs []error
+	if otherName == appName {
+		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] has itself in %s.", appName, field))
+	} else if _, exist := appMap[otherName]; !exist {
+		allErrs = append(allErrs, fmt.Errorf("Auto-schedule application [%s] has application [%s] in %s, but it does not exist in this group of applications.", appName, otherName, field))
+	}
+	return allErrs
+}
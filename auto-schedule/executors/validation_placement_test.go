@@ -0,0 +1,107 @@
+package executors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/models"
+)
+
+func TestValidateAutoSchedulePlacement(t *testing.T) {
+	testCases := []struct {
+		name           string
+		apps           []models.K8sApp
+		expectedErrNum int
+	}{
+		{
+			name: "noErr",
+			apps: []models.K8sApp{
+				{
+					Name: "app1",
+					Placement: models.Placement{
+						AllowedClouds:    []string{"proxmox1", "proxmox2"},
+						CloudTypes:       []string{models.ProxmoxIaas},
+						CoLocateWith:     []string{"app2"},
+						AntiCoLocateWith: []string{"app3"},
+					},
+				},
+				{
+					Name: "app2",
+					Placement: models.Placement{
+						ForbiddenClouds: []string{"openstack1"},
+					},
+				},
+				{
+					Name: "app3",
+				},
+			},
+			expectedErrNum: 0,
+		},
+		{
+			name: "allowedAndForbidden",
+			apps: []models.K8sApp{
+				{
+					Name: "app1",
+					Placement: models.Placement{
+						AllowedClouds:   []string{"proxmox1", "proxmox2"},
+						ForbiddenClouds: []string{"proxmox2"},
+					},
+				},
+			},
+			expectedErrNum: 1,
+		},
+		{
+			name: "unknownCloudType",
+			apps: []models.K8sApp{
+				{
+					Name: "app1",
+					Placement: models.Placement{
+						CloudTypes: []string{models.ProxmoxIaas, "aws"},
+					},
+				},
+			},
+			expectedErrNum: 1,
+		},
+		{
+			name: "coLocationAppErrors",
+			apps: []models.K8sApp{
+				{
+					Name: "app1",
+					Placement: models.Placement{
+						CoLocateWith:     []string{"app1", "app2", "app3"},
+						AntiCoLocateWith: []string{"app4"},
+					},
+				},
+				{
+					Name: "app2",
+				},
+			},
+			expectedErrNum: 3, // app1 itself, app3 and app4 do not exist
+		},
+		{
+			name: "coLocateAndAntiCoLocate",
+			apps: []models.K8sApp{
+				{
+					Name: "app1",
+					Placement: models.Placement{
+						CoLocateWith:     []string{"app2"},
+						AntiCoLocateWith: []string{"app2"},
+					},
+				},
+				{
+					Name: "app2",
+				},
+			},
+			expectedErrNum: 1,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		errs := ValidateAutoSchedulePlacement(testCase.apps)
+		t.Log("errors:", models.HandleErrSlice(errs))
+		assert.Equal(t, testCase.expectedErrNum, len(errs), fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
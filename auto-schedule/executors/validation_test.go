@@ -128,6 +128,60 @@ func TestValidateAutoScheduleApp(t *testing.T) {
 				Replicas:      2,
 				AutoScheduled: true,
 			},
+			expectedErrNum: 1,
+		},
+		{
+			name: "Replicas0",
+			app: models.K8sApp{
+				Name:          "Replicas0",
+				Priority:      9,
+				Replicas:      0,
+				AutoScheduled: true,
+			},
+			expectedErrNum: 2,
+		},
+		{
+			name: "Replicas3MinClouds2",
+			app: models.K8sApp{
+				Name:          "Replicas3MinClouds2",
+				Priority:      9,
+				Replicas:      3,
+				MinClouds:     2,
+				AutoScheduled: true,
+			},
+			expectedErrNum: 1,
+		},
+		{
+			name: "Replicas2MinClouds3",
+			app: models.K8sApp{
+				Name:          "Replicas2MinClouds3",
+				Priority:      9,
+				Replicas:      2,
+				MinClouds:     3,
+				AutoScheduled: true,
+			},
+			expectedErrNum: 2,
+		},
+		{
+			name: "Replicas2MinClouds-1",
+			app: models.K8sApp{
+				Name:          "Replicas2MinClouds-1",
+				Priority:      9,
+				Replicas:      2,
+				MinClouds:     -1,
+				AutoScheduled: true,
+			},
+			expectedErrNum: 2,
+		},
+		{
+			name: "Replicas2WithNodeNames",
+			app: models.K8sApp{
+				Name:          "Replicas2WithNodeNames",
+				Priority:      9,
+				Replicas:      2,
+				NodeNames:     []string{"node1", "node2"},
+				AutoScheduled: true,
+			},
 			expectedErrNum: 2,
 		},
 		{
@@ -592,11 +646,61 @@ func TestValidateAutoScheduleApp(t *testing.T) {
 	testCases = append(testCases, testCasesContainer...)
 
 	// test cases about Container
+	genMultiContainerApp := func(name string, containerNames []string, sidecarRequestCpu string) models.K8sApp {
+		app := models.K8sApp{
+			Name:          name,
+			Priority:      10,
+			Replicas:      1,
+			AutoScheduled: true,
+		}
+		for i, containerName := range containerNames {
+			cpu := "1"
+			if i > 0 {
+				cpu = sidecarRequestCpu
+			}
+			app.Containers = append(app.Containers, models.K8sContainer{
+				Name: containerName,
+				Resources: models.K8sResReq{
+					Limits: models.K8sResList{
+						Memory:  "10Mi",
+						CPU:     "1",
+						Storage: "10Gi",
+					},
+					Requests: models.K8sResList{
+						Memory:  "10Mi",
+						CPU:     cpu,
+						Storage: "10Gi",
+					},
+				},
+			})
+		}
+		return app
+	}
 	testCasesMultiContainer := []oneTestCase{
 		{
-			name: "multiContainer",
+			name:           "multiContainer",
+			app:            genMultiContainerApp("multiContainer", []string{"main", "sidecar"}, "1"),
+			expectedErrNum: 0,
+		},
+		{
+			name:           "multiContainerSameName",
+			app:            genMultiContainerApp("multiContainerSameName", []string{"main", "main"}, "1"),
+			expectedErrNum: 1,
+		},
+		{
+			name:           "multiContainerSidecarInvalidRes",
+			app:            genMultiContainerApp("multiContainerSidecarInvalidRes", []string{"main", "proxy", "logger"}, "500m"),
+			expectedErrNum: 4, // 2 sidecars, each of them has a CPU not equal to the limit and not matching the regular expression
+		},
+	}
+	testCases = append(testCases, testCasesMultiContainer...)
+
+	// test no app name
+	testCasesNoAppName := []oneTestCase{
+		{
+			name: "no name",
 			app: models.K8sApp{
-				Name:          "multiContainer",
+				Name:          "",
 				Priority:      10,
 				Replicas:      1,
 				AutoScheduled: true,
@@ -615,6 +719,24 @@ func TestValidateAutoScheduleApp(t *testing.T) {
 							},
 						},
 					},
+				},
+			},
+			expectedErrNum: 1,
+		},
+	}
+	testCases = append(testCases, testCasesNoAppName...)
+
+	// test the expected computation time of an application
+	testCasesExpCompuTime := []oneTestCase{
+		{
+			name: "expCompuTime",
+			app: models.K8sApp{
+				Name:               "expCompuTime",
+				Priority:           10,
+				Replicas:           1,
+				AutoScheduled:      true,
+				ExpCompuTimeOneCpu: 2000,
+				Containers: []models.K8sContainer{
 					{
 						Resources: models.K8sResReq{
 							Limits: models.K8sResList{
@@ -631,20 +753,16 @@ func TestValidateAutoScheduleApp(t *testing.T) {
 					},
 				},
 			},
-			expectedErrNum: 1,
+			expectedErrNum: 0,
 		},
-	}
-	testCases = append(testCases, testCasesMultiContainer...)
-
-	// test no app name
-	testCasesNoAppName := []oneTestCase{
 		{
-			name: "no name",
+			name: "negativeExpCompuTime",
 			app: models.K8sApp{
-				Name:          "",
-				Priority:      10,
-				Replicas:      1,
-				AutoScheduled: true,
+				Name:               "negativeExpCompuTime",
+				Priority:           10,
+				Replicas:           1,
+				AutoScheduled:      true,
+				ExpCompuTimeOneCpu: -5,
 				Containers: []models.K8sContainer{
 					{
 						Resources: models.K8sResReq{
@@ -665,7 +783,7 @@ func TestValidateAutoScheduleApp(t *testing.T) {
 			expectedErrNum: 1,
 		},
 	}
-	testCases = append(testCases, testCasesNoAppName...)
+	testCases = append(testCases, testCasesExpCompuTime...)
 
 	for i, testCase := range testCases {
 		t.Logf("test: %d, %s", i, testCase.name)
@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
 	"sort"
 
-	"github.com/KeepTheBeats/routing-algorithms/random"
-
 	asmodel "emcontroller/auto-schedule/model"
 	"emcontroller/models"
 )
@@ -48,18 +48,46 @@ type appVars struct {
 	ports    []models.PortInfo
 }
 
-// make applications for some simple tests
-func MakeAppsForTest(namePrefix string, count int, possibleVars []appVars) []models.K8sApp {
+// generate int in [start,end], the same as random.RandomInt in "github.com/KeepTheBeats/routing-algorithms/random" but with the given generator
+func randomInt(rander *rand.Rand, start, end int) int {
+	return rander.Intn(end-start+1) + start
+}
+
+// generate float64 in [start,end), the same as random.RandomFloat64 in "github.com/KeepTheBeats/routing-algorithms/random" but with the given generator
+func randomFloat64(rander *rand.Rand, start, end float64) float64 {
+	return rander.Float64()*(end-start) + start
+}
+
+// generate a random number that follows Normal distribution through Box-Muller, between lowerBound and upperBound, the same as random.NormalRandomBM in "github.com/KeepTheBeats/routing-algorithms/random" but with the given generator
+func normalRandomBM(rander *rand.Rand, lowerBound, upperBound, miu, sigma float64) float64 {
+	if lowerBound > upperBound || miu < lowerBound || miu > upperBound {
+		return 0
+	}
+	for {
+		u := 1 - rander.Float64() // in the half-open interval (0.0,1.0]
+		v := 1 - rander.Float64() // in the half-open interval (0.0,1.0]
+
+		z := math.Sqrt(-2*math.Log(u)) * math.Cos(2*math.Pi*v) // log(u) is ln(u)
+
+		x := z*sigma + miu // z~N(0,1), so x~N(miu,sigma^2)
+		if x >= lowerBound && x <= upperBound {
+			return x
+		}
+	}
+}
+
+// make applications for some simple tests. All randomness comes from rander, so the same seed makes the same applications.
+func MakeAppsForTest(namePrefix string, count int, possibleVars []appVars, rander *rand.Rand) []models.K8sApp {
 	outApps := make([]models.K8sApp, count)
 
 	for i := 0; i < len(outApps); i++ {
-		randomVar := possibleVars[random.RandomInt(0, len(possibleVars)-1)]
+		randomVar := possibleVars[randomInt(rander, 0, len(possibleVars)-1)]
 
 		outApps[i].Name = fmt.Sprintf("%s-%d", namePrefix, i)
 		outApps[i].AutoScheduled = true
 		outApps[i].Replicas = 1
-		outApps[i].Priority = random.RandomInt(asmodel.MinPriority, asmodel.MaxPriority)
-		//outApps[i].HostNetwork = random.RandomInt(0, 1) == 0
+		outApps[i].Priority = randomInt(rander, asmodel.MinPriority, asmodel.MaxPriority)
+		//outApps[i].HostNetwork = randomInt(rander, 0, 1) == 0
 		outApps[i].HostNetwork = false
 		outApps[i].Containers = []models.K8sContainer{
 			models.K8sContainer{
@@ -70,14 +98,14 @@ func MakeAppsForTest(namePrefix string, count int, possibleVars []appVars) []mod
 				WorkDir:  "",
 				Resources: models.K8sResReq{
 					Limits: models.K8sResList{
-						CPU:    fmt.Sprintf("%.1f", random.NormalRandomBM(1.0, 32.0, 6.0, 6.0)),
-						Memory: fmt.Sprintf("%.0fMi", random.NormalRandomBM(200.0, 16384.0, 2048.0, 8192.0)),
+						CPU:    fmt.Sprintf("%.1f", normalRandomBM(rander, 1.0, 32.0, 6.0, 6.0)),
+						Memory: fmt.Sprintf("%.0fMi", normalRandomBM(rander, 200.0, 16384.0, 2048.0, 8192.0)),
 					},
 				},
 			},
 		}
 
-		resStorage := random.RandomInt(0, 200)
+		resStorage := randomInt(rander, 0, 200)
 		if resStorage > 0 {
 			outApps[i].Containers[0].Resources.Limits.Storage = fmt.Sprintf("%dGi", resStorage)
 		}
@@ -94,7 +122,7 @@ func MakeAppsForTest(namePrefix string, count int, possibleVars []appVars) []mod
 
 	for i := 0; i < len(depHelpers); i++ {
 		for j := i + 1; j < len(depHelpers); j++ {
-			if random.RandomInt(0, 3) == 0 {
+			if randomInt(rander, 0, 3) == 0 {
 				outApps[depHelpers[i].oriIdx].Dependencies = append(outApps[depHelpers[i].oriIdx].Dependencies, models.Dependency{AppName: depHelpers[j].appName})
 			}
 		}
@@ -192,16 +220,35 @@ func getAllApps(mcmEp string) ([]models.AppInfo, error) {
 	return apps, nil
 }
 
-// make applications for experiments
-func MakeExperimentApps(namePrefix string, count int, fastMode bool) ([]models.K8sApp, error) {
-	depDivisor := float64(count) / 10.0
-	outApps := make([]models.K8sApp, count)
-
+// make applications for experiments. All randomness comes from rander, so the same seed makes the same applications, apart from the NodePorts, which depend on the running applications.
+func MakeExperimentApps(namePrefix string, count int, fastMode bool, rander *rand.Rand) ([]models.K8sApp, error) {
 	occNodePorts, err := getOccupiedNodePorts(mcmEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("getOccupiedNodePorts from multi-cloud manager endpoint %s, error: %s", mcmEndpoint, err.Error())
 	}
 
+	outApps, err := makeExperimentApps(namePrefix, count, fastMode, occNodePorts, rander)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Generated Applications Json:")
+	fmt.Println(models.JsonString(outApps))
+
+	return outApps, nil
+}
+
+// make applications for experiments without multi-cloud manager, e.g., for the algorithms called in the same process, which do not deploy the applications. The NodePorts are not checked with the running applications.
+// With the same seed, the applications are the same as the ones made by MakeExperimentApps, apart from the NodePorts.
+func MakeOfflineExperimentApps(namePrefix string, count int, fastMode bool, rander *rand.Rand) ([]models.K8sApp, error) {
+	return makeExperimentApps(namePrefix, count, fastMode, map[string]string{}, rander)
+}
+
+// occNodePorts are the NodePorts occupied by the running applications, which cannot be used by the applications to make.
+func makeExperimentApps(namePrefix string, count int, fastMode bool, occNodePorts map[string]string, rander *rand.Rand) ([]models.K8sApp, error) {
+	depDivisor := float64(count) / 10.0
+	outApps := make([]models.K8sApp, count)
+
 	nextNodePortToTry := minNodePort
 
 	for i := 0; i < len(outApps); i++ {
@@ -221,7 +268,7 @@ func MakeExperimentApps(namePrefix string, count int, fastMode bool) ([]models.K
 		}
 
 		// choose one application randomly
-		chosenApp := appsToChoose[random.RandomInt(0, len(appsToChoose)-1)]
+		chosenApp := appsToChoose[randomInt(rander, 0, len(appsToChoose)-1)]
 
 		outApps[i].Name = fmt.Sprintf("%s-%d", namePrefix, i)
 		outApps[i].AutoScheduled = true
@@ -229,11 +276,11 @@ func MakeExperimentApps(namePrefix string, count int, fastMode bool) ([]models.K
 		outApps[i].HostNetwork = false
 
 		// randomly generate a priority between the min and max values
-		outApps[i].Priority = random.RandomInt(asmodel.MinPriority, asmodel.MaxPriority)
+		outApps[i].Priority = randomInt(rander, asmodel.MinPriority, asmodel.MaxPriority)
 
 		// measured from real applications.
 		// We do not need to use the same amount of workload, because in the comparison of response time, I only compare the applications accepted by both my algorithm and another algorithm.
-		workload := int(random.NormalRandomBM(55000, 1415000, 466267, 370555))
+		workload := int(normalRandomBM(rander, 55000, 1415000, 466267, 370555))
 		// workload := 381475 // To avoid the interference, all applications should have a same amount of workload.
 
 		args := []string{fmt.Sprintf("%d", workload), fmt.Sprintf("%d", chosenApp.cpu), fmt.Sprintf("%d", chosenApp.memory), fmt.Sprintf("%d", chosenApp.storage)}
@@ -287,7 +334,7 @@ func MakeExperimentApps(namePrefix string, count int, fastMode bool) ([]models.K
 			//if depHelpers[j].priority <= depHelpers[i].priority { // if we need to disable apps to depend on those with the equal priority with it, we can uncomment this if.
 			//	continue
 			//}
-			if random.RandomFloat64(0, 1) < depPoss {
+			if randomFloat64(rander, 0, 1) < depPoss {
 				depPoss /= depDivisor // the more dependencies an app has, the lower possibility it can have more deps.
 				// this will be read by the scheduling algorithm.
 				outApps[depHelpers[i].oriIdx].Dependencies = append(outApps[depHelpers[i].oriIdx].Dependencies, models.Dependency{AppName: depHelpers[j].appName})
@@ -297,8 +344,5 @@ func MakeExperimentApps(namePrefix string, count int, fastMode bool) ([]models.K
 		}
 	}
 
-	fmt.Println("Generated Applications Json:")
-	fmt.Println(models.JsonString(outApps))
-
 	return outApps, nil
 }
@@ -1,7 +1,9 @@
 package applicationsgenerator
 
 import (
+	"math/rand"
 	"testing"
+	"time"
 
 	"emcontroller/models"
 )
@@ -50,14 +52,14 @@ func TestMakeAppsForTest(t *testing.T) {
 		},
 	}
 
-	_ = MakeAppsForTest(namePrefix, count, possibleVars)
+	_ = MakeAppsForTest(namePrefix, count, possibleVars, rand.New(rand.NewSource(time.Now().UnixNano())))
 }
 
 func TestMakeExperimentApps(t *testing.T) {
 	var namePrefix string = "expt-app"
 	var count int = 100
 
-	_, err := MakeExperimentApps(namePrefix, count, false)
+	_, err := MakeExperimentApps(namePrefix, count, false, rand.New(rand.NewSource(time.Now().UnixNano())))
 	if err != nil {
 		t.Errorf("MakeExperimentApps error: %s", err.Error())
 	}
@@ -67,8 +69,30 @@ func TestMakeFastExperimentApps(t *testing.T) {
 	var namePrefix string = "expt-app"
 	var count int = 100
 
-	_, err := MakeExperimentApps(namePrefix, count, true)
+	_, err := MakeExperimentApps(namePrefix, count, true, rand.New(rand.NewSource(time.Now().UnixNano())))
 	if err != nil {
 		t.Errorf("MakeExperimentApps error: %s", err.Error())
 	}
 }
+
+func TestMakeOfflineExperimentApps(t *testing.T) {
+	var namePrefix string = "expt-app"
+	var count int = 100
+
+	apps, err := MakeOfflineExperimentApps(namePrefix, count, false, rand.New(rand.NewSource(20231017)))
+	if err != nil {
+		t.Fatalf("MakeOfflineExperimentApps error: %s", err.Error())
+	}
+	if len(apps) != count {
+		t.Errorf("MakeOfflineExperimentApps makes %d applications, but %d are expected", len(apps), count)
+	}
+
+	// the same seed should make the same applications
+	appsAgain, err := MakeOfflineExperimentApps(namePrefix, count, false, rand.New(rand.NewSource(20231017)))
+	if err != nil {
+		t.Fatalf("MakeOfflineExperimentApps error: %s", err.Error())
+	}
+	if models.JsonString(apps) != models.JsonString(appsAgain) {
+		t.Errorf("MakeOfflineExperimentApps makes different applications with the same seed")
+	}
+}
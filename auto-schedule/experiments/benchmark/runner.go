@@ -0,0 +1,160 @@
+package benchmark
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"emcontroller/auto-schedule/algorithms"
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+/**
+NOTE:
+The usable-accept-rate experiment sends scheduling requests to multi-cloud manager, which needs Kubernetes and real clouds. Runner calls the scheduling algorithms in this process instead, on the clouds generated from a topology, so that the algorithms can be evaluated on any machine, e.g., in CI.
+Runner evaluates a solution in the same way as multi-cloud manager: a solution is usable only if the algorithm returns it without error and it is Acceptable, and the fitness value is calculated by Mcssga.
+*/
+
+// Runner schedules applications with the algorithms in this process, always on the same clouds.
+type Runner struct {
+	Clouds map[string]asmodel.Cloud
+	Params algorithms.AlgoParams // the parameters of the algorithms, and the Seed is set by every scheduling
+}
+
+// NewRunner creates a Runner on the clouds generated from the topology.
+func NewRunner(spec TopologySpec, params algorithms.AlgoParams) (*Runner, error) {
+	clouds, err := GenerateClouds(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{Clouds: clouds, Params: params}, nil
+}
+
+// RunRecord is the result of scheduling a group of applications by an algorithm.
+type RunRecord struct {
+	Algorithm    string
+	Seed         int64
+	SchedTimeSec float64
+	Usable       bool
+	Fitness      float64 // the fitness value calculated by Mcssga, only meaningful when the solution is usable
+
+	AppCount                          int
+	AcceptedApps                      []models.AppInfo // only the names and priorities are set, sorted by names. Empty if the solution is not usable.
+	AppPriorityWeightedAcceptanceRate float64
+}
+
+// Schedule schedules the applications by the algorithm with the seed. An error is returned only if the algorithm cannot run, and an unusable solution is not an error.
+func (r *Runner) Schedule(algoName string, apps []models.K8sApp, seed int64) (RunRecord, error) {
+	appsForScheduling, err := asmodel.GenerateApplications(apps)
+	if err != nil {
+		return RunRecord{}, fmt.Errorf("generate input applications, error: %w", err)
+	}
+	// the same as multi-cloud manager
+	expandedApps := asmodel.ExpandReplicas(appsForScheduling)
+	appsOrder := algorithms.GenerateAppsOrder(expandedApps)
+	sort.Strings(appsOrder)
+
+	params := r.Params
+	params.Seed = seed
+	algo, err := algorithms.NewAlgorithm(algoName, params)
+	if err != nil {
+		return RunRecord{}, err
+	}
+
+	record := RunRecord{
+		Algorithm:    algoName,
+		Seed:         seed,
+		AppCount:     len(appsForScheduling),
+		AcceptedApps: []models.AppInfo{},
+	}
+
+	// every scheduling uses its own copy of the clouds, so that the Runner can be used many times.
+	clouds := asmodel.CloudMapCopy(r.Clouds)
+	startTime := time.Now()
+	solution, err := algo.Schedule(clouds, expandedApps, appsOrder)
+	record.SchedTimeSec = time.Since(startTime).Seconds()
+	if err != nil {
+		// e.g., the completely random algorithm returns an error when it gets an unusable solution, and multi-cloud manager also responds this as an unusable solution.
+		log.Printf("Algorithm [%s] with the seed [%d] gets an unusable solution, error: %s", algoName, seed, err.Error())
+		return record, nil
+	}
+	if !algorithms.Acceptable(clouds, expandedApps, appsOrder, solution) {
+		log.Printf("Algorithm [%s] with the seed [%d] gets an unacceptable solution.", algoName, seed)
+		return record, nil
+	}
+	record.Usable = true
+
+	mcssgaInstance := algorithms.NewMcssga(params.Ga.ChromosomesCount, params.Ga.IterationCount, params.Ga.CrossoverProbability, params.Ga.MutationProbability, params.Ga.StopNoUpdateIteration, params.ExpAppCompuTimeOneCpu)
+	mcssgaInstance.MaxHourlyCost = params.MaxHourlyCost
+	mcssgaInstance.SetMaxReaRtt(clouds)
+	mcssgaInstance.SetAvgDepNum(expandedApps)
+	record.Fitness = mcssgaInstance.Fitness(clouds, expandedApps, solution)
+
+	// The replicas of an application are accepted or rejected together, so we count an application once.
+	var totalPriority, acceptedPriority int
+	for _, appName := range sortedAppNames(appsForScheduling) {
+		app := appsForScheduling[appName]
+		totalPriority += app.Priority
+		if !solution.AppsSolution[app.SchedulingNames()[0]].Accepted {
+			continue
+		}
+		acceptedPriority += app.Priority
+		record.AcceptedApps = append(record.AcceptedApps, models.AppInfo{AppName: appName, Priority: app.Priority})
+	}
+	if totalPriority != 0 {
+		record.AppPriorityWeightedAcceptanceRate = float64(acceptedPriority) / float64(totalPriority)
+	}
+
+	return record, nil
+}
+
+// get the names of applications in order
+func sortedAppNames(apps map[string]asmodel.Application) []string {
+	names := make([]string, 0, len(apps))
+	for name := range apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteRunsCsv writes the records of every scheduling into a csv file, in addition to the summary of the experiment, so that the results of 2 versions of an algorithm can be compared scheduling by scheduling.
+func WriteRunsCsv(fileName string, records []RunRecord) error {
+	var csvContent [][]string = [][]string{{
+		"Algorithm Name",
+		"Seed",
+		"Scheduling Time (s)",
+		"Usable",
+		"App Count",
+		"Accepted App Count",
+		"App Priority Weighted Acceptance Rate",
+		"Fitness",
+	}}
+	for _, record := range records {
+		csvContent = append(csvContent, []string{
+			record.Algorithm,
+			fmt.Sprintf("%d", record.Seed),
+			fmt.Sprintf("%g", record.SchedTimeSec),
+			fmt.Sprintf("%t", record.Usable),
+			fmt.Sprintf("%d", record.AppCount),
+			fmt.Sprintf("%d", len(record.AcceptedApps)),
+			fmt.Sprintf("%g", record.AppPriorityWeightedAcceptanceRate),
+			fmt.Sprintf("%g", record.Fitness),
+		})
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("create file %s, error: %w", fileName, err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(csvContent); err != nil {
+		return fmt.Errorf("write file %s, error: %w", fileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package benchmark
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/auto-schedule/algorithms"
+	applicationsgenerator "emcontroller/auto-schedule/experiments/applications-generator"
+)
+
+// small GA parameters, so that the tests are fast
+var paramsForTest algorithms.AlgoParams = algorithms.AlgoParams{
+	ExpAppCompuTimeOneCpu: algorithms.DefaultExpAppCompuTimeOneCpu,
+	Ga:                    algorithms.GaParams{ChromosomesCount: 20, IterationCount: 20, CrossoverProbability: 0.7, MutationProbability: 0.02, StopNoUpdateIteration: 20},
+	Pareto:                algorithms.DefaultParetoSelection,
+}
+
+func TestRunnerSchedule(t *testing.T) {
+	runner, err := NewRunner(topologyForTest(), paramsForTest)
+	assert.Nil(t, err)
+
+	apps, err := applicationsgenerator.MakeOfflineExperimentApps("expt-app", 30, false, rand.New(rand.NewSource(20231017)))
+	assert.Nil(t, err)
+
+	for _, algoName := range []string{algorithms.BERandName, algorithms.McssgaName} {
+		t.Logf("test: %s", algoName)
+		record, err := runner.Schedule(algoName, apps, 20231017)
+		assert.Nil(t, err)
+		assert.Equal(t, algoName, record.Algorithm)
+		assert.Equal(t, 30, record.AppCount)
+		assert.True(t, record.Usable, fmt.Sprintf("%s: the solution should be usable", algoName))
+		assert.True(t, record.AppPriorityWeightedAcceptanceRate >= 0 && record.AppPriorityWeightedAcceptanceRate <= 1)
+
+		// the runner does not change its clouds, so the same seed gives the same record.
+		recordAgain, err := runner.Schedule(algoName, apps, 20231017)
+		assert.Nil(t, err)
+		assert.Equal(t, record.AcceptedApps, recordAgain.AcceptedApps, fmt.Sprintf("%s: different accepted applications with the same seed", algoName))
+		assert.Equal(t, record.Fitness, recordAgain.Fitness, fmt.Sprintf("%s: different fitness with the same seed", algoName))
+	}
+
+	_, err = runner.Schedule("noSuchAlgorithm", apps, 20231017)
+	assert.ErrorIs(t, err, algorithms.ErrUnknownAlgorithm)
+}
+
+// Exact is the reference of the algorithms refining solutions by RefineSoln: they should not get higher fitness values. The algorithms for comparison may allocate more CPU cores than requested, so they are not compared.
+func TestExactReference(t *testing.T) {
+	runner, err := NewRunner(topologyForTest(), paramsForTest)
+	assert.Nil(t, err)
+
+	for _, seed := range []int64{1, 2, 3} {
+		apps, err := applicationsgenerator.MakeOfflineExperimentApps("expt-app", 6, false, rand.New(rand.NewSource(seed)))
+		assert.Nil(t, err)
+
+		exactRecord, err := runner.Schedule(algorithms.ExactName, apps, seed)
+		assert.Nil(t, err)
+		assert.True(t, exactRecord.Usable, fmt.Sprintf("seed %d: the solution of Exact should be usable", seed))
+
+		for _, algoName := range []string{algorithms.McssgaName, algorithms.Nsga2Name} {
+			t.Logf("test: seed %d, %s", seed, algoName)
+			record, err := runner.Schedule(algoName, apps, seed)
+			assert.Nil(t, err)
+			assert.LessOrEqual(t, record.Fitness, exactRecord.Fitness+0.0001, fmt.Sprintf("seed %d: %s should not be better than Exact", seed, algoName))
+		}
+	}
+}
+
+func TestWriteRunsCsv(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "runs.csv")
+	err := WriteRunsCsv(fileName, []RunRecord{
+		{Algorithm: algorithms.McssgaName, Seed: 1, SchedTimeSec: 0.5, Usable: true, Fitness: 100, AppCount: 3, AppPriorityWeightedAcceptanceRate: 0.5},
+		{Algorithm: algorithms.CompRandName, Seed: 1, SchedTimeSec: 0.1, Usable: false, AppCount: 3},
+	})
+	assert.Nil(t, err)
+	content, err := os.ReadFile(fileName)
+	assert.Nil(t, err)
+	expected := "Algorithm Name,Seed,Scheduling Time (s),Usable,App Count,Accepted App Count,App Priority Weighted Acceptance Rate,Fitness\n" +
+		"Mcssga,1,0.5,true,3,0,0.5,100\n" +
+		"CompRand,1,0.1,false,3,0,0,0\n"
+	assert.Equal(t, expected, string(content))
+}
+
+// Evaluate the algorithms on the test topology, e.g., to compare an algorithm before and after a change:
+// go test ./auto-schedule/experiments/benchmark/ -run NONE -bench BenchmarkAlgorithms -benchtime 10x
+func BenchmarkAlgorithms(b *testing.B) {
+	runner, err := NewRunner(topologyForTest(), paramsForTest)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, algoName := range []string{algorithms.CompRandName, algorithms.BERandName, algorithms.AmagaName, algorithms.AmpgaName, algorithms.DiktyogaName, algorithms.McssgaName, algorithms.GreedyName, algorithms.AnnealingName} {
+		b.Run(algoName, func(b *testing.B) {
+			var usableCount int
+			var weightedAccSum, fitnessSum float64
+			for i := 0; i < b.N; i++ {
+				// every iteration uses different applications, and all algorithms use the same ones.
+				apps, err := applicationsgenerator.MakeOfflineExperimentApps("expt-app", 60, false, rand.New(rand.NewSource(int64(i))))
+				if err != nil {
+					b.Fatal(err)
+				}
+				record, err := runner.Schedule(algoName, apps, int64(i))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if record.Usable {
+					usableCount++
+					weightedAccSum += record.AppPriorityWeightedAcceptanceRate
+					fitnessSum += record.Fitness
+				}
+			}
+			b.ReportMetric(float64(usableCount)/float64(b.N), "usable-rate")
+			if usableCount != 0 {
+				b.ReportMetric(weightedAccSum/float64(usableCount), "weighted-acc-rate")
+				b.ReportMetric(fitnessSum/float64(usableCount), "fitness")
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	asmodel "emcontroller/auto-schedule/model"
+	"emcontroller/models"
+)
+
+// TopologySpec describes the clouds on which the algorithms are evaluated, instead of the real clouds managed by multi-cloud manager.
+type TopologySpec struct {
+	IntraCloudRtt float64     `json:"intraCloudRtt"` // unit millisecond (ms), the RTT between 2 VMs on the same cloud, which is also added to the RTT between 2 clouds
+	Clouds        []CloudSpec `json:"clouds"`
+}
+
+// CloudSpec describes a cloud in the topology.
+// The same as "experiments/gen-net-delay", which adds a delay on every cloud, the RTT between 2 clouds is IntraCloudRtt plus the delays of both clouds.
+type CloudSpec struct {
+	Name     string               `json:"name"`
+	Type     string               `json:"type"`  // empty means models.SimulatedIaas
+	Delay    float64              `json:"delay"` // unit millisecond (ms)
+	Limit    models.ResSet        `json:"limit"`
+	InUse    models.ResSet        `json:"inUse"`
+	K8sNodes []asmodel.K8sNode    `json:"k8sNodes,omitempty"` // the existing Kubernetes nodes on this cloud
+	Flavors  []models.VmFlavor    `json:"flavors,omitempty"`
+	Pricing  *models.CloudPricing `json:"pricing,omitempty"`
+}
+
+// LoadTopology reads a topology from a json file.
+func LoadTopology(fileName string) (TopologySpec, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return TopologySpec{}, fmt.Errorf("read file [%s], error: %w", fileName, err)
+	}
+	var spec TopologySpec
+	if err := json.Unmarshal(content, &spec); err != nil {
+		return TopologySpec{}, fmt.Errorf("json.Unmarshal file [%s], error: %w", fileName, err)
+	}
+	return spec, nil
+}
+
+// ValidateTopology checks whether the clouds can be generated from the topology.
+func ValidateTopology(spec TopologySpec) []error {
+	var errs []error
+	if len(spec.Clouds) == 0 {
+		errs = append(errs, fmt.Errorf("the topology has no clouds"))
+	}
+	if spec.IntraCloudRtt < 0 {
+		errs = append(errs, fmt.Errorf("intraCloudRtt [%g] should not be negative", spec.IntraCloudRtt))
+	}
+	names := make(map[string]struct{})
+	for i, cloud := range spec.Clouds {
+		if len(cloud.Name) == 0 {
+			errs = append(errs, fmt.Errorf("cloud No. %d has no name", i))
+			continue
+		}
+		if _, exist := names[cloud.Name]; exist {
+			errs = append(errs, fmt.Errorf("cloud name [%s] is duplicate", cloud.Name))
+		}
+		names[cloud.Name] = struct{}{}
+		if cloud.Delay < 0 {
+			errs = append(errs, fmt.Errorf("cloud [%s]: delay [%g] should not be negative", cloud.Name, cloud.Delay))
+		}
+	}
+	return errs
+}
+
+// GenerateClouds makes the input clouds of the scheduling algorithms from the topology.
+func GenerateClouds(spec TopologySpec) (map[string]asmodel.Cloud, error) {
+	if errs := ValidateTopology(spec); len(errs) != 0 {
+		return nil, fmt.Errorf("the topology is invalid, error: %w", models.HandleErrSlice(errs))
+	}
+
+	clouds := make(map[string]asmodel.Cloud)
+	for _, cloudSpec := range spec.Clouds {
+		cloudType := cloudSpec.Type
+		if len(cloudType) == 0 {
+			cloudType = models.SimulatedIaas
+		}
+
+		netState := make(map[string]models.NetworkState)
+		for _, dstSpec := range spec.Clouds {
+			rtt := spec.IntraCloudRtt
+			if dstSpec.Name != cloudSpec.Name {
+				rtt += cloudSpec.Delay + dstSpec.Delay
+			}
+			netState[dstSpec.Name] = models.NetworkState{Rtt: rtt}
+		}
+
+		clouds[cloudSpec.Name] = asmodel.Cloud{
+			Name: cloudSpec.Name,
+			Type: cloudType,
+			Resources: models.ResourceStatus{
+				Limit: cloudSpec.Limit,
+				InUse: cloudSpec.InUse,
+			},
+			NetState: netState,
+			K8sNodes: cloudSpec.K8sNodes,
+			Flavors:  cloudSpec.Flavors,
+			Pricing:  cloudSpec.Pricing,
+		}
+	}
+	return clouds, nil
+}
@@ -0,0 +1,100 @@
+package benchmark
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/models"
+)
+
+// a small topology for tests
+func topologyForTest() TopologySpec {
+	return TopologySpec{
+		IntraCloudRtt: 1,
+		Clouds: []CloudSpec{
+			{
+				Name:  "cloud1",
+				Delay: 0,
+				Limit: models.ResSet{VCpu: 56, Ram: 128796, Vm: -1, Volume: -1, Storage: 831, Port: -1},
+				InUse: models.ResSet{VCpu: 20, Ram: 8192, Vm: -1, Volume: -1, Storage: 60, Port: -1},
+			},
+			{
+				Name:  "cloud2",
+				Type:  models.ProxmoxIaas,
+				Delay: 10,
+				Limit: models.ResSet{VCpu: 40, Ram: 64288, Vm: -1, Volume: -1, Storage: 793, Port: -1},
+				InUse: models.ResSet{VCpu: 15, Ram: 8192, Vm: -1, Volume: -1, Storage: 60, Port: -1},
+			},
+			{
+				Name:  "cloud3",
+				Delay: 25,
+				Limit: models.ResSet{VCpu: 56, Ram: 128796, Vm: -1, Volume: -1, Storage: 831, Port: -1},
+				InUse: models.ResSet{VCpu: 30, Ram: 32768, Vm: -1, Volume: -1, Storage: 460, Port: -1},
+			},
+		},
+	}
+}
+
+func TestGenerateClouds(t *testing.T) {
+	clouds, err := GenerateClouds(topologyForTest())
+	assert.Nil(t, err)
+	assert.Len(t, clouds, 3)
+
+	assert.Equal(t, models.SimulatedIaas, clouds["cloud1"].Type, "empty type should be simulated")
+	assert.Equal(t, models.ProxmoxIaas, clouds["cloud2"].Type)
+
+	// RTT is IntraCloudRtt plus the delays of both clouds
+	expectedRtts := map[string]map[string]float64{
+		"cloud1": {"cloud1": 1, "cloud2": 11, "cloud3": 26},
+		"cloud2": {"cloud1": 11, "cloud2": 1, "cloud3": 36},
+		"cloud3": {"cloud1": 26, "cloud2": 36, "cloud3": 1},
+	}
+	for src, dsts := range expectedRtts {
+		for dst, rtt := range dsts {
+			assert.Equal(t, rtt, clouds[src].NetState[dst].Rtt, fmt.Sprintf("RTT from %s to %s is not expected", src, dst))
+		}
+	}
+}
+
+func TestValidateTopology(t *testing.T) {
+	testCases := []struct {
+		name      string
+		modify    func(spec *TopologySpec)
+		errsCount int
+	}{
+		{
+			name:      "case valid",
+			modify:    func(spec *TopologySpec) {},
+			errsCount: 0,
+		},
+		{
+			name:      "case no clouds",
+			modify:    func(spec *TopologySpec) { spec.Clouds = nil },
+			errsCount: 1,
+		},
+		{
+			name:      "case duplicate name",
+			modify:    func(spec *TopologySpec) { spec.Clouds[1].Name = "cloud1" },
+			errsCount: 1,
+		},
+		{
+			name: "case empty name and negative values",
+			modify: func(spec *TopologySpec) {
+				spec.IntraCloudRtt = -1
+				spec.Clouds[0].Name = ""
+				spec.Clouds[2].Delay = -5
+			},
+			errsCount: 3,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		spec := topologyForTest()
+		testCase.modify(&spec)
+		errs := ValidateTopology(spec)
+		assert.Len(t, errs, testCase.errsCount, fmt.Sprintf("%s: errors are not expected: %v", testCase.name, errs))
+	}
+}
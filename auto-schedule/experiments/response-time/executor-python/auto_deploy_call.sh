@@ -39,7 +39,7 @@ do
     algo_path="${repeat_path}/${algo_name}"
     print_log "algorithm path is: ${algo_path}"
 
-    curl_cmd="curl -i -X POST -H Content-Type:application/json -H Mcm-Scheduling-Algorithm:${algo_name} -H Expected-Time-One-Cpu:42.629 -d @${json_file_path} http://${MCM_EP}/doNewAppGroup"
+    curl_cmd="curl -i -X POST -H Content-Type:application/json -H Mcm-Scheduling-Algorithm:${algo_name} -H Expected-Time-One-Cpu:42.629 -H Mcm-Async:false -d @${json_file_path} http://${MCM_EP}/doNewAppGroup"
 
      # execute the curl command to deploy applications
     print_log "Execute command: ${curl_cmd}"
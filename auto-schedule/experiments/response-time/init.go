@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"math/rand"
 	"os"
 	"path/filepath"
 
@@ -17,6 +18,7 @@ var (
 
 	algorithms  []string = []string{"BERand", "Amaga", "Ampga", "Diktyoga", "Mcssga"}
 	repeatCount int      = 60
+	baseSeed    int64    = 0 // the seed of repeat i is baseSeed+i
 
 	dataPath     string = "executor-python/data"
 	jsonFileName string = "request_applications.json"
@@ -45,7 +47,8 @@ func main() {
 		}
 
 		// create the json file to save the request body to deploy applications
-		apps, err := applicationsgenerator.MakeExperimentApps(appNamePrefix, appCount, true)
+		// the seed of every repeat is fixed, so that the applications can be made again.
+		apps, err := applicationsgenerator.MakeExperimentApps(appNamePrefix, appCount, true, rand.New(rand.NewSource(baseSeed+int64(i))))
 		if err != nil {
 			log.Panicf("MakeExperimentApps error: %s", err.Error())
 		}
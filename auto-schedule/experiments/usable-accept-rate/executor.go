@@ -4,22 +4,32 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"emcontroller/auto-schedule/algorithms"
 	applicationsgenerator "emcontroller/auto-schedule/experiments/applications-generator"
+	"emcontroller/auto-schedule/experiments/benchmark"
 	asmodel "emcontroller/auto-schedule/model"
 	"emcontroller/models"
 )
 
 const dataFileNameFmt string = "usable_acceptance_rate_%d.csv"
 
+// the records of every scheduling, only in the offline mode
+const runsFileNameFmt string = "usable_acceptance_rate_%d_runs.csv"
+
+// the seed of repeat i is baseSeed+i
+const baseSeed int64 = 0
+
 // the data structure that will be collected in this experiment
 type exptData struct {
 	algorithmName string
@@ -47,22 +57,49 @@ type exptData struct {
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
+	live := flag.Bool("live", false, "Send the scheduling requests to multi-cloud manager at localhost:20000, instead of calling the algorithms in this process.")
+	topologyFile := flag.String("topology", "topology.json", "The topology to generate the clouds, when the algorithms are called in this process.")
+	flag.Parse()
+
 	var appCounts []int = []int{60, 100, 140}
 	var repeatCount int = 50 // We repeat this experiment 50 times to reduce the impact from random factors, because the paper of Diktyo repeat one of their experiments 50 times.
 
+	// Without -live, the algorithms are called in this process on the generated clouds, which needs neither multi-cloud manager nor real clouds.
+	var runner *benchmark.Runner
+	if !*live {
+		spec, err := benchmark.LoadTopology(*topologyFile)
+		if err != nil {
+			log.Panicf("LoadTopology error: %s", err.Error())
+		}
+		runner, err = benchmark.NewRunner(spec, algorithms.AlgoParams{
+			ExpAppCompuTimeOneCpu: 42.629, // the same as the header "Expected-Time-One-Cpu" in schedulingRequest
+			Ga:                    algorithms.DefaultGaParams,
+			Pareto:                algorithms.DefaultParetoSelection,
+		})
+		if err != nil {
+			log.Panicf("NewRunner error: %s", err.Error())
+		}
+	}
+
 	for _, appCount := range appCounts {
-		Execute(appCount, repeatCount)
+		Execute(appCount, repeatCount, runner)
 	}
 
 }
 
-func Execute(appCount, repeatCount int) {
+// If runner is nil, the scheduling requests are sent to multi-cloud manager; otherwise, the algorithms are called by the runner in this process.
+func Execute(appCount, repeatCount int, runner *benchmark.Runner) {
 	var appNamePrefix string = "expt-app"
 
 	// all algorithms to be evaluated in experiment
-	var algoNames []string = []string{algorithms.CompRandName, algorithms.BERandName, algorithms.AmagaName, algorithms.AmpgaName, algorithms.DiktyogaName, algorithms.McssgaName}
+	var algoNames []string = []string{algorithms.CompRandName, algorithms.BERandName, algorithms.AmagaName, algorithms.AmpgaName, algorithms.DiktyogaName, algorithms.McssgaName, algorithms.GreedyName, algorithms.AnnealingName}
+	// Exact finds the optimal solutions as the reference, but it is only practical for small application counts.
+	if appCount <= algorithms.ExactMaxAppCount {
+		algoNames = append(algoNames, algorithms.ExactName)
+	}
 
-	var results []exptData // used to save and output results
+	var results []exptData               // used to save and output results
+	var runRecords []benchmark.RunRecord // the records of every scheduling, only with runner
 	for _, algoName := range algoNames {
 		results = append(results, exptData{
 			algorithmName: algoName, maxSchedTime: 0, appCountPerPri: make(map[int]int), acceptedAppCountPerPri: make(map[int]int), appPerPriAcceptanceRate: make(map[int]float64),
@@ -71,16 +108,36 @@ func Execute(appCount, repeatCount int) {
 
 	// We repeat experiment to reduce the impact from random factors. In every repeat, we generate different applications.
 	for i := 0; i < repeatCount; i++ {
-		apps, err := applicationsgenerator.MakeExperimentApps(appNamePrefix, appCount, false)
+		// In one repeat, the applications and all algorithms use the same seed, so that the randomness is paired between algorithms, and the repeat can be reproduced.
+		seed := baseSeed + int64(i)
+		var apps []models.K8sApp
+		var err error
+		if runner == nil {
+			apps, err = applicationsgenerator.MakeExperimentApps(appNamePrefix, appCount, false, rand.New(rand.NewSource(seed)))
+		} else {
+			apps, err = applicationsgenerator.MakeOfflineExperimentApps(appNamePrefix, appCount, false, rand.New(rand.NewSource(seed)))
+		}
 		if err != nil {
 			log.Panicf("MakeExperimentApps error: %s", err.Error())
 		}
 		for j, algoName := range algoNames { // in one repeat, we use the same apps for all algorithm for comparison.
 			log.Printf("Schedule %d applications, Repeat %d, algorithm No. %d [%s]", appCount, i, j, algoName)
 
-			acceptedApps, usable, schedTimeSec, err := schedulingRequest(algoName, apps)
-			if err != nil {
-				log.Panicf("schedulingRequest error: %s", err.Error())
+			var acceptedApps []models.AppInfo
+			var usable bool
+			var schedTimeSec float64
+			if runner == nil {
+				acceptedApps, usable, schedTimeSec, err = schedulingRequest(algoName, apps, seed)
+				if err != nil {
+					log.Panicf("schedulingRequest error: %s", err.Error())
+				}
+			} else {
+				record, err := runner.Schedule(algoName, apps, seed)
+				if err != nil {
+					log.Panicf("runner.Schedule error: %s", err.Error())
+				}
+				runRecords = append(runRecords, record)
+				acceptedApps, usable, schedTimeSec = record.AcceptedApps, record.Usable, record.SchedTimeSec
 			}
 
 			// record results
@@ -125,10 +182,15 @@ func Execute(appCount, repeatCount int) {
 	if err := writeCsvResults(results, appCount); err != nil {
 		log.Panicf("writeCsvResults error: %s", err.Error())
 	}
+	if runner != nil {
+		if err := benchmark.WriteRunsCsv(fmt.Sprintf(runsFileNameFmt, appCount), runRecords); err != nil {
+			log.Panicf("WriteRunsCsv error: %s", err.Error())
+		}
+	}
 
 }
 
-func schedulingRequest(algoName string, apps []models.K8sApp) ([]models.AppInfo, bool, float64, error) {
+func schedulingRequest(algoName string, apps []models.K8sApp, seed int64) ([]models.AppInfo, bool, float64, error) {
 	url := "http://localhost:20000/doNewAppGroup"
 
 	reqBodyJson, err := json.Marshal(apps)
@@ -145,6 +207,9 @@ func schedulingRequest(algoName string, apps []models.K8sApp) ([]models.AppInfo,
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Mcm-Scheduling-Algorithm", algoName)
 	req.Header.Set("Expected-Time-One-Cpu", "42.629")
+	req.Header.Set("Mcm-Seed", strconv.FormatInt(seed, 10))
+	// the scheduling time is measured by waiting for the response, so the request should not run as an asynchronous task.
+	req.Header.Set("Mcm-Async", "false")
 
 	timeBefore := time.Now()
 	res, err := http.DefaultClient.Do(req)
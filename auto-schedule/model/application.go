@@ -1,11 +1,13 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/astaxie/beego"
+	appsv1 "k8s.io/api/apps/v1"
 
 	"emcontroller/models"
 )
@@ -21,6 +23,18 @@ type Application struct {
 	Priority     int                 `json:"priority"`
 	Resources    AppResources        `json:"resources"`    // The resources information of this application
 	Dependencies []models.Dependency `json:"dependencies"` // The information of all applications that this application depends on.
+	// The expected computation time of this application by one CPU core, unit millisecond (ms). If it is 0, the algorithm uses the time set for all applications.
+	ExpCompuTimeOneCpu float64 `json:"expCompuTimeOneCpu,omitempty"`
+	// The number of replicas of this application. 0 and 1 both mean a single replica.
+	Replicas int `json:"replicas,omitempty"`
+	// The replicas of this application should be spread across at least so many clouds.
+	MinClouds int `json:"minClouds,omitempty"`
+	// If this is a replica expanded by ExpandReplicas, this is the name of the original application.
+	ReplicaOf string `json:"replicaOf,omitempty"`
+	// The hard constraints about which clouds this application can be scheduled to. The application names in it are the original names, not the names of replicas.
+	Placement models.Placement `json:"placement,omitempty"`
+	// The CPU cores requested by every container of this application, in the order of the containers. When this application is migrated, the allocated CPU is split to the containers again in proportion to them.
+	ContainersCpu []float64 `json:"containersCpu,omitempty"`
 }
 
 func AppCopy(src Application) Application {
@@ -31,6 +45,10 @@ func AppCopy(src Application) Application {
 		dst.Dependencies = make([]models.Dependency, len(src.Dependencies))
 		copy(dst.Dependencies, src.Dependencies)
 	}
+	if src.ContainersCpu != nil {
+		dst.ContainersCpu = make([]float64, len(src.ContainersCpu))
+		copy(dst.ContainersCpu, src.ContainersCpu)
+	}
 	return dst
 }
 
@@ -49,6 +67,7 @@ func GenerateApplications(inputApps []models.K8sApp) (map[string]Application, er
 
 		// traverse containers to calculate the resources requested by this applications
 		var resources AppResources
+		var containersCpu []float64
 		for _, container := range inApp.Containers {
 			floatCpu, err := strconv.ParseFloat(container.Resources.Requests.CPU, 64)
 			if err != nil {
@@ -78,6 +97,7 @@ func GenerateApplications(inputApps []models.K8sApp) (map[string]Application, er
 			}
 
 			resources.CpuCore += floatCpu
+			containersCpu = append(containersCpu, floatCpu)
 			resources.Memory += floatRamMi
 			resources.Storage += floatStorGi
 		}
@@ -88,8 +108,36 @@ func GenerateApplications(inputApps []models.K8sApp) (map[string]Application, er
 		thisOutApp.Priority = inApp.Priority
 		thisOutApp.Resources = resources
 		thisOutApp.Dependencies = inApp.Dependencies
+		thisOutApp.ExpCompuTimeOneCpu = inApp.ExpCompuTimeOneCpu
+		thisOutApp.Replicas = int(inApp.Replicas)
+		thisOutApp.MinClouds = inApp.MinClouds
+		thisOutApp.Placement = inApp.Placement
+		thisOutApp.ContainersCpu = containersCpu
 		outApps[thisOutApp.Name] = thisOutApp
 	}
 
 	return outApps, nil
 }
+
+// For migration, we get the information needed for auto-scheduling from the Annotation with key variable "AutoScheduleInfoAnno" of a running Kubernetes Deployment.
+func GenerateAppFromDeploy(deploy appsv1.Deployment) (Application, error) {
+	if deploy.Annotations[models.AutoScheduledAnno] != "true" {
+		outErr := fmt.Errorf("Deployment [%s] is not auto-scheduled", deploy.Name)
+		beego.Error(outErr)
+		return Application{}, outErr
+	}
+	infoJson, exist := deploy.Annotations[models.AutoScheduleInfoAnno]
+	if !exist {
+		outErr := fmt.Errorf("Deployment [%s] does not have the annotation [%s]", deploy.Name, models.AutoScheduleInfoAnno)
+		beego.Error(outErr)
+		return Application{}, outErr
+	}
+
+	var app Application
+	if err := json.Unmarshal([]byte(infoJson), &app); err != nil {
+		outErr := fmt.Errorf("Deployment [%s] annotation [%s] value [%s] json.Unmarshal, Error: [%w]", deploy.Name, models.AutoScheduleInfoAnno, infoJson, err)
+		beego.Error(outErr)
+		return Application{}, outErr
+	}
+	return app, nil
+}
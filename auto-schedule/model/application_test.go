@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"emcontroller/models"
 )
@@ -205,3 +207,106 @@ func TestAppMapCopy(t *testing.T) {
 	}
 
 }
+
+func TestGenerateAppFromDeploy(t *testing.T) {
+	testCases := []struct {
+		name           string
+		deploy         appsv1.Deployment
+		expectedResult Application
+		expectErr      bool
+	}{
+		{
+			name: "case auto-scheduled",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app1",
+					Annotations: map[string]string{
+						models.AutoScheduledAnno:    "true",
+						models.PriorityAnno:         "5",
+						models.AutoScheduleInfoAnno: `{"name":"app1","priority":5,"resources":{"cpuCore":2,"memory":1024,"storage":20},"dependencies":[{"appName":"app2"}]}`,
+					},
+				},
+			},
+			expectedResult: Application{
+				Name:      "app1",
+				Priority:  5,
+				Resources: AppResources{GenericResources: GenericResources{CpuCore: 2, Memory: 1024, Storage: 20}},
+				Dependencies: []models.Dependency{
+					{AppName: "app2"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "case not auto-scheduled",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app1",
+				},
+			},
+			expectedResult: Application{},
+			expectErr:      true,
+		},
+		{
+			name: "case without info annotation",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app1",
+					Annotations: map[string]string{
+						models.AutoScheduledAnno: "true",
+					},
+				},
+			},
+			expectedResult: Application{},
+			expectErr:      true,
+		},
+		{
+			name: "case invalid info annotation",
+			deploy: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app1",
+					Annotations: map[string]string{
+						models.AutoScheduledAnno:    "true",
+						models.AutoScheduleInfoAnno: "{",
+					},
+				},
+			},
+			expectedResult: Application{},
+			expectErr:      true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult, err := GenerateAppFromDeploy(testCase.deploy)
+		assert.Equal(t, testCase.expectErr, err != nil, fmt.Sprintf("%s: error is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestGenerateApplicationsMultiContainer(t *testing.T) {
+	inputApps := []models.K8sApp{
+		{
+			Name:     "app1",
+			Priority: 5,
+			Replicas: 2,
+			Containers: []models.K8sContainer{
+				{Name: "main", Resources: models.K8sResReq{Requests: models.K8sResList{CPU: "2", Memory: "512Mi", Storage: "10Gi"}}},
+				{Name: "proxy", Resources: models.K8sResReq{Requests: models.K8sResList{CPU: "1", Memory: "64Mi"}}},
+			},
+		},
+	}
+	expectedApps := map[string]Application{
+		"app1": {
+			Name:      "app1",
+			Priority:  5,
+			Replicas:  2,
+			Resources:     AppResources{GenericResources: GenericResources{CpuCore: 3, Memory: 576, Storage: 10}},
+			ContainersCpu: []float64{2, 1},
+		},
+	}
+
+	actualApps, err := GenerateApplications(inputApps)
+	assert.Nil(t, err, "GenerateApplications should not return error")
+	assert.Equal(t, expectedApps, actualApps, "result is not expected")
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/astaxie/beego"
@@ -15,40 +16,140 @@ import (
 type Cloud struct {
 	Name      string                         `json:"name"`
 	Type      string                         `json:"type"`
-	Resources models.ResourceStatus          `json:"resources"` // used and all resources of this cloud. Here we start with struct defined in "models" package, and in the future if we find that this cannot meet the needs here, we can define new structs.
-	NetState  map[string]models.NetworkState `json:"netState"`  // the network state from this cloud to every cloud
-	K8sNodes  []K8sNode                      `json:"k8sNodes"`  // all existing Kubernetes nodes whose VMs are on this cloud
+	Resources models.ResourceStatus          `json:"resources"`         // used and all resources of this cloud. Here we start with struct defined in "models" package, and in the future if we find that this cannot meet the needs here, we can define new structs.
+	NetState  map[string]models.NetworkState `json:"netState"`          // the network state from this cloud to every cloud
+	K8sNodes  []K8sNode                      `json:"k8sNodes"`          // all existing Kubernetes nodes whose VMs are on this cloud
+	Flavors   []models.VmFlavor              `json:"flavors,omitempty"` // the sizes of VMs that can be created on this cloud. Empty means that VMs of any size can be created.
+	Pricing   *models.CloudPricing           `json:"pricing,omitempty"` // the price of the resources of this cloud. nil means that this cloud does not set pricing, and its resources cost nothing in scheduling.
+	// The running applications that the applications to schedule depend on. The key is the application name, and the value is the sorted names of the Kubernetes nodes on this cloud where it has pods. Their places are fixed in scheduling, so this map is not changed by the algorithms, and the copies of a cloud share it.
+	RunningApps map[string][]string `json:"runningApps,omitempty"`
+}
+
+// AppLocation is a Kubernetes node where an application has pods.
+type AppLocation struct {
+	CloudName   string `json:"cloudName"`
+	K8sNodeName string `json:"k8sNodeName"`
+}
+
+// SetRunningApps records the places of the running applications on the clouds. The key of the input map is the application name. The places on unknown clouds are ignored.
+func SetRunningApps(clouds map[string]Cloud, runningApps map[string][]AppLocation) {
+	for appName, locations := range runningApps {
+		for _, location := range locations {
+			cloud, exist := clouds[location.CloudName]
+			if !exist {
+				continue
+			}
+			if cloud.RunningApps == nil {
+				cloud.RunningApps = make(map[string][]string)
+			}
+			cloud.RunningApps[appName] = append(cloud.RunningApps[appName], location.K8sNodeName)
+			sort.Strings(cloud.RunningApps[appName])
+			clouds[location.CloudName] = cloud
+		}
+	}
+}
+
+// RunningAppLocations returns where a running application has pods, sorted by the names of clouds and nodes. If it is not running, the output is empty.
+func RunningAppLocations(clouds map[string]Cloud, appName string) []AppLocation {
+	var locations []AppLocation
+	for cloudName, cloud := range clouds {
+		for _, nodeName := range cloud.RunningApps[appName] {
+			locations = append(locations, AppLocation{CloudName: cloudName, K8sNodeName: nodeName})
+		}
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].CloudName != locations[j].CloudName {
+			return locations[i].CloudName < locations[j].CloudName
+		}
+		return locations[i].K8sNodeName < locations[j].K8sNodeName
+	})
+	return locations
 }
 
 // the set of all cloud types that support creating new VMs when auto-scheduling
 var typesCanCreateNewVM map[string]struct{} = map[string]struct{}{
-	models.ProxmoxIaas: struct{}{},
+	models.OpenstackIaas: struct{}{},
+	models.ProxmoxIaas:   struct{}{},
+	models.SimulatedIaas: struct{}{},
+}
+
+// the cloud types on which VMs can only be created with the sizes of flavors
+var typesUseFlavors map[string]struct{} = map[string]struct{}{
+	models.OpenstackIaas: struct{}{},
 }
 
 // Not all cloud types support creating new VMs.
-// For example, CLAAUDIA does not allow users to create flavors in Openstack, so if we want to create new VMs in auto-scheduling, this will be more complicated, so we do not support creating new VMs in auto-scheduling.
+// For example, CLAAUDIA does not allow users to create flavors in Openstack, so on Openstack, we can only create VMs with the existing flavors, and if we do not know any flavors, we cannot create new VMs.
 func (c Cloud) SupportCreateNewVM() bool {
-	if _, exist := typesCanCreateNewVM[c.Type]; exist {
-		return true
+	if _, exist := typesCanCreateNewVM[c.Type]; !exist {
+		return false
 	}
-	return false
+	if c.UseFlavors() && len(c.Flavors) == 0 {
+		return false
+	}
+	return true
+}
+
+// UseFlavors tells whether the VMs on this cloud can only be created with the sizes of flavors.
+func (c Cloud) UseFlavors() bool {
+	_, exist := typesUseFlavors[c.Type]
+	return exist
 }
 
 // According to the input resource percentage, this function can generate the information of the shared VM to create.
-func (c Cloud) GetSharedVmToCreate(resPct float64, allRest bool) models.IaasVm {
+// On the clouds using flavors, the VM uses the biggest flavor within the resources, and if no flavor is small enough, it returns false.
+func (c Cloud) GetSharedVmToCreate(resPct float64, allRest bool) (models.IaasVm, bool) {
 	var totalResources GenericResources
 	if !allRest {
 		totalResources = c.GetResVmToCreate(resPct)
 	} else { // when allRest is true, resPct will not be used
 		totalResources = c.GetAllRestRes()
 	}
+	if c.UseFlavors() {
+		flavor, found := c.biggestFlavorWithin(totalResources.CpuCore, totalResources.Memory)
+		if !found {
+			return models.IaasVm{}, false
+		}
+		totalResources.CpuCore, totalResources.Memory = flavor.VCpu, flavor.Ram
+	}
 	return models.IaasVm{
 		Name:    c.GetNameVmToCreate(),
 		Cloud:   c.Name,
 		VCpu:    totalResources.CpuCore,
 		Ram:     totalResources.Memory,
 		Storage: totalResources.Storage,
+	}, true
+}
+
+// FitVmToFlavor sets the vCPU and RAM of a VM to the smallest flavor that can meet them within the rest resources of this cloud. Openstack also chooses the flavor with models.ChooseMinVmFlavor when creating VMs (models.Openstack.ChooseMinFlavor), so the VM created will be the same as the one in the scheduling.
+// On the clouds not using flavors, the VM is not changed. If no flavor can meet the VM, it returns false.
+func (c Cloud) FitVmToFlavor(vm models.IaasVm) (models.IaasVm, bool) {
+	if !c.UseFlavors() {
+		return vm, true
 	}
+	restRes := c.GetAllRestRes()
+	minIdx, found := models.ChooseMinVmFlavor(c.Flavors, vm.VCpu, vm.Ram, restRes.CpuCore, restRes.Memory)
+	if !found {
+		return models.IaasVm{}, false
+	}
+	vm.VCpu, vm.Ram = c.Flavors[minIdx].VCpu, c.Flavors[minIdx].Ram
+	return vm, true
+}
+
+// the biggest flavor whose vCPU and RAM are not more than the input. "Biggest" is measured in a similar way to the overflow in models.ChooseMinVmFlavor, i.e., the sum of the ratios of vCPU and RAM.
+func (c Cloud) biggestFlavorWithin(maxCpu, maxRam float64) (models.VmFlavor, bool) {
+	var maxFlavor models.VmFlavor
+	var found bool = false
+	for _, flavor := range c.Flavors {
+		if flavor.VCpu > maxCpu || flavor.Ram > maxRam || flavor.VCpu <= 0 || flavor.Ram <= 0 {
+			continue
+		}
+		if !found || flavor.VCpu/maxCpu+flavor.Ram/maxRam > maxFlavor.VCpu/maxCpu+maxFlavor.Ram/maxRam {
+			maxFlavor = flavor
+			found = true
+		}
+	}
+	return maxFlavor, found
 }
 
 // auto-schedule vms should have special prefixes
@@ -168,6 +269,18 @@ func GenerateOneCloud(inCloud models.Iaas, cloudNetStates map[string]models.Netw
 		NetState:  cloudNetStates,
 		Resources: resources,
 		K8sNodes:  k8sNodesOnCloud,
+		Pricing:   models.GetCloudPricing(inCloud.ShowName()),
+	}
+
+	// On Openstack, we can only create VMs with the existing flavors.
+	if osCloud, ok := inCloud.(*models.Openstack); ok {
+		vmFlavors, err := osCloud.ListVmFlavors()
+		if err != nil {
+			outErr := fmt.Errorf("List VM flavors of Cloud [%s] Type [%s], error: %w", inCloud.ShowName(), inCloud.ShowType(), err)
+			beego.Error(outErr)
+			return Cloud{}, outErr
+		}
+		outCloud.Flavors = vmFlavors
 	}
 
 	return outCloud, nil
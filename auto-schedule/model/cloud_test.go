@@ -324,8 +324,9 @@ func TestGetSharedVmToCreate(t *testing.T) {
 
 	for i, testCase := range testCases {
 		t.Logf("test: %d, %s", i, testCase.name)
-		actualResult := testCase.cloud.GetSharedVmToCreate(testCase.resPct, testCase.allRest)
+		actualResult, actualFound := testCase.cloud.GetSharedVmToCreate(testCase.resPct, testCase.allRest)
 		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+		assert.True(t, actualFound, fmt.Sprintf("%s: found is not expected", testCase.name))
 	}
 }
 
@@ -351,6 +352,23 @@ func TestSupportCreateNewVM(t *testing.T) {
 			},
 			expectedResult: false,
 		},
+		{
+			name: "case-openstack-with-flavors",
+			cloud: Cloud{
+				Name:    "CLAAUDIAweifan",
+				Type:    models.OpenstackIaas,
+				Flavors: []models.VmFlavor{{Name: "m1.small", VCpu: 2, Ram: 4096}},
+			},
+			expectedResult: true,
+		},
+		{
+			name: "case-simulated",
+			cloud: Cloud{
+				Name: "SIM1",
+				Type: models.SimulatedIaas,
+			},
+			expectedResult: true,
+		},
 		{
 			name: "case-other",
 			cloud: Cloud{
@@ -368,6 +386,186 @@ func TestSupportCreateNewVM(t *testing.T) {
 	}
 }
 
+func openstackCloudForTest() Cloud {
+	return Cloud{
+		Name: "CLAAUDIA",
+		Type: models.OpenstackIaas,
+		Resources: models.ResourceStatus{
+			Limit: models.ResSet{VCpu: 24, Ram: 65536, Storage: 1000, Vm: 10, Port: 10, Volume: 10},
+			InUse: models.ResSet{VCpu: 4, Ram: 8192, Storage: 100, Vm: 1, Port: 1, Volume: 1},
+		},
+		K8sNodes: []K8sNode{},
+		Flavors: []models.VmFlavor{
+			{Name: "m1.small", VCpu: 2, Ram: 4096},
+			{Name: "m1.large", VCpu: 8, Ram: 16384},
+			{Name: "m1.cpu", VCpu: 16, Ram: 8192},
+			{Name: "m1.xlarge", VCpu: 16, Ram: 32768},
+			{Name: "m1.huge", VCpu: 32, Ram: 65536},
+		},
+	}
+}
+
+func TestGetSharedVmToCreateFlavors(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cloud          Cloud
+		resPct         float64
+		allRest        bool
+		expectedResult models.IaasVm
+		expectedFound  bool
+	}{
+		{
+			name:    "case 30%",
+			cloud:   openstackCloudForTest(),
+			resPct:  0.3,
+			allRest: false,
+			expectedResult: models.IaasVm{
+				Name:    "auto-sched-claaudia-0",
+				Cloud:   "CLAAUDIA",
+				VCpu:    2,
+				Ram:     4096,
+				Storage: 300,
+			},
+			expectedFound: true,
+		},
+		{
+			name:    "case all rest",
+			cloud:   openstackCloudForTest(),
+			resPct:  0,
+			allRest: true,
+			expectedResult: models.IaasVm{
+				Name:    "auto-sched-claaudia-0",
+				Cloud:   "CLAAUDIA",
+				VCpu:    16,
+				Ram:     32768,
+				Storage: 900,
+			},
+			expectedFound: true,
+		},
+		{
+			name: "case no flavor small enough",
+			cloud: func() Cloud {
+				cloud := openstackCloudForTest()
+				cloud.Resources.InUse.VCpu = 23
+				return cloud
+			}(),
+			resPct:         0,
+			allRest:        true,
+			expectedResult: models.IaasVm{},
+			expectedFound:  false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult, actualFound := testCase.cloud.GetSharedVmToCreate(testCase.resPct, testCase.allRest)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedFound, actualFound, fmt.Sprintf("%s: found is not expected", testCase.name))
+	}
+}
+
+func TestFitVmToFlavor(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cloud          Cloud
+		vm             models.IaasVm
+		expectedResult models.IaasVm
+		expectedFound  bool
+	}{
+		{
+			name:           "case not using flavors",
+			cloud:          Cloud{Name: "NOKIA4", Type: models.ProxmoxIaas},
+			vm:             models.IaasVm{Name: "vm1", VCpu: 3, Ram: 5000, Storage: 20},
+			expectedResult: models.IaasVm{Name: "vm1", VCpu: 3, Ram: 5000, Storage: 20},
+			expectedFound:  true,
+		},
+		{
+			name:           "case smallest flavor",
+			cloud:          openstackCloudForTest(),
+			vm:             models.IaasVm{Name: "vm1", VCpu: 3, Ram: 5000, Storage: 20},
+			expectedResult: models.IaasVm{Name: "vm1", VCpu: 8, Ram: 16384, Storage: 20},
+			expectedFound:  true,
+		},
+		{
+			name:           "case least overflow",
+			cloud:          openstackCloudForTest(),
+			vm:             models.IaasVm{Name: "vm1", VCpu: 12, Ram: 6000, Storage: 20},
+			expectedResult: models.IaasVm{Name: "vm1", VCpu: 16, Ram: 8192, Storage: 20},
+			expectedFound:  true,
+		},
+		{
+			name:           "case beyond quota",
+			cloud:          openstackCloudForTest(),
+			vm:             models.IaasVm{Name: "vm1", VCpu: 24, Ram: 5000, Storage: 20},
+			expectedResult: models.IaasVm{},
+			expectedFound:  false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult, actualFound := testCase.cloud.FitVmToFlavor(testCase.vm)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedFound, actualFound, fmt.Sprintf("%s: found is not expected", testCase.name))
+	}
+}
+
+func TestRunningAppLocations(t *testing.T) {
+	clouds := map[string]Cloud{
+		"cloud1": {Name: "cloud1"},
+		"cloud2": {Name: "cloud2"},
+	}
+	SetRunningApps(clouds, map[string][]AppLocation{
+		"db": {
+			{CloudName: "cloud2", K8sNodeName: "node2-b"},
+			{CloudName: "cloud1", K8sNodeName: "node1"},
+			{CloudName: "cloud2", K8sNodeName: "node2-a"},
+			{CloudName: "cloud3", K8sNodeName: "node3"}, // unknown cloud
+		},
+		"cache": {
+			{CloudName: "cloud1", K8sNodeName: "node1"},
+		},
+	})
+
+	testCases := []struct {
+		name           string
+		appName        string
+		expectedResult []AppLocation
+	}{
+		{
+			name:    "case several nodes",
+			appName: "db",
+			expectedResult: []AppLocation{
+				{CloudName: "cloud1", K8sNodeName: "node1"},
+				{CloudName: "cloud2", K8sNodeName: "node2-a"},
+				{CloudName: "cloud2", K8sNodeName: "node2-b"},
+			},
+		},
+		{
+			name:    "case one node",
+			appName: "cache",
+			expectedResult: []AppLocation{
+				{CloudName: "cloud1", K8sNodeName: "node1"},
+			},
+		},
+		{
+			name:           "case not running",
+			appName:        "web",
+			expectedResult: nil,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := RunningAppLocations(clouds, testCase.appName)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+
+	// the copies of clouds share the running applications
+	copied := CloudMapCopy(clouds)
+	assert.Equal(t, RunningAppLocations(clouds, "db"), RunningAppLocations(copied, "db"))
+}
+
 func TestGetNameVmToCreate(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -559,3 +757,27 @@ func TestGetNameVmToCreate(t *testing.T) {
 		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
 	}
 }
+
+func TestGenerateOneCloudSimulated(t *testing.T) {
+	simCloud := models.NewSimulated("SIM1", models.ResSet{VCpu: 32, Ram: 65536, Vm: -1, Volume: -1, Storage: 1000, Port: -1})
+	if _, err := simCloud.CreateVM("auto-sched-sim1-0", 8, 16384, 200); err != nil {
+		t.Fatalf("create VM on the simulated cloud error: %s", err.Error())
+	}
+	netState := map[string]models.NetworkState{
+		"SIM1": models.NetworkState{Rtt: 1},
+	}
+
+	// no Kubernetes nodes, so no K8sNodes should be found on the cloud.
+	actualResult, err := GenerateOneCloud(simCloud, netState, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Cloud{
+		Name:     "SIM1",
+		Type:     models.SimulatedIaas,
+		NetState: netState,
+		Resources: models.ResourceStatus{
+			Limit: models.ResSet{VCpu: 32, Ram: 65536, Vm: -1, Volume: -1, Storage: 1000, Port: -1},
+			InUse: models.ResSet{VCpu: 8, Ram: 16384, Vm: 1, Volume: 1, Storage: 200, Port: 1},
+		},
+		K8sNodes: nil,
+	}, actualResult)
+}
@@ -18,7 +18,7 @@ type K8sNode struct {
 	NOTE:
 
 	We do not need to put the information of running applications here, because:
-	1. When deploying new applications, we do not need to consider running ones;
+	1. When deploying new applications, we do not need to consider running ones, except the ones that the new applications depend on. Their places are recorded in Cloud.RunningApps, because they may be on the Kubernetes nodes not used by auto-scheduling, e.g., the nodes with taints;
 	2. When migrating old applications, we can assume that they are new applications, which means we can simulate to remove them from the clouds and then pass them to the scheduling functions.
 	3. We do not support to migrating old applications and deploying new applications in one scheduling.
 	*/
@@ -99,3 +99,34 @@ func GenK8sNodeFromApps(vm models.IaasVm, apps map[string]Application, appGroup
 	thisNode.ResidualResources.Storage = residualStorGiB
 	return thisNode
 }
+
+// For migration, we simulate to remove the pods of the applications to migrate from the clouds, which means that the resources occupied by these pods are added back to the residual resources of their Kubernetes Nodes.
+func ReleasePodsRes(clouds map[string]Cloud, pods []apiv1.Pod) {
+	for _, pod := range pods {
+		if len(pod.Spec.NodeName) == 0 {
+			continue
+		}
+		occupied := GetResOccupiedByPod(pod)
+		for cloudName := range clouds {
+			for i := range clouds[cloudName].K8sNodes {
+				if clouds[cloudName].K8sNodes[i].Name == pod.Spec.NodeName {
+					clouds[cloudName].K8sNodes[i].ResidualResources.CpuCore += occupied.CpuCore
+					clouds[cloudName].K8sNodes[i].ResidualResources.Memory += occupied.Memory
+					clouds[cloudName].K8sNodes[i].ResidualResources.Storage += occupied.Storage
+				}
+			}
+		}
+	}
+}
+
+// Find the cloud where a Kubernetes Node is. If not found, return an empty string.
+func FindCloudOfK8sNode(clouds map[string]Cloud, nodeName string) string {
+	for cloudName, cloud := range clouds {
+		for _, node := range cloud.K8sNodes {
+			if node.Name == nodeName {
+				return cloudName
+			}
+		}
+	}
+	return ""
+}
@@ -423,3 +423,41 @@ func TestK8sNodeCopy(t *testing.T) {
 		assert.Equal(t, testCase.src, dst)
 	}
 }
+
+func TestReleasePodsRes(t *testing.T) {
+	clouds := map[string]Cloud{
+		"cloud1": Cloud{
+			Name: "cloud1",
+			K8sNodes: []K8sNode{
+				{Name: "node1", ResidualResources: GenericResources{CpuCore: 1, Memory: 1024, Storage: 10}},
+				{Name: "node2", ResidualResources: GenericResources{CpuCore: 0, Memory: 0, Storage: 0}},
+			},
+		},
+		"cloud2": Cloud{
+			Name: "cloud2",
+			K8sNodes: []K8sNode{
+				{Name: "node3", ResidualResources: GenericResources{CpuCore: 2, Memory: 2048, Storage: 20}},
+			},
+		},
+	}
+	resources := apiv1.ResourceRequirements{
+		Requests: map[apiv1.ResourceName]resource.Quantity{
+			apiv1.ResourceCPU:              resource.MustParse("2"),
+			apiv1.ResourceMemory:           resource.MustParse("512Mi"),
+			apiv1.ResourceEphemeralStorage: resource.MustParse("5Gi"),
+		},
+	}
+	pods := []apiv1.Pod{
+		{Spec: apiv1.PodSpec{NodeName: "node1", Containers: []apiv1.Container{{Resources: resources}}}},
+		{Spec: apiv1.PodSpec{NodeName: "node3", Containers: []apiv1.Container{{Resources: resources}, {Resources: resources}}}},
+		{Spec: apiv1.PodSpec{NodeName: "", Containers: []apiv1.Container{{Resources: resources}}}}, // pending pod
+	}
+
+	ReleasePodsRes(clouds, pods)
+	assert.Equal(t, GenericResources{CpuCore: 3, Memory: 1536, Storage: 15}, clouds["cloud1"].K8sNodes[0].ResidualResources)
+	assert.Equal(t, GenericResources{CpuCore: 0, Memory: 0, Storage: 0}, clouds["cloud1"].K8sNodes[1].ResidualResources)
+	assert.Equal(t, GenericResources{CpuCore: 6, Memory: 3072, Storage: 30}, clouds["cloud2"].K8sNodes[0].ResidualResources)
+
+	assert.Equal(t, "cloud2", FindCloudOfK8sNode(clouds, "node3"))
+	assert.Equal(t, "", FindCloudOfK8sNode(clouds, "node4"))
+}
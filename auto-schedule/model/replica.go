@@ -0,0 +1,108 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"emcontroller/models"
+)
+
+// ReplicaNameSep separates the application name and the replica index in the name of an expanded replica. Kubernetes names cannot include it, so the names of replicas never conflict with the names of applications.
+const ReplicaNameSep string = "#"
+
+// ReplicaName is the name of the idx-th replica of an application in the scheduling.
+func ReplicaName(appName string, idx int) string {
+	return appName + ReplicaNameSep + strconv.Itoa(idx)
+}
+
+// SplitReplicaName is the reverse of ReplicaName. If the name is not a replica name, it returns the name itself and isReplica false.
+func SplitReplicaName(name string) (appName string, idx int, isReplica bool) {
+	sepIdx := strings.LastIndex(name, ReplicaNameSep)
+	if sepIdx < 0 {
+		return name, 0, false
+	}
+	idx, err := strconv.Atoi(name[sepIdx+len(ReplicaNameSep):])
+	if err != nil {
+		return name, 0, false
+	}
+	return name[:sepIdx], idx, true
+}
+
+// IsMultiReplica tells whether an application has more than 1 replica, which needs to be expanded before scheduling. An expanded replica is not multi-replica.
+func (app Application) IsMultiReplica() bool {
+	return app.Replicas > 1 && len(app.ReplicaOf) == 0
+}
+
+// SchedulingNames are the names of the scheduling entries of an application, one for each replica, in the order of the replica index.
+func (app Application) SchedulingNames() []string {
+	if !app.IsMultiReplica() {
+		return []string{app.Name}
+	}
+	names := make([]string, app.Replicas)
+	for i := 0; i < app.Replicas; i++ {
+		names[i] = ReplicaName(app.Name, i)
+	}
+	return names
+}
+
+// ExpandReplicas makes every replica of multi-replica applications a separate application for scheduling, so that every replica gets its own placement.
+// The algorithms keep the replicas of the same application either all accepted or all rejected, on different Kubernetes nodes, and across at least MinClouds clouds.
+// A replica depends on all replicas of the applications that its original application depends on, because a request can be sent to any of them.
+// Single-replica applications are not renamed, so if no application has multiple replicas, the output is the same as the input.
+func ExpandReplicas(apps map[string]Application) map[string]Application {
+	expanded := make(map[string]Application)
+
+	expandDeps := func(deps []models.Dependency) []models.Dependency {
+		if deps == nil {
+			return nil
+		}
+		var outDeps []models.Dependency = make([]models.Dependency, 0, len(deps))
+		for _, dep := range deps {
+			depApp, exist := apps[dep.AppName]
+			if !exist || !depApp.IsMultiReplica() {
+				outDeps = append(outDeps, dep)
+				continue
+			}
+			for _, depReplicaName := range depApp.SchedulingNames() {
+				depReplica := dep
+				depReplica.AppName = depReplicaName
+				outDeps = append(outDeps, depReplica)
+			}
+		}
+		return outDeps
+	}
+
+	for name, app := range apps {
+		if !app.IsMultiReplica() {
+			outApp := AppCopy(app)
+			outApp.Dependencies = expandDeps(app.Dependencies)
+			expanded[name] = outApp
+			continue
+		}
+		for _, replicaName := range app.SchedulingNames() {
+			replica := AppCopy(app)
+			replica.Name = replicaName
+			replica.ReplicaOf = app.Name
+			replica.Dependencies = expandDeps(app.Dependencies)
+			expanded[replicaName] = replica
+		}
+	}
+
+	return expanded
+}
+
+// ReplicaGroups returns the names of the scheduling entries of every original multi-replica application in the expanded applications. The names in every group are sorted.
+func ReplicaGroups(expandedApps map[string]Application) map[string][]string {
+	groups := make(map[string][]string)
+	for name, app := range expandedApps {
+		if len(app.ReplicaOf) == 0 {
+			continue
+		}
+		groups[app.ReplicaOf] = append(groups[app.ReplicaOf], name)
+	}
+	for _, names := range groups {
+		sort.Strings(names)
+	}
+	return groups
+}
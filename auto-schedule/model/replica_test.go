@@ -0,0 +1,103 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"emcontroller/models"
+)
+
+func TestSplitReplicaName(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		expectedAppName   string
+		expectedIdx       int
+		expectedIsReplica bool
+	}{
+		{
+			name:              "case replica",
+			input:             ReplicaName("frontend", 2),
+			expectedAppName:   "frontend",
+			expectedIdx:       2,
+			expectedIsReplica: true,
+		},
+		{
+			name:              "case application",
+			input:             "frontend",
+			expectedAppName:   "frontend",
+			expectedIdx:       0,
+			expectedIsReplica: false,
+		},
+		{
+			name:              "case index is not a number",
+			input:             "frontend#a",
+			expectedAppName:   "frontend#a",
+			expectedIdx:       0,
+			expectedIsReplica: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		appName, idx, isReplica := SplitReplicaName(testCase.input)
+		assert.Equal(t, testCase.expectedAppName, appName, fmt.Sprintf("%s: appName is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedIdx, idx, fmt.Sprintf("%s: idx is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedIsReplica, isReplica, fmt.Sprintf("%s: isReplica is not expected", testCase.name))
+	}
+}
+
+func TestExpandReplicas(t *testing.T) {
+	testCases := []struct {
+		name           string
+		apps           map[string]Application
+		expectedResult map[string]Application
+	}{
+		{
+			name: "case no multi-replica application",
+			apps: map[string]Application{
+				"app1": {Name: "app1", Priority: 5, Replicas: 1, Dependencies: []models.Dependency{{AppName: "app2"}}},
+				"app2": {Name: "app2", Priority: 5},
+			},
+			expectedResult: map[string]Application{
+				"app1": {Name: "app1", Priority: 5, Replicas: 1, Dependencies: []models.Dependency{{AppName: "app2"}}},
+				"app2": {Name: "app2", Priority: 5},
+			},
+		},
+		{
+			name: "case multi-replica applications depend on each other",
+			apps: map[string]Application{
+				"frontend": {Name: "frontend", Priority: 5, Replicas: 2, MinClouds: 2, Dependencies: []models.Dependency{{AppName: "backend", MaxRttMs: 20}}},
+				"backend":  {Name: "backend", Priority: 8, Replicas: 2},
+				"client":   {Name: "client", Priority: 1, Dependencies: []models.Dependency{{AppName: "frontend"}}},
+			},
+			expectedResult: map[string]Application{
+				"frontend#0": {Name: "frontend#0", Priority: 5, Replicas: 2, MinClouds: 2, ReplicaOf: "frontend", Dependencies: []models.Dependency{{AppName: "backend#0", MaxRttMs: 20}, {AppName: "backend#1", MaxRttMs: 20}}},
+				"frontend#1": {Name: "frontend#1", Priority: 5, Replicas: 2, MinClouds: 2, ReplicaOf: "frontend", Dependencies: []models.Dependency{{AppName: "backend#0", MaxRttMs: 20}, {AppName: "backend#1", MaxRttMs: 20}}},
+				"backend#0":  {Name: "backend#0", Priority: 8, Replicas: 2, ReplicaOf: "backend"},
+				"backend#1":  {Name: "backend#1", Priority: 8, Replicas: 2, ReplicaOf: "backend"},
+				"client":     {Name: "client", Priority: 1, Dependencies: []models.Dependency{{AppName: "frontend#0"}, {AppName: "frontend#1"}}},
+			},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult := ExpandReplicas(testCase.apps)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+		assert.Equal(t, len(actualResult), len(ExpandReplicas(actualResult)), fmt.Sprintf("%s: expanding twice should not change anything", testCase.name))
+	}
+}
+
+func TestReplicaGroups(t *testing.T) {
+	apps := ExpandReplicas(map[string]Application{
+		"frontend": {Name: "frontend", Replicas: 3},
+		"backend":  {Name: "backend", Replicas: 1},
+	})
+	expectedResult := map[string][]string{
+		"frontend": {"frontend#0", "frontend#1", "frontend#2"},
+	}
+	assert.Equal(t, expectedResult, ReplicaGroups(apps), "result is not expected")
+}
@@ -0,0 +1,8 @@
+package model
+
+// Snapshot is the input of the scheduling algorithms for a request, i.e., the clouds and the applications with the replicas expanded, and the order of the applications. It can be exported from a running Multi-cloud Manager by "/appGroup/snapshot" and scheduled again offline, e.g., by "auto-schedule/asched".
+type Snapshot struct {
+	Clouds    map[string]Cloud       `json:"clouds"`
+	Apps      map[string]Application `json:"apps"`
+	AppsOrder []string               `json:"appsOrder"`
+}
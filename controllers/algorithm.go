@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"github.com/astaxie/beego"
+
+	"emcontroller/auto-schedule/algorithms"
+)
+
+type AlgorithmController struct {
+	beego.Controller
+}
+
+// List the available scheduling algorithms and their tunable parameters.
+// test command:
+// curl -i -X GET http://localhost:20000/algorithms
+func (c *AlgorithmController) Get() {
+	c.Data["json"] = algorithms.ListAlgorithms()
+	c.ServeJSON()
+}
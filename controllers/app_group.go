@@ -1,16 +1,20 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/astaxie/beego"
 
 	"emcontroller/auto-schedule/algorithms"
 	"emcontroller/auto-schedule/executors"
+	asmodel "emcontroller/auto-schedule/model"
 	"emcontroller/models"
 )
 
@@ -20,23 +24,46 @@ const (
 	ExTimeOneCpuKey string = "Expected-Time-One-Cpu" // expected application computation time with one CPU core
 )
 
+// User can use these HTTP headers to override the default parameters of genetic algorithms in app.conf. The effective parameters are also put in these headers of the response.
+const (
+	GaChromosomesCountKey      string = "Mcm-Ga-Chromosomes-Count"
+	GaIterationCountKey        string = "Mcm-Ga-Iteration-Count"
+	GaCrossoverProbabilityKey  string = "Mcm-Ga-Crossover-Probability"
+	GaMutationProbabilityKey   string = "Mcm-Ga-Mutation-Probability"
+	GaStopNoUpdateIterationKey string = "Mcm-Ga-Stop-No-Update-Iteration"
+)
+
+// With a multi-objective algorithm (e.g., Nsga2), user can use this HTTP header to choose how the solution to deploy is picked from the Pareto front, in the format "<policy>[:<objective>=<weight>,...]", e.g., "knee" or "weights:acceptance=1,communication=0.5".
+const ParetoSelectionKey string = "Mcm-Pareto-Selection"
+
+// User can use this HTTP header to set the budget per hour of the VMs created for a request. The prices are set by "pricing" of the clouds in iaas.json. Without the header, there is no limit.
+const MaxHourlyCostKey string = "Mcm-Max-Hourly-Cost"
+
+// User can use this HTTP header to set the seed of the random generator of the scheduling algorithm, to reproduce a former scheduling on the same clouds. Without the header, a seed is generated. The seed used is put in this header of the response.
+const SeedKey string = "Mcm-Seed"
+
+// User can use these HTTP headers to enable preemption. The running auto-scheduled applications with priorities lower than the value of PreemptBelowPriorityKey can be evicted for the new applications. The names of the evicted applications are put in the header EvictedAppsKey of the response.
+const (
+	PreemptBelowPriorityKey string = "Mcm-Preempt-Below-Priority"
+	PreemptRescheduleKey    string = "Mcm-Preempt-Reschedule" // "true" means that the evicted applications are moved to other places if possible, instead of only deleted
+	EvictedAppsKey          string = "Mcm-Evicted-Apps"
+)
+
+// User can use this HTTP header to set the time limit of scheduling in seconds, counted from when the request leaves the scheduling queue. When it is reached, the genetic algorithms and Exact stop and use the best solution found so far. Without the header, there is no limit.
+// For a synchronous request, the scheduling is also cancelled if the client disconnects.
+const SchedTimeLimitKey string = "Mcm-Scheduling-Time-Limit"
+
+// Scheduling and migration requests wait in the scheduling queue and run one at a time. The ID and the position of a request in the queue are put in these headers of the response.
+const (
+	QueueIdKey       string = "Mcm-Queue-Id"
+	QueuePositionKey string = "Mcm-Queue-Position"
+)
+
 type AppGroupController struct {
 	beego.Controller
 }
 
 func (c *AppGroupController) DoNewAppGroup() {
-	// scheduling, migration, and cleanup cannot be done at the same time
-	if !algorithms.ScheMu.TryLock() {
-		outErr := fmt.Errorf("Another task of Scheduling, Migration or Cleanup is running. Please try later.")
-		beego.Error(outErr)
-		c.Ctx.ResponseWriter.WriteHeader(http.StatusLocked)
-		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
-			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
-		}
-		return
-	}
-	defer algorithms.ScheMu.Unlock()
-
 	contentType := c.Ctx.Request.Header.Get("Content-Type")
 	beego.Info(fmt.Sprintf("The header \"Content-Type\" is [%s]", contentType))
 
@@ -51,6 +78,7 @@ func (c *AppGroupController) DoNewAppGroup() {
 }
 
 // Used for json request, input is json
+// it runs as an asynchronous task by default. Add the header "Mcm-Async: false" to wait for the result in the response.
 // test command:
 // curl -i -X POST -H Content-Type:application/json -H Mcm-Scheduling-Algorithm:Mcssga -H Expected-Time-One-Cpu:35 -d '[ { "priority": 2, "autoScheduled": true, "name": "group-printtime", "replicas": 1, "hostNetwork": false, "containers": [ { "name": "printtime", "image": "172.27.15.31:5000/printtime:v1", "workDir": "/printtime", "resources": { "limits": { "memory": "30Mi", "cpu": "2", "storage": "2Gi" }, "requests": { "memory": "30Mi", "cpu": "2", "storage": "2Gi" } }, "commands": [ "bash" ], "args": [ "-c", "python3 -u main.py > $LOGFILE" ], "env": [ { "name": "PARAMETER1", "value": "testRenderenv1" }, { "name": "LOGFILE", "value": "/tmp/234/printtime.log" } ], "mounts": [ { "vmPath": "/tmp/asdff", "containerPath": "/tmp/234" }, { "vmPath": "/tmp/uyyyy", "containerPath": "/tmp/2345" } ] } ], "dependencies": [ { "appName": "group-nginx" }, { "appName": "group-ubuntu" } ] }, { "priority": 4, "autoScheduled": true, "name": "group-nginx", "replicas": 1, "hostNetwork": true, "containers": [ { "name": "nginx", "image": "172.27.15.31:5000/nginx:1.17.1", "workDir": "", "resources": { "limits": { "memory": "1024Mi", "cpu": "2", "storage": "20Gi" }, "requests": { "memory": "1024Mi", "cpu": "2", "storage": "20Gi" } }, "ports": [ { "containerPort": 80, "name": "fsd", "protocol": "tcp", "servicePort": "80", "nodePort": "30001" } ] } ], "dependencies": [ { "appName": "group-ubuntu" } ] }, { "priority": 4, "autoScheduled": true, "name": "group-ubuntu", "replicas": 1, "hostNetwork": true, "containers": [ { "name": "ubuntu", "image": "172.27.15.31:5000/ubuntu:latest", "workDir": "", "resources": { "limits": { "memory": "512Mi", "cpu": "1", "storage": "20Gi" }, "requests": { "memory": "512Mi", "cpu": "1", "storage": "20Gi" } }, "commands": [ "bash", "-c", "while true;do sleep 10;done" ], "args": null, "env": [ { "name": "asfasf", "value": "asfasf" }, { "name": "asdfsdf", "value": "sfsdf" } ], "mounts": [ { "vmPath": "/tmp/asdff", "containerPath": "/tmp/log" } ], "ports": null } ], "dependencies": [] } ]' http://localhost:20000/doNewAppGroup
 func (c *AppGroupController) DoNewAppGroupJson() {
@@ -67,6 +95,275 @@ func (c *AppGroupController) DoNewAppGroupJson() {
 
 	beego.Info(fmt.Sprintf("From json input, we successfully parsed applications [%+v]", apps))
 
+	schedAlgorithm, exTimeOneCpu := c.getScheHeaders()
+	gaParams, ok := c.getGaParams()
+	if !ok {
+		return
+	}
+	pareto, ok := c.getParetoSelection()
+	if !ok {
+		return
+	}
+	maxHourlyCost, ok := c.getMaxHourlyCost()
+	if !ok {
+		return
+	}
+	seed, ok := c.getSeed()
+	if !ok {
+		return
+	}
+	timeLimit, ok := c.getSchedTimeLimit()
+	if !ok {
+		return
+	}
+	preempt, ok := c.getPreemptOptions()
+	if !ok {
+		return
+	}
+
+	// scheduling, migration, and cleanup cannot be done at the same time, so the request waits in the queue for its turn.
+	ticket := executors.ScheQueue.Submit(models.TaskTypeCreateAppGroup, maxAppPriority(apps))
+	setQueueHeaders(&c.Controller, ticket)
+
+	if isAsyncReq(&c.Controller) {
+		serveNewQueuedTask(&c.Controller, models.TaskTypeCreateAppGroup, func(reporter models.TaskReporter) (interface{}, error) {
+			reporter.Logf("waiting in the scheduling queue as [%s], position %d", ticket.ID(), executors.ScheQueue.Position(ticket))
+			var result AppGroupTaskResult
+			var err error
+			if runErr := executors.ScheQueue.Run(ticket, func() {
+				reporter.SetRunning()
+				// the client does not wait for an asynchronous task, so only the time limit stops the scheduling.
+				ctx, cancel := schedulingContext(context.Background(), timeLimit)
+				defer cancel()
+				result.Apps, result.EvictedApps, err, _ = executors.CreateAutoScheduleApps(ctx, apps, schedAlgorithm, exTimeOneCpu, gaParams, pareto, maxHourlyCost, seed, preempt, reporter)
+			}); runErr != nil {
+				return nil, runErr
+			}
+			return result, err
+		})
+		return
+	}
+
+	var outApps []models.AppInfo
+	var evictedApps []executors.EvictedApp
+	var err error
+	var statusCode int
+	// if the client disconnects while waiting, nobody waits for the response, so the request leaves the queue.
+	executors.ScheQueue.CancelOnDone(c.Ctx.Request.Context(), ticket)
+	if runErr := executors.ScheQueue.Run(ticket, func() {
+		ctx, cancel := schedulingContext(c.Ctx.Request.Context(), timeLimit)
+		defer cancel()
+		outApps, evictedApps, err, statusCode = executors.CreateAutoScheduleApps(ctx, apps, schedAlgorithm, exTimeOneCpu, gaParams, pareto, maxHourlyCost, seed, preempt, models.NopReporter{})
+	}); runErr != nil {
+		writeCancelledResponse(&c.Controller, runErr)
+		return
+	}
+	setEvictedAppsHeader(&c.Controller, evictedApps)
+	if err != nil {
+		outErr := fmt.Errorf("executors.CreateAutoScheduleApps(apps), error: %w", err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(statusCode)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+
+	c.Ctx.Output.Status = http.StatusCreated
+	c.Data["json"] = outApps
+	c.ServeJSON()
+}
+
+func (c *AppGroupController) DoNewAppGroupForm() {
+	outErr := fmt.Errorf("Please set the \"Content-Type\" as \"%s\", because the functions to handle other content types have not been implemented.", JsonContentType)
+	beego.Error(outErr)
+	c.Ctx.ResponseWriter.WriteHeader(http.StatusMethodNotAllowed)
+	if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+		beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+	}
+	return
+}
+
+// Re-schedule all running auto-scheduled applications and migrate them to the new places.
+// test command:
+// it runs as an asynchronous task by default. Add the header "Mcm-Async: false" to wait for the result in the response.
+// curl -i -X POST -H Mcm-Scheduling-Algorithm:Mcssga -H Expected-Time-One-Cpu:35 http://localhost:20000/appGroup/migrate
+func (c *AppGroupController) MigrateAppGroup() {
+	schedAlgorithm, exTimeOneCpu := c.getScheHeaders()
+	gaParams, ok := c.getGaParams()
+	if !ok {
+		return
+	}
+	pareto, ok := c.getParetoSelection()
+	if !ok {
+		return
+	}
+	seed, ok := c.getSeed()
+	if !ok {
+		return
+	}
+	timeLimit, ok := c.getSchedTimeLimit()
+	if !ok {
+		return
+	}
+
+	// the migration involves applications with all priorities, so it waits in the queue with the lowest priority, not to delay the new applications.
+	ticket := executors.ScheQueue.Submit(models.TaskTypeMigrateAppGroup, asmodel.MinPriority)
+	setQueueHeaders(&c.Controller, ticket)
+
+	if isAsyncReq(&c.Controller) {
+		serveNewQueuedTask(&c.Controller, models.TaskTypeMigrateAppGroup, func(reporter models.TaskReporter) (interface{}, error) {
+			reporter.Logf("waiting in the scheduling queue as [%s], position %d", ticket.ID(), executors.ScheQueue.Position(ticket))
+			var results []executors.MigrationResult
+			var err error
+			if runErr := executors.ScheQueue.Run(ticket, func() {
+				reporter.SetRunning()
+				ctx, cancel := schedulingContext(context.Background(), timeLimit)
+				defer cancel()
+				results, err, _ = executors.MigrateAutoScheduleApps(ctx, schedAlgorithm, exTimeOneCpu, gaParams, pareto, seed, reporter)
+			}); runErr != nil {
+				return nil, runErr
+			}
+			return results, err
+		})
+		return
+	}
+
+	var results []executors.MigrationResult
+	var err error
+	var statusCode int
+	// if the client disconnects while waiting, nobody waits for the response, so the request leaves the queue.
+	executors.ScheQueue.CancelOnDone(c.Ctx.Request.Context(), ticket)
+	if runErr := executors.ScheQueue.Run(ticket, func() {
+		ctx, cancel := schedulingContext(c.Ctx.Request.Context(), timeLimit)
+		defer cancel()
+		results, err, statusCode = executors.MigrateAutoScheduleApps(ctx, schedAlgorithm, exTimeOneCpu, gaParams, pareto, seed, models.NopReporter{})
+	}); runErr != nil {
+		writeCancelledResponse(&c.Controller, runErr)
+		return
+	}
+	if err != nil {
+		outErr := fmt.Errorf("executors.MigrateAutoScheduleApps, results: %s, error: %w", models.JsonString(results), err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(statusCode)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+
+	c.Ctx.Output.Status = http.StatusOK
+	c.Data["json"] = results
+	c.ServeJSON()
+}
+
+// Dry run of scheduling. Return the plan worked out by the algorithm without creating any VMs or applications, so that users can check the plan before anything is provisioned.
+// test command:
+// curl -i -X POST -H Content-Type:application/json -H Mcm-Scheduling-Algorithm:Mcssga -H Expected-Time-One-Cpu:35 -d '<the same body as /doNewAppGroup>' http://localhost:20000/appGroup/plan
+func (c *AppGroupController) PlanAppGroup() {
+	contentType := c.Ctx.Request.Header.Get("Content-Type")
+	beego.Info(fmt.Sprintf("The header \"Content-Type\" is [%s]", contentType))
+	if !strings.Contains(strings.ToLower(contentType), JsonContentType) {
+		c.DoNewAppGroupForm()
+		return
+	}
+
+	var apps []models.K8sApp
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &apps); err != nil {
+		outErr := fmt.Errorf("json.Unmarshal the applications in RequestBody, error: %w", err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+	beego.Info(fmt.Sprintf("From json input, we successfully parsed applications [%+v]", apps))
+
+	schedAlgorithm, exTimeOneCpu := c.getScheHeaders()
+	gaParams, ok := c.getGaParams()
+	if !ok {
+		return
+	}
+	pareto, ok := c.getParetoSelection()
+	if !ok {
+		return
+	}
+	maxHourlyCost, ok := c.getMaxHourlyCost()
+	if !ok {
+		return
+	}
+	seed, ok := c.getSeed()
+	if !ok {
+		return
+	}
+	timeLimit, ok := c.getSchedTimeLimit()
+	if !ok {
+		return
+	}
+	preempt, ok := c.getPreemptOptions()
+	if !ok {
+		return
+	}
+
+	ctx, cancel := schedulingContext(c.Ctx.Request.Context(), timeLimit)
+	defer cancel()
+	plan, err, statusCode := executors.PlanAutoScheduleApps(ctx, apps, schedAlgorithm, exTimeOneCpu, gaParams, pareto, maxHourlyCost, seed, preempt)
+	if err != nil {
+		outErr := fmt.Errorf("executors.PlanAutoScheduleApps(apps), error: %w", err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(statusCode)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+
+	c.Ctx.Output.Status = http.StatusOK
+	c.Data["json"] = plan
+	c.ServeJSON()
+}
+
+// Export the input that the scheduling algorithms would get for the applications on the current clouds, without scheduling them, so that a scheduling can be replayed offline by "auto-schedule/asched".
+// test command:
+// curl -X POST -H Content-Type:application/json -d '<the same body as /doNewAppGroup>' http://localhost:20000/appGroup/snapshot > snapshot.json
+func (c *AppGroupController) SnapshotAppGroup() {
+	var apps []models.K8sApp
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &apps); err != nil {
+		outErr := fmt.Errorf("json.Unmarshal the applications in RequestBody, error: %w", err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+	beego.Info(fmt.Sprintf("From json input, we successfully parsed applications [%+v]", apps))
+
+	// with preemption, the clouds in the snapshot do not have the resources used by the evictable applications.
+	preempt, ok := c.getPreemptOptions()
+	if !ok {
+		return
+	}
+
+	snapshot, err, statusCode := executors.SnapshotAutoScheduleApps(apps, preempt)
+	if err != nil {
+		outErr := fmt.Errorf("executors.SnapshotAutoScheduleApps(apps), error: %w", err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(statusCode)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+
+	c.Ctx.Output.Status = http.StatusOK
+	c.Data["json"] = snapshot
+	c.ServeJSON()
+}
+
+// read the scheduling algorithm and the expected application computation time with one CPU core from the HTTP headers.
+func (c *AppGroupController) getScheHeaders() (string, float64) {
 	schedAlgorithm := c.Ctx.Request.Header.Get(SAHeaderKey)
 	beego.Info(fmt.Sprintf("The header %s is [%s]", SAHeaderKey, schedAlgorithm))
 
@@ -80,28 +377,277 @@ func (c *AppGroupController) DoNewAppGroupJson() {
 		beego.Info(fmt.Sprintf("Parse header %s to float [%g]", ExTimeOneCpuKey, exTimeOneCpu))
 	}
 
-	outApps, err, statusCode := executors.CreateAutoScheduleApps(apps, schedAlgorithm, exTimeOneCpu)
+	return schedAlgorithm, exTimeOneCpu
+}
+
+// read the parameters of genetic algorithms from the HTTP headers. The parameters not in the headers are the default ones in app.conf.
+// If the parameters are invalid, this function responds 400 and returns false. Otherwise, it puts the effective parameters in the response headers.
+func (c *AppGroupController) getGaParams() (algorithms.GaParams, bool) {
+	gaParams := algorithms.DefaultGaParams
+	var errs []error
+
+	for key, target := range map[string]*int{
+		GaChromosomesCountKey:      &gaParams.ChromosomesCount,
+		GaIterationCountKey:        &gaParams.IterationCount,
+		GaStopNoUpdateIterationKey: &gaParams.StopNoUpdateIteration,
+	} {
+		valueStr := c.Ctx.Request.Header.Get(key)
+		if len(valueStr) == 0 {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse HTTP header key [%s] value [%s] to int, error: %w", key, valueStr, err))
+			continue
+		}
+		*target = value
+	}
+	for key, target := range map[string]*float64{
+		GaCrossoverProbabilityKey: &gaParams.CrossoverProbability,
+		GaMutationProbabilityKey:  &gaParams.MutationProbability,
+	} {
+		valueStr := c.Ctx.Request.Header.Get(key)
+		if len(valueStr) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse HTTP header key [%s] value [%s] to float64, error: %w", key, valueStr, err))
+			continue
+		}
+		*target = value
+	}
+	errs = append(errs, algorithms.ValidateGaParams(gaParams)...)
+
+	if len(errs) != 0 {
+		outErr := fmt.Errorf("The GA parameters are invalid, error: %w", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return algorithms.GaParams{}, false
+	}
+
+	beego.Info(fmt.Sprintf("The GA parameters are %s", models.JsonString(gaParams)))
+	c.Ctx.Output.Header(GaChromosomesCountKey, strconv.Itoa(gaParams.ChromosomesCount))
+	c.Ctx.Output.Header(GaIterationCountKey, strconv.Itoa(gaParams.IterationCount))
+	c.Ctx.Output.Header(GaCrossoverProbabilityKey, strconv.FormatFloat(gaParams.CrossoverProbability, 'g', -1, 64))
+	c.Ctx.Output.Header(GaMutationProbabilityKey, strconv.FormatFloat(gaParams.MutationProbability, 'g', -1, 64))
+	c.Ctx.Output.Header(GaStopNoUpdateIterationKey, strconv.Itoa(gaParams.StopNoUpdateIteration))
+	return gaParams, true
+}
+
+// read the policy to pick the solution from the Pareto front from the HTTP header. Without the header, the policy is algorithms.DefaultParetoSelection.
+// If the header cannot be parsed, this function responds 400 and returns false. Whether the weighted objectives exist is checked with the algorithm in scheduling.
+func (c *AppGroupController) getParetoSelection() (algorithms.ParetoSelection, bool) {
+	valueStr := c.Ctx.Request.Header.Get(ParetoSelectionKey)
+	pareto, err := algorithms.ParseParetoSelection(valueStr)
 	if err != nil {
-		outErr := fmt.Errorf("executors.CreateAutoScheduleApps(apps), error: %w", err)
+		outErr := fmt.Errorf("parse HTTP header key [%s] value [%s], error: %w", ParetoSelectionKey, valueStr, err)
 		beego.Error(outErr)
-		c.Ctx.ResponseWriter.WriteHeader(statusCode)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return algorithms.ParetoSelection{}, false
+	}
+	beego.Info(fmt.Sprintf("The Pareto selection is %s", models.JsonString(pareto)))
+	return pareto, true
+}
+
+// read the budget per hour of the new VMs from the HTTP header. Without the header, the budget is 0, which means no limit.
+// If the budget is invalid, this function responds 400 and returns false.
+func (c *AppGroupController) getMaxHourlyCost() (float64, bool) {
+	valueStr := c.Ctx.Request.Header.Get(MaxHourlyCostKey)
+	if len(valueStr) == 0 {
+		return 0, true
+	}
+	maxHourlyCost, err := strconv.ParseFloat(valueStr, 64)
+	if err == nil && maxHourlyCost < 0 {
+		err = fmt.Errorf("it should not be negative")
+	}
+	if err != nil {
+		outErr := fmt.Errorf("parse HTTP header key [%s] value [%s] to float64, error: %w", MaxHourlyCostKey, valueStr, err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return 0, false
+	}
+	beego.Info(fmt.Sprintf("Parse header %s to float [%g]", MaxHourlyCostKey, maxHourlyCost))
+	return maxHourlyCost, true
+}
+
+// read the seed of the random generator from the HTTP header. Without the header, we generate a seed. The seed used is put in the response header.
+// If the seed is not an int64, this function responds 400 and returns false.
+func (c *AppGroupController) getSeed() (int64, bool) {
+	valueStr := c.Ctx.Request.Header.Get(SeedKey)
+	seed := algorithms.NewSeed()
+	if len(valueStr) != 0 {
+		var err error
+		seed, err = strconv.ParseInt(valueStr, 10, 64)
+		if err != nil {
+			outErr := fmt.Errorf("parse HTTP header key [%s] value [%s] to int64, error: %w", SeedKey, valueStr, err)
+			beego.Error(outErr)
+			c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+			if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+				beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+			}
+			return 0, false
+		}
+	}
+	beego.Info(fmt.Sprintf("The seed is [%d]", seed))
+	c.Ctx.Output.Header(SeedKey, strconv.FormatInt(seed, 10))
+	return seed, true
+}
+
+// read the time limit of scheduling from the HTTP header. Without the header, the time limit is 0, which means no limit.
+// If the time limit is not a positive number of seconds, this function responds 400 and returns false.
+func (c *AppGroupController) getSchedTimeLimit() (time.Duration, bool) {
+	valueStr := c.Ctx.Request.Header.Get(SchedTimeLimitKey)
+	if len(valueStr) == 0 {
+		return 0, true
+	}
+	seconds, err := strconv.ParseFloat(valueStr, 64)
+	if err == nil && seconds <= 0 {
+		err = fmt.Errorf("it should be positive")
+	}
+	if err != nil {
+		outErr := fmt.Errorf("parse HTTP header key [%s] value [%s] to seconds, error: %w", SchedTimeLimitKey, valueStr, err)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
 		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
 			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
 		}
+		return 0, false
+	}
+	timeLimit := time.Duration(seconds * float64(time.Second))
+	beego.Info(fmt.Sprintf("The scheduling time limit is [%s]", timeLimit))
+	return timeLimit, true
+}
+
+// make the context passed to the scheduling algorithm. It is done when parent is done, or when timeLimit (0 means no limit) is reached.
+func schedulingContext(parent context.Context, timeLimit time.Duration) (context.Context, context.CancelFunc) {
+	if timeLimit <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeLimit)
+}
+
+// read the preemption options from the HTTP headers. Without the headers, preemption is disabled.
+// If the options are invalid, this function responds 400 and returns false.
+func (c *AppGroupController) getPreemptOptions() (executors.PreemptOptions, bool) {
+	var preempt executors.PreemptOptions
+	var errs []error
+
+	if valueStr := c.Ctx.Request.Header.Get(PreemptBelowPriorityKey); len(valueStr) != 0 {
+		value, err := strconv.Atoi(valueStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse HTTP header key [%s] value [%s] to int, error: %w", PreemptBelowPriorityKey, valueStr, err))
+		}
+		preempt.BelowPriority = value
+	}
+	if valueStr := c.Ctx.Request.Header.Get(PreemptRescheduleKey); len(valueStr) != 0 {
+		value, err := strconv.ParseBool(valueStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse HTTP header key [%s] value [%s] to bool, error: %w", PreemptRescheduleKey, valueStr, err))
+		}
+		preempt.Reschedule = value
+	}
+	if len(errs) == 0 {
+		errs = executors.ValidatePreemptOptions(preempt)
+	}
+
+	if len(errs) != 0 {
+		outErr := fmt.Errorf("The preemption options are invalid, error: %w", models.HandleErrSlice(errs))
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return executors.PreemptOptions{}, false
+	}
+
+	beego.Info(fmt.Sprintf("The preemption options are %s", models.JsonString(preempt)))
+	return preempt, true
+}
+
+// AppGroupTaskResult is the result of the task deploying an application group, with the deployed applications and the evicted ones, the same as the body and the header EvictedAppsKey of the synchronous response.
+type AppGroupTaskResult struct {
+	Apps        []models.AppInfo       `json:"apps"`
+	EvictedApps []executors.EvictedApp `json:"evictedApps,omitempty"` // the running applications evicted by preemption
+}
+
+// put the names of the evicted applications in the response header, separated by commas.
+func setEvictedAppsHeader(c *beego.Controller, evictedApps []executors.EvictedApp) {
+	if len(evictedApps) == 0 {
 		return
 	}
+	names := make([]string, 0, len(evictedApps))
+	for _, evictedApp := range evictedApps {
+		names = append(names, evictedApp.AppName)
+	}
+	c.Ctx.Output.Header(EvictedAppsKey, strings.Join(names, ","))
+}
 
-	c.Ctx.Output.Status = http.StatusCreated
-	c.Data["json"] = outApps
-	c.ServeJSON()
+// put the ID and the current position of the request in the scheduling queue in the response headers.
+func setQueueHeaders(c *beego.Controller, ticket *executors.QueueTicket) {
+	c.Ctx.Output.Header(QueueIdKey, ticket.ID())
+	c.Ctx.Output.Header(QueuePositionKey, strconv.Itoa(executors.ScheQueue.Position(ticket)))
 }
 
-func (c *AppGroupController) DoNewAppGroupForm() {
-	outErr := fmt.Errorf("Please set the \"Content-Type\" as \"%s\", because the functions to handle other content types have not been implemented.", JsonContentType)
+// a synchronous request is cancelled while waiting in the scheduling queue.
+func writeCancelledResponse(c *beego.Controller, runErr error) {
+	outErr := fmt.Errorf("The request is not run, error: %w", runErr)
 	beego.Error(outErr)
-	c.Ctx.ResponseWriter.WriteHeader(http.StatusMethodNotAllowed)
+	c.Ctx.ResponseWriter.WriteHeader(http.StatusConflict)
 	if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
 		beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
 	}
-	return
+}
+
+// the request waits in the scheduling queue with the highest priority of its applications.
+func maxAppPriority(apps []models.K8sApp) int {
+	priority := asmodel.MinPriority
+	for _, app := range apps {
+		if app.Priority > priority {
+			priority = app.Priority
+		}
+	}
+	return priority
+}
+
+// List the running and waiting scheduling and migration requests in the scheduling queue.
+// test command:
+// curl -i -X GET http://localhost:20000/appGroup/queue
+func (c *AppGroupController) ListQueue() {
+	c.Data["json"] = executors.ScheQueue.List()
+	c.ServeJSON()
+}
+
+// Cancel a waiting request in the scheduling queue. A running request cannot be cancelled.
+// test command:
+// curl -i -X DELETE http://localhost:20000/appGroup/queue/createAppGroup-1700000000000000000-1
+func (c *AppGroupController) CancelQueued() {
+	id := c.Ctx.Input.Param(":id")
+	if err := executors.ScheQueue.Cancel(id); err != nil {
+		outErr := fmt.Errorf("Cancel scheduling request [%s], error: %w", id, err)
+		beego.Error(outErr)
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, executors.ErrQueueItemNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, executors.ErrQueueItemRunning):
+			statusCode = http.StatusConflict
+		}
+		c.Ctx.ResponseWriter.WriteHeader(statusCode)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+
+	c.Ctx.ResponseWriter.WriteHeader(http.StatusOK)
 }
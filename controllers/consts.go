@@ -2,4 +2,7 @@ package controllers
 
 const (
 	JsonContentType = "application/json"
+
+	// By default, the long-running operations (e.g., creating VMs) are run as asynchronous tasks, and the response is the task, whose ID can be used to query the task. With this HTTP header set as "false", the response is the result of the operation.
+	AsyncHeaderKey = "Mcm-Async"
 )
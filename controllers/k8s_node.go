@@ -52,6 +52,7 @@ func (c *K8sNodeController) DeleteNode() {
 
 // delete multiple Kubernetes Nodes from the cluster
 // test command:
+// it runs as an asynchronous task by default. Add the header "Mcm-Async: false" to wait for the result in the response.
 // curl -i -X DELETE -H Content-Type:application/json http://localhost:20000/k8sNode -d '["auto-sched-hpe1-0","auto-sched-nokia4-0"]'
 func (c *K8sNodeController) DeleteNodes() {
 	var nodeNamesToDelete []string
@@ -69,6 +70,17 @@ func (c *K8sNodeController) DeleteNodes() {
 
 	beego.Info(fmt.Sprintf("Delete Kubernetes Nodes %v.", nodeNamesToDelete))
 
+	if isAsyncReq(&c.Controller) {
+		serveNewTask(&c.Controller, models.TaskTypeDeleteNodes, func(reporter models.TaskReporter) (interface{}, error) {
+			reporter.Logf("Deleting Kubernetes Nodes %v.", nodeNamesToDelete)
+			if errs := models.UninstallBatchNodes(nodeNamesToDelete); len(errs) != 0 {
+				return nil, models.HandleErrSlice(errs)
+			}
+			return nodeNamesToDelete, nil
+		})
+		return
+	}
+
 	// Use the parsed Kubernetes Nodes as the input information to delete Kubernetes Nodes
 	if errs := models.UninstallBatchNodes(nodeNamesToDelete); len(errs) != 0 {
 		outErr := models.HandleErrSlice(errs)
@@ -152,6 +164,7 @@ func (c *K8sNodeController) DoAddNodesForm() {
 }
 
 // test command:
+// it runs as an asynchronous task by default. Add the header "Mcm-Async: false" to wait for the result in the response.
 // curl -i -X POST -H Content-Type:application/json -d '[{"name":"hpe1","ips":["192.168.100.124"]},{"name":"cnode1","ips":["10.234.234.99"]},{"name":"cnode2","ips":["10.234.234.99"]},{"name":"nokia7","ips":["192.168.100.69"]}]' http://localhost:20000/k8sNode/doAdd
 func (c *K8sNodeController) DoAddNodesJson() {
 	var vms []models.IaasVm
@@ -168,6 +181,17 @@ func (c *K8sNodeController) DoAddNodesJson() {
 
 	beego.Info(fmt.Sprintf("From json input, we successfully parsed vms [%v]", vms))
 
+	if isAsyncReq(&c.Controller) {
+		serveNewTask(&c.Controller, models.TaskTypeAddNodes, func(reporter models.TaskReporter) (interface{}, error) {
+			reporter.Logf("Adding %d nodes to the Kubernetes cluster.", len(vms))
+			if errs := models.AddNodes(vms); len(errs) != 0 {
+				return nil, models.HandleErrSlice(errs)
+			}
+			return vms, nil
+		})
+		return
+	}
+
 	// Use the parsed vms to create VMs
 	if errs := models.AddNodes(vms); len(errs) != 0 {
 		outErr := models.HandleErrSlice(errs)
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego"
+
+	"emcontroller/models"
+)
+
+const defaultTaskListLimit int = 50
+
+type TaskController struct {
+	beego.Controller
+}
+
+// List the recent tasks, the newest first. The query parameter "limit" sets how many tasks to list.
+// test command:
+// curl -i -X GET http://localhost:20000/task?limit=10
+func (c *TaskController) Get() {
+	limit := defaultTaskListLimit
+	if limitStr := c.GetString("limit"); len(limitStr) != 0 {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			outErr := fmt.Errorf("parse the query parameter limit [%s] to int, error: %w", limitStr, err)
+			beego.Error(outErr)
+			c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
+			if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+				beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+			}
+			return
+		}
+	}
+
+	c.Data["json"] = models.Tasks.List(limit)
+	c.ServeJSON()
+}
+
+// Get a task by its ID.
+// test command:
+// curl -i -X GET http://localhost:20000/task/createVms-1700000000000000000
+func (c *TaskController) GetTask() {
+	taskID := c.Ctx.Input.Param(":id")
+	task, exist := models.Tasks.Get(taskID)
+	if !exist {
+		outErr := fmt.Errorf("Task [%s] not found", taskID)
+		beego.Error(outErr)
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusNotFound)
+		if result, err := c.Ctx.ResponseWriter.Write([]byte(outErr.Error())); err != nil {
+			beego.Error(fmt.Sprintf("Write Error to response, error: %s, result: %d", err.Error(), result))
+		}
+		return
+	}
+
+	c.Data["json"] = task
+	c.ServeJSON()
+}
+
+// check whether the operation should run as an asynchronous task. It does by default, so that a client timing out does not lose the result, unless the request opts out with the header set as "false".
+func isAsyncReq(c *beego.Controller) bool {
+	async := strings.ToLower(strings.TrimSpace(c.Ctx.Request.Header.Get(AsyncHeaderKey))) != "false"
+	beego.Info(fmt.Sprintf("The header %s is [%t]", AsyncHeaderKey, async))
+	return async
+}
+
+// start an asynchronous task, and respond the task with the status code 202 Accepted.
+func serveNewTask(c *beego.Controller, taskType string, work models.TaskWork) {
+	serveTask(c, models.Tasks.Start(taskType, work))
+}
+
+// the same as serveNewTask, but the task stays pending until its work calls reporter.SetRunning, e.g., when it leaves the scheduling queue.
+func serveNewQueuedTask(c *beego.Controller, taskType string, work models.TaskWork) {
+	serveTask(c, models.Tasks.StartQueued(taskType, work))
+}
+
+func serveTask(c *beego.Controller, task models.Task) {
+	c.Ctx.Output.Header("Location", fmt.Sprintf("/task/%s", task.ID))
+	c.Ctx.Output.Status = http.StatusAccepted
+	c.Data["json"] = task
+	c.ServeJSON()
+}
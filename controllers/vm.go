@@ -198,7 +198,7 @@ func (c *VmController) DoNewVmsForm() {
 			return
 		}
 		if vms[i].Storage, err = c.GetFloat(fmt.Sprintf("vm%dStorage", i)); err != nil {
-			outErr := fmt.Errorf("Get vms[%d].Storage, error: %w", err)
+			outErr := fmt.Errorf("Get vms[%d].Storage, error: %w", i, err)
 			beego.Error(outErr)
 			c.Data["errorMessage"] = outErr.Error()
 			c.TplName = "error.tpl"
@@ -236,6 +236,7 @@ func (c *VmController) DoNewVmsForm() {
 }
 
 // test command:
+// it runs as an asynchronous task by default. Add the header "Mcm-Async: false" to wait for the result in the response.
 // curl -i -X POST -H Content-Type:application/json -d '[{"name":"cnode1","vcpu":4,"ram":32768,"storage":400,"cloud":"CLAAUDIAweifan"},{"name":"cnode2","vcpu":4,"ram":32768,"storage":400,"cloud":"CLAAUDIAweifan"},{"name":"hpe1","vcpu":4,"ram":8192,"storage":100,"cloud":"HPE1"},{"name":"nokia7","vcpu":4,"ram":8192,"storage":100,"cloud":"NOKIA7"}]' http://localhost:20000/vm/doNew
 func (c *VmController) DoNewVmsJson() {
 	var vms []models.IaasVm
@@ -252,6 +253,14 @@ func (c *VmController) DoNewVmsJson() {
 
 	beego.Info(fmt.Sprintf("From json input, we successfully parsed vms [%v]", vms))
 
+	if isAsyncReq(&c.Controller) {
+		serveNewTask(&c.Controller, models.TaskTypeCreateVms, func(reporter models.TaskReporter) (interface{}, error) {
+			reporter.Logf("Creating %d VMs.", len(vms))
+			return models.CreateVms(vms)
+		})
+		return
+	}
+
 	// Use the parsed vms to create VMs
 	outVms, err := models.CreateVms(vms)
 	if err != nil {
@@ -27,6 +27,13 @@ funcsToTestInModels="${funcsToTestInModels}|TestRemoveNodeFromList"
 funcsToTestInModels="${funcsToTestInModels}|TestFindIdxVmInList"
 funcsToTestInModels="${funcsToTestInModels}|TestRemoveVmFromList"
 funcsToTestInModels="${funcsToTestInModels}|TestGetResOccupiedByPod"
+funcsToTestInModels="${funcsToTestInModels}|TestSimulatedCreateDeleteVM"
+funcsToTestInModels="${funcsToTestInModels}|TestSimulatedInjection"
+funcsToTestInModels="${funcsToTestInModels}|TestInitSimulated"
+funcsToTestInModels="${funcsToTestInModels}|TestSimulatedCreateDeleteBatchVms"
+funcsToTestInModels="${funcsToTestInModels}|TestTaskManager"
+funcsToTestInModels="${funcsToTestInModels}|TestTaskManagerInterrupted"
+funcsToTestInModels="${funcsToTestInModels}|TestTaskManagerList"
 funcsToTestInModels="${funcsToTestInModels})$"
 
 echo "In ${CURRENT_DIR}/models/, the functions to test are ${funcsToTestInModels}."
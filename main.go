@@ -10,6 +10,7 @@ import (
 	"github.com/astaxie/beego"
 
 	"emcontroller/auto-schedule/algorithms"
+	"emcontroller/auto-schedule/executors"
 	"emcontroller/models"
 	_ "emcontroller/routers"
 )
@@ -36,6 +37,8 @@ func main() {
 	}
 
 	models.InitSomeThing()
+	algorithms.InitGaParams()
+	executors.InitSchedulingQueue()
 
 	numCpuToUse := runtime.NumCPU()
 	beego.Info(fmt.Sprintf("Using %d CPU cores for goroutines.", numCpuToUse))
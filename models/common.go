@@ -6,4 +6,6 @@ func InitSomeThing() {
 
 	InitDockerClient()
 	InitKubernetesClient()
+
+	InitTasks()
 }
@@ -18,6 +18,7 @@ const (
 	// type of clouds
 	OpenstackIaas string = "openstack"
 	ProxmoxIaas   string = "proxmox"
+	SimulatedIaas string = "simulated" // in-memory cloud for offline development and tests
 
 	McmSign        string = "mcmcreated" // add this sign something, meaning that it is created by multi-cloud manager
 	WaitForTimeOut int    = 1800         // unit second. wait for 30 minutes when creating or deleting something. At first, it was 10 minutes, but Proxmox often use more than 10 minutes to clone a VM, so I changed this to 30 minutes.
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"sync"
 
 	"github.com/astaxie/beego"
@@ -70,6 +71,37 @@ type IaasVm struct {
 	McmCreate bool    `json:"mcmCreate"` // whether this VM is created by Multi-cloud manager
 }
 
+// VmFlavor is a size of VMs that a cloud offers. On the clouds with flavors (Openstack), VMs can only be created with the sizes of the flavors.
+type VmFlavor struct {
+	Name string  `json:"name"`
+	VCpu float64 `json:"vcpu"` // number of logical CPU cores
+	Ram  float64 `json:"ram"`  // memory size unit: MiB
+}
+
+// ChooseMinVmFlavor chooses the smallest flavor that can meet the required vCPU and RAM within the rest vCPU and RAM of a cloud, and returns its index in allFlavors.
+// Both the creation of VMs on Openstack and the scheduling choose flavors with this function, so the VM created is the same as the one in the scheduling.
+func ChooseMinVmFlavor(allFlavors []VmFlavor, reqCpu, reqRam, restCpu, restRam float64) (int, bool) {
+	var minIdx int
+	var found bool = false
+	for i, flavor := range allFlavors {
+		if flavor.VCpu < reqCpu || flavor.Ram < reqRam || flavor.VCpu > restCpu || flavor.Ram > restRam { // cannot meet the requirements
+			continue
+		}
+		if !found || vmFlavorOverflow(flavor, reqCpu, reqRam) < vmFlavorOverflow(allFlavors[minIdx], reqCpu, reqRam) {
+			minIdx = i
+			found = true
+		}
+	}
+	return minIdx, found
+}
+
+// the amount that a flavor overflows the required vCPU and RAM. A requirement less than 1 is counted as 1, so that we never divide by 0.
+func vmFlavorOverflow(flavor VmFlavor, reqCpu, reqRam float64) float64 {
+	cpuOverflow := (flavor.VCpu - reqCpu) / math.Max(reqCpu, 1)
+	ramOverflow := (flavor.Ram - reqRam) / math.Max(reqRam, 1)
+	return ramOverflow + cpuOverflow
+}
+
 // Resource set
 type ResSet struct {
 	VCpu    float64 `json:"vcpu"`    // number of logical CPU cores
@@ -133,9 +165,24 @@ func InitClouds() {
 		case ProxmoxIaas:
 			pCloud := InitProxmox(iaasParas[i])
 			Clouds[pCloud.Name] = pCloud
+		case SimulatedIaas:
+			sCloud := InitSimulated(iaasParas[i])
+			Clouds[sCloud.Name] = sCloud
 		default:
 			beego.Info(fmt.Sprintf("Multi-cloud manager does not support cloud type [%s] of cloud [%s]", iaasParas[i]["type"].(string), iaasParas[i]["name"].(string)))
 		}
+		// the pricing is optional for all cloud types
+		if name, ok := iaasParas[i]["name"].(string); ok {
+			if _, supported := Clouds[name]; supported {
+				pricing, err := ParseCloudPricing(iaasParas[i])
+				if err != nil {
+					panic(fmt.Errorf("parse the pricing of cloud [%s] in iaas.json, error: %w", name, err))
+				}
+				if pricing != nil {
+					CloudPricings[name] = *pricing
+				}
+			}
+		}
 	}
 	beego.Info(fmt.Sprintf("All %d clouds are initialized.", len(Clouds)))
 }
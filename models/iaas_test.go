@@ -500,6 +500,68 @@ func TestOverflow(t *testing.T) {
 	}
 }
 
+func TestChooseMinVmFlavor(t *testing.T) {
+	allFlavors := []VmFlavor{
+		{Name: "large", VCpu: 8, Ram: 16384},
+		{Name: "small", VCpu: 2, Ram: 4096},
+		{Name: "medium", VCpu: 4, Ram: 8192},
+		{Name: "cpu", VCpu: 8, Ram: 4096},
+	}
+	testCases := []struct {
+		name          string
+		reqCpu        float64
+		reqRam        float64
+		restCpu       float64
+		restRam       float64
+		expectedIdx   int
+		expectedFound bool
+	}{
+		{
+			name:          "case smallest flavor",
+			reqCpu:        3,
+			reqRam:        5000,
+			restCpu:       100,
+			restRam:       100000,
+			expectedIdx:   2,
+			expectedFound: true,
+		},
+		{
+			name:          "case least overflow",
+			reqCpu:        6,
+			reqRam:        4000,
+			restCpu:       100,
+			restRam:       100000,
+			expectedIdx:   3,
+			expectedFound: true,
+		},
+		{
+			name:          "case within the rest resources",
+			reqCpu:        3,
+			reqRam:        5000,
+			restCpu:       8,
+			restRam:       8000,
+			expectedIdx:   0,
+			expectedFound: false,
+		},
+		{
+			name:          "case zero requirements",
+			reqCpu:        0,
+			reqRam:        0,
+			restCpu:       100,
+			restRam:       100000,
+			expectedIdx:   1,
+			expectedFound: true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualIdx, actualFound := ChooseMinVmFlavor(allFlavors, testCase.reqCpu, testCase.reqRam, testCase.restCpu, testCase.restRam)
+		assert.Equal(t, testCase.expectedIdx, actualIdx, fmt.Sprintf("%s: index is not expected", testCase.name))
+		assert.Equal(t, testCase.expectedFound, actualFound, fmt.Sprintf("%s: found is not expected", testCase.name))
+	}
+}
+
 func TestGroupVmsByCloud(t *testing.T) {
 	testCases := []struct {
 		name           string
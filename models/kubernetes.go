@@ -85,6 +85,39 @@ func CreateDeployment(d *v1.Deployment) (*v1.Deployment, error) {
 	return createdDeployment, err
 }
 
+func UpdateDeployment(d *v1.Deployment) (*v1.Deployment, error) {
+	ctx := context.Background()
+	updatedDeployment, err := kubernetesClient.AppsV1().Deployments(d.Namespace).Update(ctx, d, metav1.UpdateOptions{})
+	if err != nil {
+		beego.Error(fmt.Sprintf("Update deployment %s/%s error: %s", d.Namespace, d.Name, err.Error()))
+	}
+	return updatedDeployment, err
+}
+
+// After a deployment is updated, wait until the rolling update of the generation is finished.
+func WaitForDeployUpdated(timeout int, checkInterval int, namespace, name string, generation int64) error {
+	return MyWaitFor(timeout, checkInterval, func() (bool, error) {
+		deploy, err := GetDeployment(namespace, name)
+		if err != nil {
+			beego.Error(fmt.Sprintf("Get deployment %s/%s, error: %s", namespace, name, err.Error()))
+			return false, nil
+		}
+		if deploy == nil {
+			return false, fmt.Errorf("Deployment %s/%s not found", namespace, name)
+		}
+		// Before the deployment controller observes the new generation, the status is still the one of the old generation.
+		if deploy.Status.ObservedGeneration < generation {
+			beego.Info(fmt.Sprintf("Deployment %s/%s, observed generation [%d] is older than [%d].", namespace, name, deploy.Status.ObservedGeneration, generation))
+			return false, nil
+		}
+		if !appRunning(*deploy) {
+			beego.Info(fmt.Sprintf("Deployment %s/%s, rolling update is not finished.", namespace, name))
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
 func DeleteDeployment(namespace, name string) error {
 	ctx := context.Background()
 	//deletePolicy := metav1.DeletePropagationForeground
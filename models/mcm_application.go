@@ -22,21 +22,47 @@ type K8sApp struct {
 	Replicas      int32               `json:"replicas"`
 	HostNetwork   bool                `json:"hostNetwork"`
 	NodeName      string              `json:"nodeName,omitempty"`
+	NodeNames     []string            `json:"nodeNames,omitempty"` // optional, the pods of this application can only run on these nodes, one pod on each node. Auto-scheduling uses it to place every replica.
 	NodeSelector  map[string]string   `json:"nodeSelector,omitempty"`
 	Tolerations   []corev1.Toleration `json:"tolerations,omitempty"`
 	Containers    []K8sContainer      `json:"containers"`
 	Priority      int                 `json:"priority"`
 	AutoScheduled bool                `json:"autoScheduled"`
 	Dependencies  []Dependency        `json:"dependencies,omitempty"` // The information of all applications that this application depends on, only useful for
+	// Optional, only useful for auto-scheduling. The expected computation time of this application by one CPU core, unit millisecond (ms). If it is 0, the Expected-Time-One-Cpu in the request header is used.
+	ExpCompuTimeOneCpu float64 `json:"expCompuTimeOneCpu,omitempty"`
+	// Optional, only useful for auto-scheduling. The replicas of this application should be spread across at least so many clouds, for availability. 0 means no requirement.
+	MinClouds int `json:"minClouds,omitempty"`
+	// Optional, only useful for auto-scheduling. The hard constraints about which clouds this application can be scheduled to.
+	Placement Placement `json:"placement,omitempty"`
+
+	// The Json of the information needed for auto-scheduling, which is set by auto-scheduling and put into the Annotation with key AutoScheduleInfoAnno, so that we can migrate this application later.
+	AutoScheduleInfo string `json:"-"`
 }
 
 // This is for the functionality of auto-schedule
-// In Dependency, only AppName is enough, because:
-// 1. Bandwidth is not considered in this model;
-// 2. RTT is not a hard requirement, but soft, which means that the smaller RTT the better, but high RTT is also OK.
-// A high RTT will only make the response slow, but the application will still work.
+// Only AppName is required. Without MaxRttMs and MinBandwidthMbps, the RTT to the dependent application is only optimized by the scheduling algorithms, which means that the smaller RTT the better, but a high RTT is also OK, as it only makes the response slow.
+// MaxRttMs and MinBandwidthMbps are hard constraints for the applications that cannot work with a high RTT or a low bandwidth (e.g., real-time services): an application is only accepted on a place where the network to its dependent application meets them.
 type Dependency struct {
-	AppName string `json:"appName"` // the name of the dependent application
+	AppName          string  `json:"appName"`                    // the name of the dependent application
+	MaxRttMs         float64 `json:"maxRttMs,omitempty"`         // optional, unit millisecond (ms). The maximum RTT to the dependent application. 0 means no requirement.
+	MinBandwidthMbps float64 `json:"minBandwidthMbps,omitempty"` // optional, unit Megabit per second (Mbps). The minimum bandwidth to the dependent application. 0 means no requirement.
+}
+
+// This is for the functionality of auto-schedule
+// Placement includes the hard constraints about where an application can be scheduled, e.g., some data must stay on the on-premises clouds. An empty field means no constraint.
+// The constraints about other applications are at the cloud level and only work when both applications are accepted.
+type Placement struct {
+	AllowedClouds    []string `json:"allowedClouds,omitempty"`    // the application can only be scheduled to these clouds
+	ForbiddenClouds  []string `json:"forbiddenClouds,omitempty"`  // the application cannot be scheduled to these clouds
+	CloudTypes       []string `json:"cloudTypes,omitempty"`       // the application can only be scheduled to the clouds of these types, e.g., "proxmox"
+	CoLocateWith     []string `json:"coLocateWith,omitempty"`     // the application should be scheduled to a cloud where these applications are
+	AntiCoLocateWith []string `json:"antiCoLocateWith,omitempty"` // the application cannot be scheduled to a cloud where these applications are
+}
+
+// IsEmpty tells whether there is no placement constraint.
+func (p Placement) IsEmpty() bool {
+	return len(p.AllowedClouds) == 0 && len(p.ForbiddenClouds) == 0 && len(p.CloudTypes) == 0 && len(p.CoLocateWith) == 0 && len(p.AntiCoLocateWith) == 0
 }
 
 type K8sContainer struct {
@@ -635,6 +661,24 @@ func CreateApplication(app K8sApp) error {
 	if len(app.NodeName) > 0 {
 		deployment.Spec.Template.Spec.NodeName = app.NodeName
 	}
+	// if the app in the request body has node names, we only allow the pods to run on these nodes. The pod anti-affinity above makes every node run only one pod.
+	if len(app.NodeNames) > 0 {
+		deployment.Spec.Template.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							corev1.NodeSelectorRequirement{
+								Key:      corev1.LabelHostname,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   app.NodeNames,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
 	// if the app in the request body has node selectors, we set them in K8s deployment
 	if len(app.NodeSelector) > 0 {
 		deployment.Spec.Template.Spec.NodeSelector = app.NodeSelector
@@ -652,6 +696,9 @@ func CreateApplication(app K8sApp) error {
 		}
 		deployment.Annotations[AutoScheduledAnno] = strconv.FormatBool(app.AutoScheduled)
 		deployment.Annotations[PriorityAnno] = strconv.Itoa(app.Priority)
+		if len(app.AutoScheduleInfo) > 0 {
+			deployment.Annotations[AutoScheduleInfoAnno] = app.AutoScheduleInfo
+		}
 	}
 
 	beego.Info(fmt.Sprintf("Create deployment [%+v]", deployment))
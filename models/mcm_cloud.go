@@ -11,6 +11,17 @@ type CloudInfo struct {
 	Type      string
 	WebUrl    string
 	Resources ResourceStatus
+	Pricing   *CloudPricing // nil if this cloud does not set pricing
+	// the estimated current spend per hour of this cloud, calculated from the resources in use and the pricing. It is 0 without pricing.
+	SpendHourly float64
+}
+
+// set the pricing and the spend estimate of the cloud
+func (ci *CloudInfo) setSpend() {
+	ci.Pricing = GetCloudPricing(ci.Name)
+	if ci.Pricing != nil {
+		ci.SpendHourly = ci.Pricing.SpendHourly(ci.Resources)
+	}
 }
 
 func ListClouds() ([]CloudInfo, []error) {
@@ -42,6 +53,7 @@ func ListClouds() ([]CloudInfo, []error) {
 				errsMu.Unlock()
 			}
 			thisCloud.Resources = resources
+			thisCloud.setSpend()
 
 			beego.Info(fmt.Sprintf("Cloud [%s], type [%s], resources [%+v]", thisCloud.Name, thisCloud.Type, thisCloud.Resources))
 			cloudListMu.Lock()
@@ -67,6 +79,7 @@ func GetCloud(cloudName string) (CloudInfo, []IaasVm, error, error) {
 		WebUrl:    cloud.ShowWebUrl(),
 		Resources: resources,
 	}
+	cloudInfo.setSpend()
 
 	// show all VMs of this cloud on the web
 	vmList, errVMs := cloud.ListAllVMs()
@@ -39,8 +39,13 @@ const (
 	NetPerfDbName    string = "multi_cloud"
 	DbFieldCloudName string = "target_cloud_name" // field in the tables of network performance database
 	DbFieldRtt       string = "rtt_ms"            // field in the tables of network performance database
+	DbFieldBandwidth string = "bandwidth_mbps"    // field in the tables of network performance database
 
 	UnreachableRttMs float64 = 250000 // unit: millisecond (ms). when a cloud is unreachable from another, in the database, we set this value as the RTT between them. This value should be consistent with that in "net-perf-container-image/client.sh"
+	// unit: Megabit per second (Mbps). when a cloud is unreachable from another, in the database, we set this value as the bandwidth between them. This value should be consistent with that in "net-perf-container-image/client.sh"
+	UnreachableBandwidthMbps float64 = 0
+	// unit: Megabit per second (Mbps). when a cloud is reachable from another but iperf3 fails to measure the bandwidth, in the database, we set this value, which means that the bandwidth is unknown. This value should be consistent with that in "net-perf-container-image/client.sh"
+	UnknownBandwidthMbps float64 = -1
 )
 
 var (
@@ -366,7 +371,7 @@ func initDbTables() error {
 
 	for cloudName, _ := range Clouds {
 		tableName := cloudName
-		query := fmt.Sprintf("create table %s.%s(%s varchar(768) not null,%s double not null, primary key(%s))", NetPerfDbName, tableName, DbFieldCloudName, DbFieldRtt, DbFieldCloudName)
+		query := fmt.Sprintf("create table %s.%s(%s varchar(768) not null,%s double not null,%s double not null, primary key(%s))", NetPerfDbName, tableName, DbFieldCloudName, DbFieldRtt, DbFieldBandwidth, DbFieldCloudName)
 		result, err := db.Query(query)
 		if err != nil {
 			outErr := fmt.Errorf("Query [%s], error [%w].", query, err)
@@ -377,15 +382,15 @@ func initDbTables() error {
 		beego.Info(fmt.Sprintf("Query [%s] successfully.", query))
 
 		for targetCloudName, _ := range Clouds {
-			query := fmt.Sprintf("insert into %s.%s (%s, %s) values (?, ?)", NetPerfDbName, tableName, DbFieldCloudName, DbFieldRtt)
-			result, err := db.Query(query, targetCloudName, math.MaxFloat64)
+			query := fmt.Sprintf("insert into %s.%s (%s, %s, %s) values (?, ?, ?)", NetPerfDbName, tableName, DbFieldCloudName, DbFieldRtt, DbFieldBandwidth)
+			result, err := db.Query(query, targetCloudName, math.MaxFloat64, UnreachableBandwidthMbps)
 			if err != nil {
-				outErr := fmt.Errorf("Query [%s], args: [%s, %g], error [%w].", query, targetCloudName, math.MaxFloat64, err)
+				outErr := fmt.Errorf("Query [%s], args: [%s, %g, %g], error [%w].", query, targetCloudName, math.MaxFloat64, UnreachableBandwidthMbps, err)
 				beego.Error(outErr)
 				return outErr
 			}
 			result.Close()
-			beego.Info(fmt.Sprintf("Query [%s], args: [%s, %g] successfully.", query, targetCloudName, math.MaxFloat64))
+			beego.Info(fmt.Sprintf("Query [%s], args: [%s, %g, %g] successfully.", query, targetCloudName, math.MaxFloat64, UnreachableBandwidthMbps))
 		}
 	}
 	beego.Info(fmt.Sprintf("Successful! Create and initialize tables for network performance in database [%s].", NetPerfDbName))
@@ -420,7 +425,7 @@ func initDbTablesWithUse() error {
 
 	for cloudName, _ := range Clouds {
 		tableName := cloudName
-		query := fmt.Sprintf("create table %s(%s varchar(768) not null,%s double not null, primary key(%s))", tableName, DbFieldCloudName, DbFieldRtt, DbFieldCloudName)
+		query := fmt.Sprintf("create table %s(%s varchar(768) not null,%s double not null,%s double not null, primary key(%s))", tableName, DbFieldCloudName, DbFieldRtt, DbFieldBandwidth, DbFieldCloudName)
 		result, err := db.Query(query)
 		if err != nil {
 			outErr := fmt.Errorf("Query [%s], error [%w].", query, err)
@@ -431,15 +436,15 @@ func initDbTablesWithUse() error {
 		beego.Info(fmt.Sprintf("Query [%s] successfully.", query))
 
 		for targetCloudName, _ := range Clouds {
-			query := fmt.Sprintf("insert into %s (%s, %s) values (?, ?)", tableName, DbFieldCloudName, DbFieldRtt)
-			result, err := db.Query(query, targetCloudName, UnreachableRttMs)
+			query := fmt.Sprintf("insert into %s (%s, %s, %s) values (?, ?, ?)", tableName, DbFieldCloudName, DbFieldRtt, DbFieldBandwidth)
+			result, err := db.Query(query, targetCloudName, UnreachableRttMs, UnreachableBandwidthMbps)
 			if err != nil {
-				outErr := fmt.Errorf("Query [%s], args: [%s, %g], error [%w].", query, targetCloudName, math.MaxFloat64, err)
+				outErr := fmt.Errorf("Query [%s], args: [%s, %g, %g], error [%w].", query, targetCloudName, UnreachableRttMs, UnreachableBandwidthMbps, err)
 				beego.Error(outErr)
 				return outErr
 			}
 			result.Close()
-			beego.Info(fmt.Sprintf("Query [%s], args: [%s, %g] successfully.", query, targetCloudName, math.MaxFloat64))
+			beego.Info(fmt.Sprintf("Query [%s], args: [%s, %g, %g] successfully.", query, targetCloudName, UnreachableRttMs, UnreachableBandwidthMbps))
 		}
 	}
 	beego.Info(fmt.Sprintf("Successful! Create and initialize tables for network performance in database [%s].", NetPerfDbName))
@@ -576,30 +581,30 @@ func deleteNetTestServer(cloud Iaas) error {
 	return nil
 }
 
-// From each cloud to each cloud, we run a Kubernetes Job to measure the RTT and write the RTT in the database.
+// From each cloud to each cloud, we run a Kubernetes Job to measure the RTT and bandwidth and write them in the database.
+// The iperf3 server on a cloud only serves one client at a time, so the Jobs to the same cloud run one by one, and the Jobs to different clouds run in parallel.
 func executeNetTestClients() error {
 	beego.Info("Start to execute the network performance test client Job on every net test client VM.")
 
-	// Do it in parallel
+	// Do it in parallel for every target cloud
 	var wg sync.WaitGroup
 	var errsMu sync.Mutex // the slice in golang is not safe for concurrent read/write
 	var errs []error
-	for nameFrom, cloudFrom := range Clouds {
-		for nameTo, cloudTo := range Clouds {
-			beego.Info(fmt.Sprintf("Execute the network performance test client Job from cloud [%s] to cloud [%s]", nameFrom, nameTo))
-			wg.Add(1)
-			go func(cF Iaas, cT Iaas) {
-				defer wg.Done()
+	for _, cloudTo := range Clouds {
+		wg.Add(1)
+		go func(cT Iaas) {
+			defer wg.Done()
+			for _, cF := range Clouds {
+				beego.Info(fmt.Sprintf("Execute the network performance test client Job from cloud [%s] to cloud [%s]", cF.ShowName(), cT.ShowName()))
 				if err := executeNetTestClient(cF, cT); err != nil {
 					outErr := fmt.Errorf("Cannot execute the network performance test client Job from cloud [%s] to cloud [%s], error: [%w]", cF.ShowName(), cT.ShowName(), err)
 					beego.Error(outErr)
 					errsMu.Lock()
 					errs = append(errs, outErr)
 					errsMu.Unlock()
-					return
 				}
-			}(cloudFrom, cloudTo)
-		}
+			}
+		}(cloudTo)
 	}
 	wg.Wait()
 
@@ -614,12 +619,12 @@ func executeNetTestClients() error {
 	return nil
 }
 
-// Run a network performance test Job on cloudFrom to measure the RTT from cloudFrom to cloudTo, and write the RTT value in the MySQL database.
+// Run a network performance test Job on cloudFrom to measure the RTT and bandwidth from cloudFrom to cloudTo, and write the values in the MySQL database.
 func executeNetTestClient(cloudFrom, cloudTo Iaas) error {
 	var errExist bool = false
 	defer func() {
 		if errExist {
-			beego.Info(fmt.Sprintf("executeNetTestClient from [%s] to [%s] error exist, so we set the unreachable RTT between these 2 clouds in the database.", cloudFrom.ShowName(), cloudTo.ShowName()))
+			beego.Info(fmt.Sprintf("executeNetTestClient from [%s] to [%s] error exist, so we set the unreachable RTT and bandwidth between these 2 clouds in the database.", cloudFrom.ShowName(), cloudTo.ShowName()))
 			if err := setUnreachableRtt(cloudFrom.ShowName(), cloudTo.ShowName()); err != nil {
 				beego.Error(fmt.Sprintf("setUnreachableRtt from [%s] to [%s] error: [%s]", cloudFrom.ShowName(), cloudTo.ShowName(), err.Error()))
 			}
@@ -759,7 +764,7 @@ func deleteNetTestClient(cloudFrom, cloudTo Iaas) error {
 	return nil
 }
 
-// when a cloud is cannot access another, in the database, we set a very big RTT value between them.
+// when a cloud is cannot access another, in the database, we set a very big RTT value and the unreachable bandwidth between them.
 // we only need to do it in executeNetTestClient, no need for runNetTestServer, because if the server does not work, the client will fail, so doing it only for clients is enough.
 func setUnreachableRtt(nameCloudFrom, nameCloudTo string) error {
 	db, err := NewMySqlCli()
@@ -770,7 +775,7 @@ func setUnreachableRtt(nameCloudFrom, nameCloudTo string) error {
 	}
 	defer db.Close()
 
-	query := fmt.Sprintf("update %s.%s set rtt_ms=%g where %s='%s'", NetPerfDbName, nameCloudFrom, UnreachableRttMs, DbFieldCloudName, nameCloudTo)
+	query := fmt.Sprintf("update %s.%s set %s=%g, %s=%g where %s='%s'", NetPerfDbName, nameCloudFrom, DbFieldRtt, UnreachableRttMs, DbFieldBandwidth, UnreachableBandwidthMbps, DbFieldCloudName, nameCloudTo)
 
 	// without timeout, this request may be stuck forever if there are some problems
 	ctx, cancel := context.WithTimeout(context.Background(), ReqShortTimeout)
@@ -9,7 +9,13 @@ import (
 )
 
 type NetworkState struct {
-	Rtt float64 `json:"rtt"` // Round-Trip Time, unit millisecond (ms)
+	Rtt       float64 `json:"rtt"`       // Round-Trip Time, unit millisecond (ms)
+	Bandwidth float64 `json:"bandwidth"` // throughput measured by iperf3, unit Megabit per second (Mbps). UnknownBandwidthMbps means that it cannot be measured.
+}
+
+// BandwidthKnown tells whether the bandwidth is measured.
+func (ns NetworkState) BandwidthKnown() bool {
+	return ns.Bandwidth >= 0
 }
 
 // Check network state from MySQL and return the result as a matrix.
@@ -72,7 +78,7 @@ func GetNetStateOneCloud(cloudName string) (map[string]NetworkState, error) {
 	}
 	defer db.Close()
 
-	query := fmt.Sprintf("select * from %s.%s", NetPerfDbName, cloudName)
+	query := fmt.Sprintf("select %s, %s, %s from %s.%s", DbFieldCloudName, DbFieldRtt, DbFieldBandwidth, NetPerfDbName, cloudName)
 
 	// without timeout, this request may be stuck forever if there are some problems
 	ctx, cancel := context.WithTimeout(context.Background(), ReqShortTimeout)
@@ -88,14 +94,14 @@ func GetNetStateOneCloud(cloudName string) (map[string]NetworkState, error) {
 	var netStates map[string]NetworkState = make(map[string]NetworkState)
 	for result.Next() {
 		var targetCloudName string
-		var rtt float64
-		if err := result.Scan(&targetCloudName, &rtt); err != nil {
+		var rtt, bandwidth float64
+		if err := result.Scan(&targetCloudName, &rtt, &bandwidth); err != nil {
 			outErr := fmt.Errorf("Query [%s], result.Scan, error [%w].", query, err)
 			beego.Error(outErr)
 			beego.Error(fmt.Sprintf("Current netStates: %v", netStates))
 			return nil, outErr
 		}
-		netStates[targetCloudName] = NetworkState{Rtt: rtt}
+		netStates[targetCloudName] = NetworkState{Rtt: rtt, Bandwidth: bandwidth}
 	}
 
 	beego.Info(fmt.Sprintf("Query [%s] successfully.", query))
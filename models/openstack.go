@@ -488,40 +488,26 @@ func IsOs404(err error) bool {
 	return strings.Contains(err.Error(), Os404Substr)
 }
 
-// Choose the smallest flavor that can meet the requirements of RAM and vCPU
+// Choose the smallest flavor that can meet the requirements of RAM and vCPU within the remaining quota, in the same way as the scheduling (ChooseMinVmFlavor).
 func (os *Openstack) ChooseMinFlavor(allFlavors []flavors.Flavor, reqCpu, reqRam int) (flavors.Flavor, bool) {
-	var minFlavor flavors.Flavor
-	var found bool = false
 	computeQuota, err := os.GetComputeQuota()
 	if err != nil {
 		beego.Error(fmt.Sprintf("Get comput quota error: %s", err.Error()))
-		return minFlavor, found
+		return flavors.Flavor{}, false
 	}
 	beego.Info(fmt.Sprintf("Try to find a flavor to meet vCPU %d, RAM %d MiB. The vCPU Limit quota is %d, in use is %d. The RAM Limit quota is %d MiB, in use is %d MiB", reqCpu, reqRam, computeQuota.Cores.Limit, computeQuota.Cores.InUse, computeQuota.RAM.Limit, computeQuota.RAM.InUse))
 	remainingVcpu := computeQuota.Cores.Limit - computeQuota.Cores.InUse
 	remainingRam := computeQuota.RAM.Limit - computeQuota.RAM.InUse
-	for i := 0; i < len(allFlavors); i++ {
-		if allFlavors[i].VCPUs < reqCpu || allFlavors[i].RAM < reqRam || allFlavors[i].VCPUs > remainingVcpu || allFlavors[i].RAM > remainingRam { // count meet the requirements
-			continue
-		}
-		if !found { // the first one that can meet the requirements
-			minFlavor = allFlavors[i]
-			found = true
-			continue
-		}
-		if os.overflowFlavor(allFlavors[i], reqCpu, reqRam) < os.overflowFlavor(minFlavor, reqCpu, reqRam) {
-			minFlavor = allFlavors[i]
-		}
 
+	vmFlavors := make([]VmFlavor, len(allFlavors))
+	for i, flavor := range allFlavors {
+		vmFlavors[i] = VmFlavor{Name: flavor.Name, VCpu: float64(flavor.VCPUs), Ram: float64(flavor.RAM)}
 	}
-	return minFlavor, found
-}
-
-// Calculate the amount that a flavor overflows the required RAM and vCPU
-func (os *Openstack) overflowFlavor(flavor flavors.Flavor, reqCpu, reqRam int) float64 {
-	cpuOverflow := (float64(flavor.VCPUs) - float64(reqCpu)) / float64(reqCpu)
-	ramOverflow := (float64(flavor.RAM) - float64(reqRam)) / float64(reqRam)
-	return ramOverflow + cpuOverflow
+	minIdx, found := ChooseMinVmFlavor(vmFlavors, float64(reqCpu), float64(reqRam), float64(remainingVcpu), float64(remainingRam))
+	if !found {
+		return flavors.Flavor{}, false
+	}
+	return allFlavors[minIdx], true
 }
 
 // extract IP addresses from a server, imitating github.com\gophercloud\gophercloud@v1.1.1\acceptance\openstack\compute\v2\floatingip_test.go
@@ -625,6 +611,25 @@ func (os *Openstack) ListAllFavors() ([]flavors.Flavor, error) {
 	return allFlavors, err
 }
 
+// ListVmFlavors lists the flavors of this cloud as the sizes of VMs that can be created. The disk of flavors is not included, because we boot VMs from volumes whose size can be set freely.
+func (os *Openstack) ListVmFlavors() ([]VmFlavor, error) {
+	allFlavors, err := os.ListAllFavors()
+	if err != nil {
+		outErr := fmt.Errorf("Cloud name [%s], type [%s], list VM flavors error: %w", os.Name, os.Type, err)
+		beego.Error(outErr)
+		return []VmFlavor{}, outErr
+	}
+	vmFlavors := make([]VmFlavor, 0, len(allFlavors))
+	for _, flavor := range allFlavors {
+		vmFlavors = append(vmFlavors, VmFlavor{
+			Name: flavor.Name,
+			VCpu: float64(flavor.VCPUs),
+			Ram:  float64(flavor.RAM),
+		})
+	}
+	return vmFlavors, nil
+}
+
 func (os *Openstack) GetFlavor(id string) (*flavors.Flavor, error) {
 	vol, err := flavors.Get(os.ComputeClient, id).Extract()
 	if err != nil {
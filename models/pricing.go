@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CloudPricing is the optional price of the resources of a cloud, set by "pricing" in iaas.json. All prices are per hour, in the currency chosen by the user.
+type CloudPricing struct {
+	VCpuHour       float64 `json:"vcpu_hour"`        // the price of one logical CPU core per hour
+	RamGibHour     float64 `json:"ram_gib_hour"`     // the price of 1 GiB memory per hour
+	StorageGibHour float64 `json:"storage_gib_hour"` // the price of 1 GiB storage per hour
+	VmHour         float64 `json:"vm_hour"`          // the fixed price of every VM per hour, apart from its resources
+}
+
+// the global variable to record the pricing of the clouds. The key is the cloud name. The clouds without pricing are not in it.
+var CloudPricings map[string]CloudPricing = make(map[string]CloudPricing)
+
+// ParseCloudPricing reads "pricing" from the parameters of a cloud in iaas.json. If there is no "pricing", it returns nil.
+func ParseCloudPricing(paras map[string]interface{}) (*CloudPricing, error) {
+	pricingPara, exist := paras["pricing"]
+	if !exist || pricingPara == nil {
+		return nil, nil
+	}
+	pricingJson, err := json.Marshal(pricingPara)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal pricing [%v], error: %w", pricingPara, err)
+	}
+	var pricing CloudPricing
+	if err := json.Unmarshal(pricingJson, &pricing); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal pricing [%s], error: %w", string(pricingJson), err)
+	}
+	if pricing.VCpuHour < 0 || pricing.RamGibHour < 0 || pricing.StorageGibHour < 0 || pricing.VmHour < 0 {
+		return nil, fmt.Errorf("prices should not be negative, but the pricing is %s", JsonString(pricing))
+	}
+	return &pricing, nil
+}
+
+// ResourcesHourly is the price per hour of the resources. ramMiB is in MiB, and storageGiB is in GiB, the same as ResSet.
+func (p CloudPricing) ResourcesHourly(vcpu, ramMiB, storageGiB float64) float64 {
+	return vcpu*p.VCpuHour + ramMiB/1024*p.RamGibHour + storageGiB*p.StorageGibHour
+}
+
+// VmHourly is the price per hour of a VM, including its resources and the fixed price of a VM.
+func (p CloudPricing) VmHourly(vm IaasVm) float64 {
+	return p.ResourcesHourly(vm.VCpu, vm.Ram, vm.Storage) + p.VmHour
+}
+
+// SpendHourly estimates the current spend per hour of a cloud from the resources in use.
+func (p CloudPricing) SpendHourly(resources ResourceStatus) float64 {
+	return p.ResourcesHourly(resources.InUse.VCpu, resources.InUse.Ram, resources.InUse.Storage) + resources.InUse.Vm*p.VmHour
+}
+
+// GetCloudPricing returns the pricing of a cloud, or nil if the cloud does not set pricing.
+func GetCloudPricing(cloudName string) *CloudPricing {
+	pricing, exist := CloudPricings[cloudName]
+	if !exist {
+		return nil
+	}
+	return &pricing
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCloudPricing(t *testing.T) {
+	testCases := []struct {
+		name           string
+		paras          map[string]interface{}
+		expectedResult *CloudPricing
+		expectedErr    bool
+	}{
+		{
+			name:           "case no pricing",
+			paras:          map[string]interface{}{"name": "SIM1"},
+			expectedResult: nil,
+		},
+		{
+			name: "case all prices",
+			paras: map[string]interface{}{"name": "SIM1", "pricing": map[string]interface{}{
+				"vcpu_hour": 0.02, "ram_gib_hour": 0.005, "storage_gib_hour": 0.0001, "vm_hour": 0.01,
+			}},
+			expectedResult: &CloudPricing{VCpuHour: 0.02, RamGibHour: 0.005, StorageGibHour: 0.0001, VmHour: 0.01},
+		},
+		{
+			name:           "case some prices",
+			paras:          map[string]interface{}{"name": "SIM1", "pricing": map[string]interface{}{"vcpu_hour": 0.5}},
+			expectedResult: &CloudPricing{VCpuHour: 0.5},
+		},
+		{
+			name:        "case negative",
+			paras:       map[string]interface{}{"name": "SIM1", "pricing": map[string]interface{}{"vm_hour": -1}},
+			expectedErr: true,
+		},
+		{
+			name:        "case not number",
+			paras:       map[string]interface{}{"name": "SIM1", "pricing": map[string]interface{}{"vm_hour": "cheap"}},
+			expectedErr: true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		actualResult, err := ParseCloudPricing(testCase.paras)
+		if testCase.expectedErr {
+			assert.NotNil(t, err, fmt.Sprintf("%s: should return error", testCase.name))
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedResult, actualResult, fmt.Sprintf("%s: result is not expected", testCase.name))
+	}
+}
+
+func TestCloudPricingHourly(t *testing.T) {
+	pricing := CloudPricing{VCpuHour: 0.02, RamGibHour: 0.01, StorageGibHour: 0.001, VmHour: 0.05}
+
+	assert.InDelta(t, 4*0.02+2*0.01+100*0.001, pricing.ResourcesHourly(4, 2048, 100), 1e-9)
+	assert.InDelta(t, 4*0.02+2*0.01+100*0.001+0.05, pricing.VmHourly(IaasVm{VCpu: 4, Ram: 2048, Storage: 100}), 1e-9)
+
+	resources := ResourceStatus{
+		Limit: ResSet{VCpu: 64, Ram: 65536, Storage: 1000, Vm: -1},
+		InUse: ResSet{VCpu: 8, Ram: 8192, Storage: 200, Vm: 2},
+	}
+	assert.InDelta(t, 8*0.02+8*0.01+200*0.001+2*0.05, pricing.SpendHourly(resources), 1e-9)
+}
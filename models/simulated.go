@@ -0,0 +1,285 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego"
+)
+
+// The first VM ID of a simulated cloud, imitating the qemu IDs of Proxmox which start from 100.
+const simFirstVmId int = 100
+
+// Simulated is an in-memory cloud. It implements the interface Iaas without calling any real cloud APIs, so that we can run the functions of multi-cloud manager (e.g., CreateVms, DeleteBatchVms, auto-scheduling) on a laptop without cloud access.
+// The capacity of a simulated cloud is configurable, and we can inject latency and failures into its APIs to imitate real clouds.
+type Simulated struct {
+	Name   string
+	Type   string
+	WebUrl string
+
+	Limit ResSet // the total amounts of resources of this cloud
+
+	Latency     time.Duration // the latency injected into every API call of this cloud
+	FailureRate float64       // the probability, in [0, 1], that an API call of this cloud fails
+
+	vms    map[string]IaasVm // key: VM ID
+	nextID int               // the ID used by the next created VM
+	rander *rand.Rand        // the random generator to decide injected failures
+	mu     sync.Mutex        // the map in golang is not safe for concurrent read/write
+}
+
+// NewSimulated creates a simulated cloud with the input capacity and without injected latency or failures.
+func NewSimulated(name string, limit ResSet) *Simulated {
+	return &Simulated{
+		Name:        name,
+		Type:        SimulatedIaas,
+		WebUrl:      "",
+		Limit:       limit,
+		Latency:     0,
+		FailureRate: 0,
+		vms:         make(map[string]IaasVm),
+		nextID:      simFirstVmId,
+		rander:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// InitSimulated creates a simulated cloud from the parameters in iaas.json.
+// Apart from "type" and "name", all parameters are optional:
+// "vcpu", "ram" (MiB), "storage" (GiB), "vm", "volume", "port" are the capacity, negative values mean unlimited;
+// "latency_ms" is the latency injected into every API call;
+// "failure_rate" is the probability that an API call fails;
+// "seed" is the seed of the random generator deciding the injected failures.
+func InitSimulated(paras map[string]interface{}) *Simulated {
+	beego.Info(fmt.Sprintf("Start to initialize cloud name [%s] type [%s]", paras["name"].(string), paras["type"].(string)))
+
+	s := NewSimulated(paras["name"].(string), ResSet{
+		VCpu:    simParaFloat(paras, "vcpu", 64),
+		Ram:     simParaFloat(paras, "ram", 262144),
+		Vm:      simParaFloat(paras, "vm", -1),
+		Volume:  simParaFloat(paras, "volume", -1),
+		Storage: simParaFloat(paras, "storage", 4096),
+		Port:    simParaFloat(paras, "port", -1),
+	})
+	if webUrl, ok := paras["weburl"].(string); ok {
+		s.WebUrl = webUrl
+	}
+	s.Latency = time.Duration(simParaFloat(paras, "latency_ms", 0) * float64(time.Millisecond))
+	s.FailureRate = simParaFloat(paras, "failure_rate", 0)
+	if _, exist := paras["seed"]; exist {
+		s.rander = rand.New(rand.NewSource(int64(simParaFloat(paras, "seed", 0))))
+	}
+
+	return s
+}
+
+// read a number parameter of a simulated cloud. In iaas.json, the number can be either a json number or a string.
+func simParaFloat(paras map[string]interface{}, key string, defaultValue float64) float64 {
+	switch value := paras[key].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case string:
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		beego.Error(fmt.Sprintf("Simulated cloud parameter [%s] value [%s] cannot be parsed to float64, we use the default value [%g].", key, value, defaultValue))
+	}
+	return defaultValue
+}
+
+func (s *Simulated) ShowName() string {
+	return s.Name
+}
+
+func (s *Simulated) ShowType() string {
+	return s.Type
+}
+
+func (s *Simulated) ShowWebUrl() string {
+	return s.WebUrl
+}
+
+// simulate calling an API of a cloud: wait for the injected latency, and fail with the injected failure rate.
+func (s *Simulated) simulateCall(action string) error {
+	if s.Latency > 0 {
+		time.Sleep(s.Latency)
+	}
+	s.mu.Lock()
+	failed := s.FailureRate > 0 && s.rander.Float64() < s.FailureRate
+	s.mu.Unlock()
+	if failed {
+		outErr := fmt.Errorf("Cloud name [%s], type [%s], %s, injected failure", s.Name, s.Type, action)
+		beego.Error(outErr)
+		return outErr
+	}
+	return nil
+}
+
+// calculate the resources used by all VMs of this cloud. The caller should hold s.mu.
+func (s *Simulated) inUse() ResSet {
+	inUse := ResSet{
+		Vm:     float64(len(s.vms)),
+		Volume: float64(len(s.vms)),
+		Port:   float64(len(s.vms)),
+	}
+	for _, vm := range s.vms {
+		inUse.VCpu += vm.VCpu
+		inUse.Ram += vm.Ram
+		inUse.Storage += vm.Storage
+	}
+	return inUse
+}
+
+func (s *Simulated) GetVM(vmID string) (*IaasVm, error) {
+	if err := s.simulateCall(fmt.Sprintf("get VM [%s]", vmID)); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vm, exist := s.vms[vmID]
+	if !exist {
+		outErr := fmt.Errorf("Cloud name [%s], type [%s], get VM [%s], VM not found", s.Name, s.Type, vmID)
+		beego.Error(outErr)
+		return nil, outErr
+	}
+	return &vm, nil
+}
+
+func (s *Simulated) ListAllVMs() ([]IaasVm, error) {
+	if err := s.simulateCall("list all VMs"); err != nil {
+		return []IaasVm{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var vms []IaasVm
+	for _, vm := range s.vms {
+		vms = append(vms, vm)
+	}
+	// the iteration order of map is random, so we sort the VMs to make the result definite.
+	sort.Slice(vms, func(i, j int) bool {
+		return vms[i].Name < vms[j].Name
+	})
+	return vms, nil
+}
+
+// the unit of vcpu, ram, storage in the input is consistent with ResSet
+func (s *Simulated) CreateVM(name string, vcpu, ram, storage int) (*IaasVm, error) {
+	beego.Info(fmt.Sprintf("Cloud name [%s], type [%s], Create VM: %s", s.Name, s.Type, name))
+	if err := s.simulateCall(fmt.Sprintf("create VM [%s]", name)); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the same as real clouds, we cannot create VMs more than the capacity.
+	afterCreate := ResourceStatus{
+		Limit: s.Limit,
+		InUse: s.inUse(),
+	}
+	afterCreate.InUse.VCpu += float64(vcpu)
+	afterCreate.InUse.Ram += float64(ram)
+	afterCreate.InUse.Storage += float64(storage)
+	afterCreate.InUse.Vm++
+	if afterCreate.Overflow() || (s.Limit.Vm >= 0 && afterCreate.InUse.Vm > s.Limit.Vm) {
+		outErr := fmt.Errorf("Cloud name [%s], type [%s], CreateVM [%s], vcpu [%d], ram [%d MiB], storage [%d GiB], resources are not enough, limit [%+v], in use [%+v]", s.Name, s.Type, name, vcpu, ram, storage, s.Limit, s.inUse())
+		beego.Error(outErr)
+		return nil, outErr
+	}
+
+	vmID := strconv.Itoa(s.nextID)
+	vm := IaasVm{
+		ID:        vmID,
+		Name:      name,
+		IPs:       []string{fmt.Sprintf("192.0.2.%d", s.nextID%256)}, // TEST-NET-1, never routed
+		VCpu:      float64(vcpu),
+		Ram:       float64(ram),
+		Storage:   float64(storage),
+		Status:    ProxQSRunning,
+		Cloud:     s.Name,
+		CloudType: s.Type,
+		McmCreate: true,
+	}
+	s.vms[vmID] = vm
+	s.nextID++
+
+	beego.Info(fmt.Sprintf("Successful! Cloud name [%s], type [%s], Create VM: %s", s.Name, s.Type, name))
+	return &vm, nil
+}
+
+func (s *Simulated) DeleteVM(vmID string) error {
+	beego.Info(fmt.Sprintf("Cloud name [%s], type [%s], Delete VM: %s", s.Name, s.Type, vmID))
+	if err := s.simulateCall(fmt.Sprintf("delete VM [%s]", vmID)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vm, exist := s.vms[vmID]
+	if !exist {
+		outErr := fmt.Errorf("Cloud name [%s], type [%s], DeleteVM [%s], VM not found", s.Name, s.Type, vmID)
+		beego.Error(outErr)
+		return outErr
+	}
+	// Multi-cloud manager is only allowed to delete VMs created by itself
+	if !vm.McmCreate {
+		outErr := fmt.Errorf("Cloud name [%s], type [%s], DeleteVM [%s], the VM is not created by multi-cloud manager, so we cannot delete it.", s.Name, s.Type, vmID)
+		beego.Error(outErr)
+		return outErr
+	}
+	delete(s.vms, vmID)
+
+	beego.Info(fmt.Sprintf("Successful! Cloud name [%s], type [%s], Delete VM: %s", s.Name, s.Type, vmID))
+	return nil
+}
+
+func (s *Simulated) CheckResources() (ResourceStatus, error) {
+	if err := s.simulateCall("check resources"); err != nil {
+		return errRs, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ResourceStatus{
+		Limit: s.Limit,
+		InUse: s.inUse(),
+	}, nil
+}
+
+func (s *Simulated) IsCreatedByMcm(vmID string) (bool, error) {
+	vm, err := s.GetVM(vmID)
+	if err != nil {
+		outErr := fmt.Errorf("check whether VM [%s] is created by multi-cloud manager, get VM error: %w", vmID, err)
+		beego.Error(outErr)
+		return false, outErr
+	}
+	return vm.McmCreate, nil
+}
+
+// AddExistingVM puts a VM into this simulated cloud directly, without injected latency or failures and without checking the capacity, to simulate the VMs that already exist in a cloud, e.g., the VMs not created by multi-cloud manager.
+func (s *Simulated) AddExistingVM(vm IaasVm) IaasVm {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vm.ID = strconv.Itoa(s.nextID)
+	vm.Cloud = s.Name
+	vm.CloudType = s.Type
+	if len(vm.IPs) == 0 {
+		vm.IPs = []string{fmt.Sprintf("192.0.2.%d", s.nextID%256)}
+	}
+	if len(vm.Status) == 0 {
+		vm.Status = ProxQSRunning
+	}
+	s.vms[vm.ID] = vm
+	s.nextID++
+	return vm
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatedCreateDeleteVM(t *testing.T) {
+	cloud := NewSimulated("SIM1", ResSet{VCpu: 8, Ram: 16384, Vm: 2, Volume: -1, Storage: 200, Port: -1})
+
+	vm1, err := cloud.CreateVM("vm1", 4, 8192, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, "SIM1", vm1.Cloud)
+	assert.Equal(t, SimulatedIaas, vm1.CloudType)
+	assert.True(t, vm1.McmCreate)
+	assert.Equal(t, 1, len(vm1.IPs))
+
+	// the resources are not enough for this VM
+	_, err = cloud.CreateVM("vm2", 5, 4096, 50)
+	assert.NotNil(t, err)
+
+	vm2, err := cloud.CreateVM("vm2", 4, 8192, 100)
+	assert.Nil(t, err)
+
+	// the number of VMs reaches the limit
+	_, err = cloud.CreateVM("vm3", 0, 0, 0)
+	assert.NotNil(t, err)
+
+	resources, err := cloud.CheckResources()
+	assert.Nil(t, err)
+	assert.Equal(t, ResSet{VCpu: 8, Ram: 16384, Vm: 2, Volume: 2, Storage: 200, Port: 2}, resources.InUse)
+
+	gotVm, err := cloud.GetVM(vm2.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, *vm2, *gotVm)
+
+	vms, err := cloud.ListAllVMs()
+	assert.Nil(t, err)
+	assert.Equal(t, []IaasVm{*vm1, *vm2}, vms)
+
+	assert.Nil(t, cloud.DeleteVM(vm1.ID))
+	assert.NotNil(t, cloud.DeleteVM(vm1.ID))
+	_, err = cloud.GetVM(vm1.ID)
+	assert.NotNil(t, err)
+
+	// VMs not created by multi-cloud manager cannot be deleted
+	existing := cloud.AddExistingVM(IaasVm{Name: "existing", VCpu: 1, Ram: 1024, Storage: 10})
+	created, err := cloud.IsCreatedByMcm(existing.ID)
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.NotNil(t, cloud.DeleteVM(existing.ID))
+}
+
+func TestSimulatedInjection(t *testing.T) {
+	testCases := []struct {
+		name        string
+		failureRate float64
+		latency     time.Duration
+		expectErr   bool
+	}{
+		{
+			name:        "never fail",
+			failureRate: 0,
+			latency:     10 * time.Millisecond,
+			expectErr:   false,
+		},
+		{
+			name:        "always fail",
+			failureRate: 1,
+			latency:     0,
+			expectErr:   true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		cloud := NewSimulated("SIM1", ResSet{VCpu: 8, Ram: 16384, Vm: -1, Volume: -1, Storage: 200, Port: -1})
+		cloud.FailureRate = testCase.failureRate
+		cloud.Latency = testCase.latency
+
+		start := time.Now()
+		_, err := cloud.CreateVM("vm1", 1, 1024, 10)
+		assert.Equal(t, testCase.expectErr, err != nil, fmt.Sprintf("%s: error is not expected", testCase.name))
+		assert.GreaterOrEqual(t, time.Since(start), testCase.latency, fmt.Sprintf("%s: latency is not injected", testCase.name))
+	}
+}
+
+func TestInitSimulated(t *testing.T) {
+	paras := map[string]interface{}{
+		"type":         SimulatedIaas,
+		"name":         "SIM1",
+		"vcpu":         float64(32),
+		"ram":          "65536",
+		"latency_ms":   float64(20),
+		"failure_rate": 0.1,
+		"seed":         float64(1),
+	}
+	cloud := InitSimulated(paras)
+	assert.Equal(t, "SIM1", cloud.ShowName())
+	assert.Equal(t, SimulatedIaas, cloud.ShowType())
+	assert.Equal(t, ResSet{VCpu: 32, Ram: 65536, Vm: -1, Volume: -1, Storage: 4096, Port: -1}, cloud.Limit)
+	assert.Equal(t, 20*time.Millisecond, cloud.Latency)
+	assert.Equal(t, 0.1, cloud.FailureRate)
+}
+
+func TestSimulatedCreateDeleteBatchVms(t *testing.T) {
+	oriClouds := Clouds
+	defer func() {
+		Clouds = oriClouds
+	}()
+	Clouds = map[string]Iaas{
+		"SIM1": NewSimulated("SIM1", ResSet{VCpu: 16, Ram: 32768, Vm: -1, Volume: -1, Storage: 500, Port: -1}),
+		"SIM2": NewSimulated("SIM2", ResSet{VCpu: 4, Ram: 8192, Vm: -1, Volume: -1, Storage: 100, Port: -1}),
+	}
+
+	vmsToCreate := []IaasVm{
+		{Cloud: "SIM1", Name: "s1-node1", VCpu: 4, Ram: 8192, Storage: 100},
+		{Cloud: "SIM1", Name: "s1-node2", VCpu: 4, Ram: 8192, Storage: 100},
+		{Cloud: "SIM2", Name: "s2-node1", VCpu: 4, Ram: 8192, Storage: 100},
+		{Cloud: "SIM2", Name: "s2-node2", VCpu: 4, Ram: 8192, Storage: 100}, // SIM2 does not have enough resources for this one.
+	}
+	createdVms, err := CreateVms(vmsToCreate)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, len(createdVms))
+
+	errs := DeleteBatchVms(createdVms)
+	assert.Nil(t, errs)
+	for name, cloud := range Clouds {
+		vms, err := cloud.ListAllVMs()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(vms), fmt.Sprintf("cloud %s should not have VMs", name))
+	}
+}
@@ -0,0 +1,381 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego"
+)
+
+// phases of tasks
+const (
+	TaskPending     string = "pending"
+	TaskRunning     string = "running"
+	TaskSucceeded   string = "succeeded"
+	TaskFailed      string = "failed"
+	TaskInterrupted string = "interrupted" // the controller restarted when the task was pending or running
+	TaskCancelled   string = "cancelled"   // the task was cancelled before its work really started
+)
+
+// ErrTaskCancelled should be wrapped by the error that a TaskWork returns when it is cancelled, so that the task is set as cancelled rather than failed.
+var ErrTaskCancelled = errors.New("cancelled")
+
+// types of tasks
+const (
+	TaskTypeCreateVms       string = "createVms"
+	TaskTypeAddNodes        string = "addNodes"
+	TaskTypeDeleteNodes     string = "deleteNodes"
+	TaskTypeCreateAppGroup  string = "createAppGroup"
+	TaskTypeMigrateAppGroup string = "migrateAppGroup"
+)
+
+const (
+	DefaultTaskStoreDir string = "tasks/"
+	MaxTasksKept        int    = 500 // we only keep the latest tasks, and the oldest finished ones are deleted.
+	taskFileSuffix      string = ".json"
+)
+
+// Task is a long-running operation (e.g., creating VMs), which runs in the background after the HTTP request returns.
+type Task struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Phase     string          `json:"phase"`
+	Progress  int             `json:"progress"` // percentage, from 0 to 100
+	Logs      []TaskLog       `json:"logs"`     // the sub-steps of this task
+	Result    json.RawMessage `json:"result,omitempty"`
+	Errors    []string        `json:"errors,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+type TaskLog struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+func (t Task) Finished() bool {
+	return t.Phase == TaskSucceeded || t.Phase == TaskFailed || t.Phase == TaskInterrupted || t.Phase == TaskCancelled
+}
+
+func taskCopy(src Task) Task {
+	dst := src
+	dst.Logs = append([]TaskLog(nil), src.Logs...)
+	dst.Result = append(json.RawMessage(nil), src.Result...)
+	dst.Errors = append([]string(nil), src.Errors...)
+	return dst
+}
+
+// TaskReporter is used by a running task to report its sub-steps and progress.
+type TaskReporter interface {
+	Logf(format string, a ...interface{})
+	SetProgress(progress int)
+	// SetRunning sets a pending task as running. It is only needed by the work of a task started by TaskManager.StartQueued.
+	SetRunning()
+}
+
+// NopReporter is used when the operation is not run as a task, so nothing needs to be reported.
+type NopReporter struct{}
+
+func (NopReporter) Logf(format string, a ...interface{}) {}
+func (NopReporter) SetProgress(progress int)             {}
+func (NopReporter) SetRunning()                          {}
+
+// TaskWork is the real work of a task. Its result will be put into the task as Json.
+type TaskWork func(reporter TaskReporter) (interface{}, error)
+
+// TaskManager runs tasks and saves their states. Every task is saved as a Json file in the storeDir, so that the tasks are not lost after the controller restarts.
+type TaskManager struct {
+	storeDir string // if it is empty, tasks are only in memory.
+	tasks    map[string]*Task
+	mu       sync.Mutex // the map in golang is not safe for concurrent read/write
+}
+
+// the global task manager
+var Tasks *TaskManager = &TaskManager{tasks: make(map[string]*Task)}
+
+func InitTasks() {
+	storeDir := beego.AppConfig.String("TaskStoreDir")
+	if len(storeDir) == 0 {
+		storeDir = DefaultTaskStoreDir
+	}
+	tm, err := NewTaskManager(storeDir)
+	if err != nil {
+		beego.Error(fmt.Sprintf("Initialize the task manager with the directory [%s], error: [%s]. Tasks will only be kept in memory.", storeDir, err.Error()))
+		return
+	}
+	Tasks = tm
+}
+
+// NewTaskManager creates a task manager and loads the tasks saved in storeDir.
+// The tasks that were pending or running when the controller stopped cannot be continued, so we set them as interrupted.
+func NewTaskManager(storeDir string) (*TaskManager, error) {
+	tm := &TaskManager{
+		storeDir: storeDir,
+		tasks:    make(map[string]*Task),
+	}
+	if err := os.MkdirAll(storeDir, 0755); err != nil {
+		outErr := fmt.Errorf("Create the directory [%s] for tasks, error: [%w]", storeDir, err)
+		beego.Error(outErr)
+		return nil, outErr
+	}
+
+	files, err := filepath.Glob(filepath.Join(storeDir, "*"+taskFileSuffix))
+	if err != nil {
+		outErr := fmt.Errorf("List the task files in [%s], error: [%w]", storeDir, err)
+		beego.Error(outErr)
+		return nil, outErr
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			beego.Error(fmt.Sprintf("Read task file [%s], error: [%s], skip it.", file, err.Error()))
+			continue
+		}
+		var task Task
+		if err := json.Unmarshal(content, &task); err != nil {
+			beego.Error(fmt.Sprintf("json.Unmarshal task file [%s], error: [%s], skip it.", file, err.Error()))
+			continue
+		}
+		if !task.Finished() {
+			beego.Info(fmt.Sprintf("Task [%s] was [%s] when the controller stopped, so it is interrupted.", task.ID, task.Phase))
+			task.Phase = TaskInterrupted
+			task.UpdatedAt = time.Now()
+			task.Errors = append(task.Errors, "the controller restarted before this task finished, so its result is unknown")
+			if err := tm.save(task); err != nil {
+				beego.Error(fmt.Sprintf("Save interrupted task [%s], error: [%s]", task.ID, err.Error()))
+			}
+		}
+		tm.tasks[task.ID] = &task
+	}
+	beego.Info(fmt.Sprintf("Loaded %d tasks from [%s].", len(tm.tasks), storeDir))
+	return tm, nil
+}
+
+// Start creates a task and runs the work in the background. It returns the task instantly.
+func (tm *TaskManager) Start(taskType string, work TaskWork) Task {
+	return tm.start(taskType, work, false)
+}
+
+// StartQueued is the same as Start, but the task stays pending until its work calls reporter.SetRunning, so that a task waiting in a queue (e.g., the scheduling queue) is not shown as running.
+func (tm *TaskManager) StartQueued(taskType string, work TaskWork) Task {
+	return tm.start(taskType, work, true)
+}
+
+func (tm *TaskManager) start(taskType string, work TaskWork, queued bool) Task {
+	tm.mu.Lock()
+	now := time.Now()
+	id := fmt.Sprintf("%s-%d", taskType, now.UnixNano())
+	for _, exist := tm.tasks[id]; exist; _, exist = tm.tasks[id] {
+		now = now.Add(time.Nanosecond)
+		id = fmt.Sprintf("%s-%d", taskType, now.UnixNano())
+	}
+	task := &Task{
+		ID:        id,
+		Type:      taskType,
+		Phase:     TaskPending,
+		Logs:      []TaskLog{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	tm.tasks[id] = task
+	tm.pruneLocked()
+	if err := tm.save(*task); err != nil {
+		beego.Error(fmt.Sprintf("Save task [%s], error: [%s]", id, err.Error()))
+	}
+	outTask := taskCopy(*task)
+	tm.mu.Unlock()
+
+	beego.Info(fmt.Sprintf("Task [%s] is created.", id))
+
+	go tm.run(id, work, queued)
+	return outTask
+}
+
+func (tm *TaskManager) run(id string, work TaskWork, queued bool) {
+	reporter := &taskReporter{tm: tm, id: id}
+	if !queued {
+		reporter.SetRunning()
+	}
+
+	result, err := runWork(id, work, reporter)
+
+	var resultJson json.RawMessage
+	if result != nil {
+		var mErr error
+		if resultJson, mErr = json.Marshal(result); mErr != nil {
+			beego.Error(fmt.Sprintf("Task [%s], json.Marshal result, error: [%s]", id, mErr.Error()))
+		}
+	}
+	tm.update(id, func(t *Task) {
+		t.Result = resultJson
+		switch {
+		case errors.Is(err, ErrTaskCancelled):
+			t.Phase = TaskCancelled
+			t.Errors = append(t.Errors, err.Error())
+		case err != nil:
+			t.Phase = TaskFailed
+			t.Errors = append(t.Errors, err.Error())
+		default:
+			t.Phase = TaskSucceeded
+			t.Progress = 100
+		}
+	})
+	beego.Info(fmt.Sprintf("Task [%s] is finished.", id))
+}
+
+// run the work of a task. If the work panics, the panic is returned as an error, so that the task is set as failed and the controller keeps running.
+func runWork(id string, work TaskWork, reporter TaskReporter) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			beego.Error(fmt.Sprintf("Task [%s] panicked: %v\n%s", id, r, debug.Stack()))
+			result, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return work(reporter)
+}
+
+// update a task and save it. We save it while holding the lock, so that an older state never overwrites a newer one in the file.
+func (tm *TaskManager) update(id string, modify func(t *Task)) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	task, exist := tm.tasks[id]
+	if !exist {
+		beego.Error(fmt.Sprintf("Update task [%s], task not found", id))
+		return
+	}
+	modify(task)
+	task.UpdatedAt = time.Now()
+
+	if err := tm.save(*task); err != nil {
+		beego.Error(fmt.Sprintf("Save task [%s], error: [%s]", id, err.Error()))
+	}
+}
+
+// Get a task by ID.
+func (tm *TaskManager) Get(id string) (Task, bool) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	task, exist := tm.tasks[id]
+	if !exist {
+		return Task{}, false
+	}
+	return taskCopy(*task), true
+}
+
+// List the recent tasks, the newest first. If limit is not positive, all tasks are listed.
+func (tm *TaskManager) List(limit int) []Task {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tasks := make([]Task, 0, len(tm.tasks))
+	for _, task := range tm.tasks {
+		tasks = append(tasks, taskCopy(*task))
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+	})
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks
+}
+
+// delete the oldest finished tasks if there are more than MaxTasksKept tasks. The caller should hold tm.mu.
+func (tm *TaskManager) pruneLocked() {
+	if len(tm.tasks) <= MaxTasksKept {
+		return
+	}
+	var finished []*Task
+	for _, task := range tm.tasks {
+		if task.Finished() {
+			finished = append(finished, task)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreatedAt.Before(finished[j].CreatedAt)
+	})
+	for i := 0; i < len(finished) && len(tm.tasks) > MaxTasksKept; i++ {
+		delete(tm.tasks, finished[i].ID)
+		if len(tm.storeDir) != 0 {
+			if err := os.Remove(tm.taskFile(finished[i].ID)); err != nil && !os.IsNotExist(err) {
+				beego.Error(fmt.Sprintf("Remove the file of task [%s], error: [%s]", finished[i].ID, err.Error()))
+			}
+		}
+	}
+}
+
+func (tm *TaskManager) taskFile(id string) string {
+	// task IDs are generated by us, but we still avoid path traversal.
+	return filepath.Join(tm.storeDir, strings.ReplaceAll(id, string(os.PathSeparator), "_")+taskFileSuffix)
+}
+
+// save a task into its file. The caller should hold tm.mu, except when the task manager is being created. We write a temporary file and then rename it, so that the file is never half written.
+func (tm *TaskManager) save(task Task) error {
+	if len(tm.storeDir) == 0 {
+		return nil
+	}
+	content, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("json.Marshal task [%s], error: [%w]", task.ID, err)
+	}
+	file := tm.taskFile(task.ID)
+	tmpFile, err := os.CreateTemp(tm.storeDir, task.ID+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temporary file for task [%s], error: [%w]", task.ID, err)
+	}
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("write temporary file for task [%s], error: [%w]", task.ID, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("close temporary file for task [%s], error: [%w]", task.ID, err)
+	}
+	if err := os.Rename(tmpFile.Name(), file); err != nil {
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("rename temporary file to [%s], error: [%w]", file, err)
+	}
+	return nil
+}
+
+// taskReporter implements TaskReporter for a task in a TaskManager.
+type taskReporter struct {
+	tm *TaskManager
+	id string
+}
+
+func (r *taskReporter) Logf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	beego.Info(fmt.Sprintf("Task [%s]: %s", r.id, msg))
+	r.tm.update(r.id, func(t *Task) {
+		t.Logs = append(t.Logs, TaskLog{Time: time.Now(), Message: msg})
+	})
+}
+
+func (r *taskReporter) SetRunning() {
+	r.tm.update(r.id, func(t *Task) {
+		if t.Phase == TaskPending {
+			t.Phase = TaskRunning
+		}
+	})
+}
+
+func (r *taskReporter) SetProgress(progress int) {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 100 {
+		progress = 100
+	}
+	r.tm.update(r.id, func(t *Task) {
+		t.Progress = progress
+	})
+}
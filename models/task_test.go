@@ -0,0 +1,182 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wait for a task to finish, or fail the test after the timeout.
+func waitTaskFinished(t *testing.T, tm *TaskManager, id string) Task {
+	var task Task
+	err := MyWaitFor(5, 1, func() (bool, error) {
+		var exist bool
+		task, exist = tm.Get(id)
+		if !exist {
+			return false, fmt.Errorf("task [%s] not found", id)
+		}
+		return task.Finished(), nil
+	})
+	assert.Nil(t, err)
+	return task
+}
+
+func TestTaskManager(t *testing.T) {
+	testCases := []struct {
+		name           string
+		work           TaskWork
+		expectedPhase  string
+		expectedResult string
+		expectedErrs   []string
+	}{
+		{
+			name: "case succeeded",
+			work: func(reporter TaskReporter) (interface{}, error) {
+				reporter.Logf("step %d", 1)
+				reporter.SetProgress(50)
+				return []string{"vm1", "vm2"}, nil
+			},
+			expectedPhase:  TaskSucceeded,
+			expectedResult: `["vm1","vm2"]`,
+			expectedErrs:   nil,
+		},
+		{
+			name: "case failed",
+			work: func(reporter TaskReporter) (interface{}, error) {
+				reporter.Logf("step %d", 1)
+				return nil, fmt.Errorf("something wrong")
+			},
+			expectedPhase:  TaskFailed,
+			expectedResult: "",
+			expectedErrs:   []string{"something wrong"},
+		},
+		{
+			name: "case panicked",
+			work: func(reporter TaskReporter) (interface{}, error) {
+				reporter.Logf("step %d", 1)
+				var m map[string]int
+				m["a"] = 1
+				return nil, nil
+			},
+			expectedPhase:  TaskFailed,
+			expectedResult: "",
+			expectedErrs:   []string{"panic: assignment to entry in nil map"},
+		},
+		{
+			name: "case cancelled",
+			work: func(reporter TaskReporter) (interface{}, error) {
+				reporter.Logf("step %d", 1)
+				return nil, fmt.Errorf("request [abc] is %w", ErrTaskCancelled)
+			},
+			expectedPhase:  TaskCancelled,
+			expectedResult: "",
+			expectedErrs:   []string{"request [abc] is cancelled"},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Logf("test: %d, %s", i, testCase.name)
+		storeDir := t.TempDir()
+		tm, err := NewTaskManager(storeDir)
+		assert.Nil(t, err)
+
+		created := tm.Start(TaskTypeCreateVms, testCase.work)
+		assert.Equal(t, TaskPending, created.Phase)
+
+		task := waitTaskFinished(t, tm, created.ID)
+		assert.Equal(t, testCase.expectedPhase, task.Phase, testCase.name)
+		assert.Equal(t, testCase.expectedResult, string(task.Result), testCase.name)
+		assert.Equal(t, testCase.expectedErrs, task.Errors, testCase.name)
+		assert.Equal(t, 1, len(task.Logs), testCase.name)
+		assert.Equal(t, "step 1", task.Logs[0].Message, testCase.name)
+
+		// the task should be loaded after restarting
+		reloaded, err := NewTaskManager(storeDir)
+		assert.Nil(t, err)
+		reloadedTask, exist := reloaded.Get(created.ID)
+		assert.True(t, exist, testCase.name)
+		assert.Equal(t, task.Phase, reloadedTask.Phase, testCase.name)
+		assert.Equal(t, string(task.Result), string(reloadedTask.Result), testCase.name)
+	}
+}
+
+// a queued task should stay pending until its work calls SetRunning.
+func TestTaskManagerQueued(t *testing.T) {
+	tm, err := NewTaskManager(t.TempDir())
+	assert.Nil(t, err)
+
+	leaveQueue := make(chan struct{})
+	running := make(chan struct{})
+	finish := make(chan struct{})
+	created := tm.StartQueued(TaskTypeCreateAppGroup, func(reporter TaskReporter) (interface{}, error) {
+		<-leaveQueue
+		reporter.SetRunning()
+		close(running)
+		<-finish
+		return nil, nil
+	})
+
+	time.Sleep(100 * time.Millisecond)
+	task, _ := tm.Get(created.ID)
+	assert.Equal(t, TaskPending, task.Phase)
+
+	close(leaveQueue)
+	<-running
+	task, _ = tm.Get(created.ID)
+	assert.Equal(t, TaskRunning, task.Phase)
+
+	close(finish)
+	task = waitTaskFinished(t, tm, created.ID)
+	assert.Equal(t, TaskSucceeded, task.Phase)
+}
+
+func TestTaskManagerInterrupted(t *testing.T) {
+	storeDir := t.TempDir()
+	running := Task{
+		ID:        "createVms-1",
+		Type:      TaskTypeCreateVms,
+		Phase:     TaskRunning,
+		Progress:  30,
+		Logs:      []TaskLog{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	content, err := json.Marshal(running)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(storeDir, running.ID+taskFileSuffix), content, 0644))
+
+	tm, err := NewTaskManager(storeDir)
+	assert.Nil(t, err)
+	task, exist := tm.Get(running.ID)
+	assert.True(t, exist)
+	assert.Equal(t, TaskInterrupted, task.Phase)
+	assert.Equal(t, 1, len(task.Errors))
+}
+
+func TestTaskManagerList(t *testing.T) {
+	tm, err := NewTaskManager(t.TempDir())
+	assert.Nil(t, err)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		task := tm.Start(TaskTypeAddNodes, func(reporter TaskReporter) (interface{}, error) {
+			return nil, nil
+		})
+		ids = append(ids, task.ID)
+	}
+	for _, id := range ids {
+		waitTaskFinished(t, tm, id)
+	}
+
+	tasks := tm.List(2)
+	assert.Equal(t, 2, len(tasks))
+	// the newest first
+	assert.Equal(t, ids[2], tasks[0].ID)
+	assert.Equal(t, ids[1], tasks[1].ID)
+	assert.Equal(t, 3, len(tm.List(0)))
+}
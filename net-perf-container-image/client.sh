@@ -26,15 +26,38 @@ last_line_ping=$(ping -c 10 -w 30 "${t_cloud_ip}" | tail -1)
 # 10 packets transmitted, 0 packets received, 100% packet loss
 # does not contain  '/' and '='
 rtt_ms=""
+bandwidth_mbps=""
 if [[ "${last_line_ping}" == *=* && "${last_line_ping}" == */* ]]
 then
   rtt_ms=$(echo "${last_line_ping}" | awk '{print $4}' | cut -d '/' -f 2)
   echo "RTT from ${this_cloud_name} to ${t_cloud_name} is ${rtt_ms} ms."
+
+  # measure the bandwidth (throughput) from this cloud to the target cloud with the iperf3 server in the target cloud.
+  # with "-f m", the summary line of the receiver is like:
+  # [  5]   0.00-10.04  sec  1.09 GBytes   936 Mbits/sec                  receiver
+  # the iperf3 server only serves one client at a time. Multi-cloud Manager runs the clients to the same server one by one, but if it is still busy, e.g., with a client of the last round, we wait and try again.
+  for i in $(seq 1 10)
+  do
+    bandwidth_mbps=$(iperf3 -c "${t_cloud_ip}" -t 10 -f m 2>/dev/null | grep "receiver" | tail -1 | awk '{print $(NF-2)}') || true
+    if [[ -n "${bandwidth_mbps}" ]]
+    then
+      break
+    fi
+    sleep $((RANDOM % 10 + 1))
+  done
+  if [[ -n "${bandwidth_mbps}" ]]
+  then
+    echo "Bandwidth from ${this_cloud_name} to ${t_cloud_name} is ${bandwidth_mbps} Mbps."
+  else
+    bandwidth_mbps="-1" # -1 means that the bandwidth is unknown
+    echo "Cannot measure the bandwidth from ${this_cloud_name} to ${t_cloud_name}, so we set it as unknown (${bandwidth_mbps})."
+  fi
 else
   rtt_ms="250000" # very big value means unreachable
-  echo "Unreachable from ${this_cloud_name} to ${t_cloud_name}, so we set the RTT as ${rtt_ms} ms."
+  bandwidth_mbps="0" # 0 means unreachable
+  echo "Unreachable from ${this_cloud_name} to ${t_cloud_name}, so we set the RTT as ${rtt_ms} ms and the bandwidth as ${bandwidth_mbps} Mbps."
 fi
 
-# write the rtt in to the database
-mysql -u "${mysql_user}" --port "${mysql_port}" -h "${mysql_ip}" --password="${mysql_passwd}" -e "update ${mysql_db_name}.${this_cloud_name} set rtt_ms=${rtt_ms} where target_cloud_name='${t_cloud_name}'"
-echo "Write the rtt value in the database successfully."
\ No newline at end of file
+# write the rtt and bandwidth in to the database
+mysql -u "${mysql_user}" --port "${mysql_port}" -h "${mysql_ip}" --password="${mysql_passwd}" -e "update ${mysql_db_name}.${this_cloud_name} set rtt_ms=${rtt_ms}, bandwidth_mbps=${bandwidth_mbps} where target_cloud_name='${t_cloud_name}'"
+echo "Write the rtt and bandwidth values in the database successfully."
\ No newline at end of file
@@ -1,8 +1,9 @@
 #!/bin/env bash
 
-# The network performance test servers do not need to do anything. Just to keep the container running.
+# The network performance test servers run the iperf3 server for the clients to measure bandwidth, and keep the container running.
+# If the iperf3 server exits for some reason, we start it again.
 
 while true
 do
-  sleep 10
-done
\ No newline at end of file
+  iperf3 -s || sleep 10
+done
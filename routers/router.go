@@ -33,6 +33,12 @@ func init() {
 
 	// AppGroup is for the auto-schedule function.
 	beego.Router("/doNewAppGroup", &controllers.AppGroupController{}, "post:DoNewAppGroup")
+	beego.Router("/appGroup/plan", &controllers.AppGroupController{}, "post:PlanAppGroup")
+	beego.Router("/appGroup/migrate", &controllers.AppGroupController{}, "post:MigrateAppGroup")
+	beego.Router("/appGroup/snapshot", &controllers.AppGroupController{}, "post:SnapshotAppGroup")
+	beego.Router("/appGroup/queue", &controllers.AppGroupController{}, "get:ListQueue")
+	beego.Router("/appGroup/queue/:id", &controllers.AppGroupController{}, "delete:CancelQueued")
+	beego.Router("/algorithms", &controllers.AlgorithmController{}, "get:Get")
 
 	beego.Router("/k8sNode", &controllers.K8sNodeController{}, "get:Get")
 	beego.Router("/k8sNode", &controllers.K8sNodeController{}, "delete:DeleteNodes")
@@ -41,4 +47,7 @@ func init() {
 	beego.Router("/k8sNode/doAdd", &controllers.K8sNodeController{}, "post:DoAddNodes")
 
 	beego.Router("/netState", &controllers.NetStateController{}, "get:Get")
+
+	beego.Router("/task", &controllers.TaskController{}, "get:Get")
+	beego.Router("/task/:id", &controllers.TaskController{}, "get:GetTask")
 }
@@ -22,6 +22,7 @@
             <th rowspan="2">Type</th>
             <th rowspan="2">Web URL</th>
             <th colspan="6">Resources (used/total)</th>
+            <th rowspan="2">Estimated Spend per Hour</th>
         </tr>
         <tr>
 <!--            <th></th> -->
@@ -69,6 +70,11 @@
                 {{else}}
                     <td>{{$cloud.Resources.InUse.Port}}/{{$cloud.Resources.Limit.Port}}</td>
                 {{end}}
+                {{if $cloud.Pricing}}
+                    <td>{{printf "%.2f" $cloud.SpendHourly}}</td>
+                {{else}}
+                    <td></td>
+                {{end}}
             </tr>
         {{end}}
     </table>
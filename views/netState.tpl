@@ -28,7 +28,7 @@
 
         <table border = 1>
             <tr>
-                <th rowspan="2" colspan="2">RTT (ms)</th>
+                <th rowspan="2" colspan="2">RTT (ms) / Bandwidth (Mbps)</th>
                 <th colspan="{{$netStateLen}}">Target Cloud</th>
             </tr>
             <tr>
@@ -42,7 +42,7 @@
                 <th>{{$firstSKey}}</th>
                 {{ range $tIdx, $tKey := $nsKeys }}
                     {{with $thisNs := index $ns $firstSKey $tKey}}
-                        <td>{{$thisNs.Rtt}}</td>
+                        <td>{{$thisNs.Rtt}} / {{$thisNs.Bandwidth}}</td>
                     {{end}}
                 {{end}}
             </tr>
@@ -54,7 +54,7 @@
                 <th>{{$sKey}}</th>
                 {{ range $tIdx, $tKey := $nsKeys }}
                     {{with $thisNs := index $ns $sKey $tKey}}
-                    <td>{{$thisNs.Rtt}}</td>
+                    <td>{{$thisNs.Rtt}} / {{$thisNs.Bandwidth}}</td>
                     {{end}}
                 {{end}}
                 </tr>
@@ -67,6 +67,28 @@
         </tr>
     </table>
 
+    {{if .cloudInfo.Pricing}}
+    <br>
+    <h3>Pricing (per hour)</h3>
+
+    <table border = 1>
+        <tr>
+            <th>CPU Logical Core</th>
+            <th>Memory (GiB)</th>
+            <th>Storage (GiB)</th>
+            <th>Virtual Machine</th>
+            <th>Estimated Spend</th>
+        </tr>
+        <tr>
+            <td>{{.cloudInfo.Pricing.VCpuHour}}</td>
+            <td>{{.cloudInfo.Pricing.RamGibHour}}</td>
+            <td>{{.cloudInfo.Pricing.StorageGibHour}}</td>
+            <td>{{.cloudInfo.Pricing.VmHour}}</td>
+            <td>{{printf "%.2f" .cloudInfo.SpendHourly}}</td>
+        </tr>
+    </table>
+    {{end}}
+
     <br>
     <h3>Create a new Virtual Machine</h3>
     <form id="uploadForm" method="POST" action="/cloud/{{.cloudInfo.Name}}/vm" enctype="multipart/form-data" onsubmit="whileCreatingVM()">